@@ -8,4 +8,10 @@ var (
 
 	// ErrCAKeyAlreadyExists is returned when CA private key already exists.
 	ErrCAKeyAlreadyExists = errors.New("CA private key already exists")
+
+	// ErrHARFileRequired is returned when har import is called without files.
+	ErrHARFileRequired = errors.New("at least one HAR file is required")
+
+	// ErrInvalidImportHost is returned when a host can not be used in a mapping.
+	ErrInvalidImportHost = errors.New("invalid host")
 )
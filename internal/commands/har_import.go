@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/internal/handler/record"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultImportDir  = "fixtures"
+	defaultImportFrom = "http://localhost:3000"
+	importFileMode    = 0o644
+	importYAMLIndent  = 2
+)
+
+// HARImportCommand handles the 'har import' command. It converts HAR entries
+// into response files and a configuration with mocks serving them.
+type HARImportCommand struct {
+	outputDir  string
+	configFile string
+	from       string
+	hosts      []string
+	paths      []string
+	methods    []string
+	statuses   []int
+
+	fs     afero.Fs
+	output contracts.Output
+	stdout io.Writer
+}
+
+type importedConfig struct {
+	Mappings []importedMapping `yaml:"mappings"`
+}
+
+type importedMapping struct {
+	From  string        `yaml:"from"`
+	To    string        `yaml:"to"`
+	Mocks []record.Mock `yaml:"mocks"`
+}
+
+// NewHARImportCommand creates a new har import command.
+func NewHARImportCommand(options ...HARImportOption) *HARImportCommand {
+	return helpers.ApplyOptions(&HARImportCommand{}, options)
+}
+
+// DefineFlags defines command-line flags for the har import command.
+func (c *HARImportCommand) DefineFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&c.outputDir, "output", "o", defaultImportDir, "Directory for imported response files")
+	flags.StringVar(&c.configFile, "config", "", "File to write the generated config to (default: stdout)")
+	flags.StringVar(&c.from, "from", defaultImportFrom, "Local host of the first mapping; next hosts use the following ports")
+	flags.StringSliceVar(&c.hosts, "host", nil, "Import only entries whose host matches the glob (repeatable)")
+	flags.StringSliceVar(&c.paths, "path", nil, "Import only entries whose path matches the glob (repeatable)")
+	flags.StringSliceVar(&c.methods, "method", nil, "Import only entries with the HTTP method (repeatable)")
+	flags.IntSliceVar(&c.statuses, "status", nil, "Import only entries with the status code (repeatable)")
+}
+
+// Execute imports the given HAR files.
+func (c *HARImportCommand) Execute(files []string) error {
+	if len(files) == 0 {
+		return ErrHARFileRequired
+	}
+
+	from, err := url.Parse(c.from)
+	if err != nil || from.Hostname() == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidImportHost, c.from)
+	}
+
+	var (
+		origins     []string
+		collections = map[string]*record.Collection{}
+		imported    int
+	)
+
+	for _, file := range files {
+		archive, err := har.ReadFile(c.fs, file)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range archive.Log.Entries {
+			request, err := importRequest(&entry.Request)
+			if err != nil || !c.matches(request, entry.Response.Status) {
+				continue
+			}
+
+			origin := request.URL.Scheme + "://" + request.URL.Host
+
+			collection, ok := collections[origin]
+			if !ok {
+				dir := filepath.Join(c.outputDir, strings.ReplaceAll(request.URL.Host, ":", "-"))
+				collection = record.NewCollection(c.fs, dir)
+				collections[origin] = collection
+				origins = append(origins, origin)
+			}
+
+			body := []byte(nil)
+			if entry.Request.PostData != nil {
+				body = []byte(entry.Request.PostData.Text)
+			}
+
+			capture, err := importResponse(&entry.Response)
+			if err != nil {
+				return fmt.Errorf("failed to decode response of %s %s: %w", request.Method, request.URL, err)
+			}
+
+			err = collection.Add(request, body, capture)
+			if err != nil {
+				return err
+			}
+
+			imported++
+		}
+	}
+
+	mappings, err := c.mappings(from, origins, collections)
+	if err != nil {
+		return err
+	}
+
+	err = c.writeConfig(importedConfig{Mappings: mappings})
+	if err != nil {
+		return err
+	}
+
+	if c.configFile != "" {
+		c.output.Infof("Imported %d entries for %d hosts into %s", imported, len(origins), c.configFile)
+	}
+
+	return nil
+}
+
+func (c *HARImportCommand) matches(request *http.Request, status int) bool {
+	// Status 0 marks requests the browser never got a response for.
+	if status == 0 {
+		return false
+	}
+
+	if len(c.statuses) > 0 && !slices.Contains(c.statuses, status) {
+		return false
+	}
+
+	if len(c.methods) > 0 && !slices.ContainsFunc(c.methods, func(method string) bool {
+		return strings.EqualFold(method, request.Method)
+	}) {
+		return false
+	}
+
+	return matchesAny(c.hosts, request.URL.Hostname(), doublestar.Match) &&
+		matchesAny(c.paths, request.URL.Path, doublestar.PathMatch)
+}
+
+func (c *HARImportCommand) mappings(
+	from *url.URL,
+	origins []string,
+	collections map[string]*record.Collection,
+) ([]importedMapping, error) {
+	port := 0
+
+	if from.Port() != "" {
+		var err error
+
+		port, err = strconv.Atoi(from.Port())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidImportHost, c.from)
+		}
+	}
+
+	mappings := make([]importedMapping, 0, len(origins))
+
+	for i, origin := range origins {
+		local := *from
+		if port != 0 {
+			local.Host = from.Hostname() + ":" + strconv.Itoa(port+i)
+		}
+
+		mappings = append(mappings, importedMapping{
+			From:  local.String(),
+			To:    origin,
+			Mocks: collections[origin].Mocks(),
+		})
+	}
+
+	return mappings, nil
+}
+
+func (c *HARImportCommand) writeConfig(cfg importedConfig) error {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(importYAMLIndent)
+
+	err := encoder.Encode(cfg)
+	if err != nil {
+		return err
+	}
+
+	if c.configFile == "" {
+		_, err = c.stdout.Write(buffer.Bytes())
+
+		return err
+	}
+
+	return afero.WriteFile(c.fs, c.configFile, buffer.Bytes(), importFileMode)
+}
+
+func importRequest(entry *har.Request) (*http.Request, error) {
+	parsed, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImportHost, entry.URL)
+	}
+
+	return &http.Request{
+		Method: strings.ToUpper(entry.Method),
+		URL:    parsed,
+		Host:   parsed.Host,
+		Header: http.Header{},
+	}, nil
+}
+
+func importResponse(entry *har.Response) (contracts.ResponseCapture, error) {
+	header := http.Header{}
+
+	for _, item := range entry.Headers {
+		// HTTP/2 pseudo-headers like :status are not real headers.
+		if strings.HasPrefix(item.Name, ":") {
+			continue
+		}
+
+		header.Add(item.Name, item.Value)
+	}
+
+	if header.Get(headers.ContentType) == "" && entry.Content.MimeType != "" {
+		header.Set(headers.ContentType, entry.Content.MimeType)
+	}
+
+	body, decoded, err := har.DecodeContent(entry.Content, header.Get(headers.ContentEncoding))
+	if err != nil {
+		return contracts.ResponseCapture{}, err
+	}
+
+	if decoded {
+		header.Del(headers.ContentEncoding)
+	}
+
+	return contracts.ResponseCapture{
+		StatusCode: entry.Status,
+		Header:     header,
+		Body:       body,
+	}, nil
+}
+
+func matchesAny(patterns []string, value string, match func(pattern, name string) (bool, error)) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := match(pattern, value)
+
+		return ok
+	})
+}
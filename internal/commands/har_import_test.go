@@ -0,0 +1,229 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/commands"
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/evg4b/uncors/testing/testutils/params"
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const harFile = "/session.har"
+
+func harEntry(method, url string, status int, mimeType, text string) har.Entry {
+	return har.Entry{
+		Request: har.Request{Method: method, URL: url},
+		Response: har.Response{
+			Status: status,
+			Headers: []har.NameValue{
+				{Name: ":status", Value: "200"},
+				{Name: "content-type", Value: mimeType},
+				{Name: "content-encoding", Value: "gzip"},
+			},
+			Content: har.Content{MimeType: mimeType, Text: text},
+		},
+	}
+}
+
+func harFs(t *testing.T, entries ...har.Entry) afero.Fs {
+	t.Helper()
+
+	data, err := json.Marshal(har.HAR{Log: har.Log{Version: "1.2", Entries: entries}})
+	require.NoError(t, err)
+
+	return testutils.FsFromMap(t, map[string]string{harFile: string(data)})
+}
+
+func runImport(t *testing.T, fs afero.Fs, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout bytes.Buffer
+
+	cmd := commands.NewHARImportCommand(
+		commands.ForHARImportWithFs(fs),
+		commands.ForHARImportWithOutput(mocks.NoopOutput()),
+		commands.ForHARImportWithStdout(&stdout),
+	)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	cmd.DefineFlags(flags)
+	require.NoError(t, flags.Parse(args))
+
+	err := cmd.Execute(flags.Args())
+
+	return stdout.String(), err
+}
+
+func TestHARImportCommand_DefineFlags(t *testing.T) {
+	cmd := commands.NewHARImportCommand()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	cmd.DefineFlags(flags)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "output", expected: "fixtures"},
+		{name: "config", expected: ""},
+		{name: "from", expected: "http://localhost:3000"},
+		{name: "host", expected: "[]"},
+		{name: "path", expected: "[]"},
+		{name: "method", expected: "[]"},
+		{name: "status", expected: "[]"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			flag := flags.Lookup(testCase.name)
+			require.NotNil(t, flag)
+			assert.Equal(t, testCase.expected, flag.DefValue)
+		})
+	}
+}
+
+func TestHARImportCommand_Execute(t *testing.T) {
+	t.Run("prints config with mocks", func(t *testing.T) {
+		fs := harFs(t,
+			harEntry("GET", "https://api.example.com/users?page=2", 200, "application/json", `[{"id":1}]`),
+		)
+
+		actual, err := runImport(t, fs, harFile)
+
+		require.NoError(t, err)
+		assert.Equal(t, `mappings:
+  - from: http://localhost:3000
+    to: https://api.example.com
+    mocks:
+      - path: /users
+        method: GET
+        queries:
+          page: "2"
+        response:
+          code: 200
+          headers:
+            Content-Type: application/json
+          file: fixtures/api.example.com/responses/get-users-3460fd9c.json
+`, actual)
+
+		body, err := afero.ReadFile(fs, "fixtures/api.example.com/responses/get-users-3460fd9c.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id":1}]`, string(body))
+	})
+
+	t.Run("decodes base64 bodies", func(t *testing.T) {
+		entry := harEntry("GET", "https://cdn.example.com/logo.png", 200, "image/png", "")
+		entry.Response.Headers = entry.Response.Headers[:2]
+		entry.Response.Content.Text = base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'})
+		entry.Response.Content.Encoding = "base64"
+
+		fs := harFs(t, entry)
+
+		_, err := runImport(t, fs, harFile)
+		require.NoError(t, err)
+
+		files, err := afero.ReadDir(fs, "fixtures/cdn.example.com/responses")
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		body, err := afero.ReadFile(fs, "fixtures/cdn.example.com/responses/"+files[0].Name())
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, body)
+	})
+
+	t.Run("creates mapping per host", func(t *testing.T) {
+		fs := harFs(t,
+			harEntry("GET", "https://api.example.com/users", 200, "application/json", `[]`),
+			harEntry("GET", "http://auth.example.com:8080/token", 200, "text/plain", `token`),
+		)
+
+		actual, err := runImport(t, fs, "--from", "https://localhost:4000", harFile)
+
+		require.NoError(t, err)
+		assert.Contains(t, actual, "  - from: https://localhost:4000\n    to: https://api.example.com\n")
+		assert.Contains(t, actual, "  - from: https://localhost:4001\n    to: http://auth.example.com:8080\n")
+		assert.Contains(t, actual, "file: fixtures/auth.example.com-8080/responses/")
+	})
+
+	t.Run("filters entries", func(t *testing.T) {
+		fs := harFs(t,
+			harEntry("GET", "https://api.example.com/api/users", 200, "application/json", `[]`),
+			harEntry("POST", "https://api.example.com/api/users", 201, "application/json", `{}`),
+			harEntry("GET", "https://api.example.com/api/missing", 404, "text/plain", `missing`),
+			harEntry("GET", "https://api.example.com/assets/app.js", 200, "text/javascript", `app`),
+			harEntry("GET", "https://ads.tracker.net/api/pixel", 200, "image/gif", `gif`),
+			harEntry("GET", "https://api.example.com/api/failed", 0, "", ""),
+		)
+
+		actual, err := runImport(t, fs,
+			"--host", "*.example.com",
+			"--path", "/api/**",
+			"--method", "get",
+			"--status", "200,404",
+			harFile,
+		)
+
+		require.NoError(t, err)
+		assert.Contains(t, actual, "path: /api/users")
+		assert.Contains(t, actual, "path: /api/missing")
+		assert.NotContains(t, actual, "method: POST")
+		assert.NotContains(t, actual, "app.js")
+		assert.NotContains(t, actual, "tracker")
+		assert.NotContains(t, actual, "/api/failed")
+	})
+
+	t.Run("writes loadable config file", func(t *testing.T) {
+		fs := harFs(t,
+			harEntry("GET", "https://api.example.com/users", 200, "application/json", `[]`),
+			harEntry("POST", "https://api.example.com/users", 201, "application/json", `{"id":2}`),
+		)
+
+		actual, err := runImport(t, fs, "--config", "/imported.yaml", harFile)
+
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+
+		loaded, _, err := config.LoadConfiguration(fs, []string{params.Config, "/imported.yaml"})
+		require.NoError(t, err)
+		require.Len(t, loaded.Mappings, 1)
+		assert.Len(t, loaded.Mappings[0].Mocks, 2)
+	})
+
+	t.Run("fails without files", func(t *testing.T) {
+		_, err := runImport(t, afero.NewMemMapFs())
+
+		require.ErrorIs(t, err, commands.ErrHARFileRequired)
+	})
+
+	t.Run("fails on missing file", func(t *testing.T) {
+		_, err := runImport(t, afero.NewMemMapFs(), harFile)
+
+		require.ErrorContains(t, err, "failed to read HAR file")
+	})
+
+	t.Run("fails on broken file", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{harFile: "{"})
+
+		_, err := runImport(t, fs, harFile)
+
+		require.ErrorContains(t, err, "failed to parse HAR file")
+	})
+
+	t.Run("fails on invalid from", func(t *testing.T) {
+		fs := harFs(t)
+
+		_, err := runImport(t, fs, "--from", "localhost", harFile)
+
+		require.ErrorIs(t, err, commands.ErrInvalidImportHost)
+	})
+}
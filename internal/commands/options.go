@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"io"
+
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/spf13/afero"
 )
@@ -18,3 +20,25 @@ func WithFs(fs afero.Fs) Option {
 		c.fs = fs
 	}
 }
+
+type HARImportOption = func(*HARImportCommand)
+
+func ForHARImportWithOutput(output contracts.Output) HARImportOption {
+	return func(c *HARImportCommand) {
+		c.output = output
+	}
+}
+
+func ForHARImportWithFs(fs afero.Fs) HARImportOption {
+	return func(c *HARImportCommand) {
+		c.fs = fs
+	}
+}
+
+// ForHARImportWithStdout sets where the generated config is printed when no
+// config file is given.
+func ForHARImportWithStdout(stdout io.Writer) HARImportOption {
+	return func(c *HARImportCommand) {
+		c.stdout = stdout
+	}
+}
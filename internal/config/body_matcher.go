@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// BodyMatcher is a condition on the request body. With Field set the body is
+// parsed as JSON and the value at the dotted path (array items are addressed
+// by index, e.g. items.0.id) is checked; otherwise the whole body is.
+type BodyMatcher struct {
+	Field        string `yaml:"field"`
+	ValueMatcher `yaml:",inline"`
+}
+
+func (m BodyMatcher) Clone() BodyMatcher {
+	return m
+}
+
+// UnmarshalYAML decodes the field explicitly, since the promoted
+// ValueMatcher.UnmarshalYAML would otherwise consume the whole node.
+func (m *BodyMatcher) UnmarshalYAML(value *yaml.Node) error {
+	var fields struct {
+		Field string `yaml:"field"`
+	}
+
+	err := value.Decode(&fields)
+	if err != nil {
+		return err
+	}
+
+	m.Field = fields.Field
+
+	return value.Decode(&m.ValueMatcher)
+}
+
+func (m BodyMatcher) Validate(field string) error {
+	if m.Field != "" && lo.Contains(strings.Split(m.Field, "."), "") {
+		return &ValidationError{fmt.Sprintf("%s must be a field name or dotted path", joinPath(field, "field"))}
+	}
+
+	return m.ValueMatcher.Validate(field)
+}
+
+type BodyMatchers []BodyMatcher
+
+func (m BodyMatchers) Clone() BodyMatchers {
+	if m == nil {
+		return nil
+	}
+
+	return append(BodyMatchers{}, m...)
+}
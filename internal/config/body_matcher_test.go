@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBodyMatchersUnmarshalYAML(t *testing.T) {
+	const input = `
+- field: user.name
+  equals: locked
+- field: password
+  absent: true
+- contains: '"remember":true'
+`
+
+	var actual config.BodyMatchers
+
+	require.NoError(t, yaml.Unmarshal([]byte(input), &actual))
+	assert.Equal(t, config.BodyMatchers{
+		{Field: "user.name", ValueMatcher: config.ValueMatcher{Equals: "locked"}},
+		{Field: "password", ValueMatcher: config.ValueMatcher{Absent: true}},
+		{ValueMatcher: config.ValueMatcher{Contains: `"remember":true`}},
+	}, actual)
+}
+
+func TestBodyMatchersClone(t *testing.T) {
+	matchers := config.BodyMatchers{
+		{Field: "username", ValueMatcher: config.ValueMatcher{Equals: "locked"}},
+	}
+
+	cloned := matchers.Clone()
+
+	assert.Equal(t, matchers, cloned)
+	assert.NotSame(t, &matchers[0], &cloned[0])
+	assert.Nil(t, config.BodyMatchers(nil).Clone())
+}
+
+func TestBodyMatcherValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher config.BodyMatcher
+		error   string
+	}{
+		{
+			name:    "empty path segment",
+			matcher: config.BodyMatcher{Field: "user..name", ValueMatcher: config.ValueMatcher{Equals: "x"}},
+			error:   "body[0].field must be a field name or dotted path",
+		},
+		{
+			name:    "invalid regex",
+			matcher: config.BodyMatcher{Field: "username", ValueMatcher: config.ValueMatcher{Regex: "("}},
+			error:   "body[0].regex is not a valid regular expression",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.EqualError(t, testCase.matcher.Validate("body[0]"), testCase.error)
+		})
+	}
+
+	t.Run("valid matcher", func(t *testing.T) {
+		matcher := config.BodyMatcher{Field: "items.0.id", ValueMatcher: config.ValueMatcher{Equals: "1"}}
+
+		assert.NoError(t, matcher.Validate("body[0]"))
+	})
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBodyRewriteContentTypes are rewritten when no content types are
+// configured.
+var DefaultBodyRewriteContentTypes = []string{
+	"text/html",
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"text/xml",
+}
+
+// BodyRewriteConfig replaces target URLs in proxied response bodies with the
+// source URLs of the mapping.
+type BodyRewriteConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	ContentTypes []string `yaml:"content-types"`
+}
+
+func (b *BodyRewriteConfig) Clone() BodyRewriteConfig {
+	return BodyRewriteConfig{
+		Enabled:      b.Enabled,
+		ContentTypes: slices.Clone(b.ContentTypes),
+	}
+}
+
+// UnmarshalYAML accepts a boolean as a shorthand that enables rewriting of
+// the default content types. The full form is enabled unless enabled is set
+// to false.
+func (b *BodyRewriteConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&b.Enabled)
+	}
+
+	type bodyRewriteConfigAlias BodyRewriteConfig
+
+	b.Enabled = true
+
+	return value.Decode((*bodyRewriteConfigAlias)(b))
+}
+
+// Types returns the content types to rewrite.
+func (b *BodyRewriteConfig) Types() []string {
+	if len(b.ContentTypes) == 0 {
+		return DefaultBodyRewriteContentTypes
+	}
+
+	return b.ContentTypes
+}
+
+func (b *BodyRewriteConfig) Validate(field string) error {
+	errs := make([]error, 0, len(b.ContentTypes))
+
+	for i, contentType := range b.ContentTypes {
+		errs = append(errs, ValidateGlobPattern(joinPath(field, "content-types", index(i)), contentType))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBodyRewriteConfigUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.BodyRewriteConfig
+	}{
+		{
+			name:     "enabled shorthand",
+			input:    `true`,
+			expected: config.BodyRewriteConfig{Enabled: true},
+		},
+		{
+			name:     "disabled shorthand",
+			input:    `false`,
+			expected: config.BodyRewriteConfig{},
+		},
+		{
+			name:  "full form is enabled by default",
+			input: `content-types: [text/html]`,
+			expected: config.BodyRewriteConfig{
+				Enabled:      true,
+				ContentTypes: []string{"text/html"},
+			},
+		},
+		{
+			name:  "full form disabled",
+			input: "enabled: false\ncontent-types: [text/html]",
+			expected: config.BodyRewriteConfig{
+				ContentTypes: []string{"text/html"},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual config.BodyRewriteConfig
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestBodyRewriteConfigTypes(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := config.BodyRewriteConfig{Enabled: true}
+
+		assert.Equal(t, config.DefaultBodyRewriteContentTypes, cfg.Types())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		cfg := config.BodyRewriteConfig{Enabled: true, ContentTypes: []string{"text/plain"}}
+
+		assert.Equal(t, []string{"text/plain"}, cfg.Types())
+	})
+}
+
+func TestBodyRewriteConfigClone(t *testing.T) {
+	cfg := config.BodyRewriteConfig{Enabled: true, ContentTypes: []string{"text/html"}}
+
+	clone := cfg.Clone()
+
+	assert.Equal(t, cfg, clone)
+	assert.NotSame(t, &cfg.ContentTypes[0], &clone.ContentTypes[0])
+}
+
+func TestBodyRewriteConfigValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg := config.BodyRewriteConfig{Enabled: true, ContentTypes: []string{"application/*+json"}}
+
+		assert.NoError(t, cfg.Validate("mappings[0].body-rewrite"))
+	})
+
+	t.Run("invalid content type pattern", func(t *testing.T) {
+		cfg := config.BodyRewriteConfig{Enabled: true, ContentTypes: []string{"text/["}}
+
+		assert.EqualError(t, cfg.Validate("mappings[0].body-rewrite"),
+			"mappings[0].body-rewrite.content-types[0] is not a valid glob pattern")
+	})
+}
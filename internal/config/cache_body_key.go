@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CacheBodyKey controls whether the request body becomes part of the cache
+// key. JSON bodies are canonicalized before hashing; when Fields is set only
+// the listed top-level or dotted fields participate.
+type CacheBodyKey struct {
+	Enabled bool     `yaml:"enabled"`
+	Fields  []string `yaml:"fields"`
+}
+
+func (k *CacheBodyKey) Clone() CacheBodyKey {
+	return CacheBodyKey{
+		Enabled: k.Enabled,
+		Fields:  slices.Clone(k.Fields),
+	}
+}
+
+// UnmarshalYAML accepts a boolean shorthand (body-key: true) as well as the
+// full mapping form. The mapping form is enabled unless stated otherwise.
+func (k *CacheBodyKey) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&k.Enabled)
+	}
+
+	type cacheBodyKeyAlias CacheBodyKey
+
+	alias := cacheBodyKeyAlias{Enabled: true}
+
+	err := value.Decode(&alias)
+	if err != nil {
+		return err
+	}
+
+	*k = CacheBodyKey(alias)
+
+	return nil
+}
+
+func (k *CacheBodyKey) Validate(field string) error {
+	var errs []error
+
+	for i, name := range k.Fields {
+		if slices.Contains(strings.Split(name, "."), "") {
+			msg := fmt.Sprintf("%s must be a field name or dotted path", joinPath(field, "fields", index(i)))
+			errs = append(errs, &ValidationError{msg})
+		}
+	}
+
+	return errors.Join(errs...)
+}
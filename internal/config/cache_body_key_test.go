@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCacheBodyKeyClone(t *testing.T) {
+	cfg := config.CacheBodyKey{
+		Enabled: true,
+		Fields:  []string{"operationName", "variables"},
+	}
+
+	cloned := cfg.Clone()
+
+	assert.Equal(t, cfg, cloned)
+	assert.NotSame(t, &cfg.Fields[0], &cloned.Fields[0])
+}
+
+func TestCacheBodyKeyUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.CacheBodyKey
+	}{
+		{
+			name:     "boolean shorthand true",
+			input:    `true`,
+			expected: config.CacheBodyKey{Enabled: true},
+		},
+		{
+			name:     "boolean shorthand false",
+			input:    `false`,
+			expected: config.CacheBodyKey{},
+		},
+		{
+			name:  "mapping form is enabled by default",
+			input: `fields: [operationName, variables]`,
+			expected: config.CacheBodyKey{
+				Enabled: true,
+				Fields:  []string{"operationName", "variables"},
+			},
+		},
+		{
+			name:  "mapping form can be disabled",
+			input: "enabled: false\nfields: [operationName]",
+			expected: config.CacheBodyKey{
+				Fields: []string{"operationName"},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var cfg config.CacheBodyKey
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &cfg))
+			assert.Equal(t, testCase.expected, cfg)
+		})
+	}
+
+	t.Run("invalid shorthand", func(t *testing.T) {
+		var cfg config.CacheBodyKey
+
+		require.Error(t, yaml.Unmarshal([]byte(`sometimes`), &cfg))
+	})
+}
+
+func TestCacheBodyKeyValidate(t *testing.T) {
+	t.Run("valid fields", func(t *testing.T) {
+		cfg := config.CacheBodyKey{Enabled: true, Fields: []string{"operationName", "variables.id"}}
+
+		assert.NoError(t, cfg.Validate("body-key"))
+	})
+
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{name: "empty field", field: ""},
+		{name: "leading dot", field: ".id"},
+		{name: "trailing dot", field: "variables."},
+		{name: "double dot", field: "variables..id"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg := config.CacheBodyKey{Enabled: true, Fields: []string{testCase.field}}
+
+			require.EqualError(t, cfg.Validate("body-key"), "body-key.fields[0] must be a field name or dotted path")
+		})
+	}
+}
@@ -3,20 +3,27 @@ package config
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
 	"time"
-)
 
-type CacheGlobs []string
+	"github.com/spf13/afero"
+)
 
-func (g CacheGlobs) Clone() CacheGlobs {
-	return slices.Clone(g)
-}
+const (
+	CacheStorageMemory = "memory"
+	CacheStorageDisk   = "disk"
+)
 
 type CacheConfig struct {
 	ExpirationTime time.Duration `yaml:"expiration-time"`
 	MaxSize        int64         `yaml:"max-size"`
 	Methods        []string      `yaml:"methods"`
+	Storage        string        `yaml:"storage"`
+	Dir            string        `yaml:"dir"`
+	HTTPSemantics  bool          `yaml:"http-semantics"`
+	BodyKey        CacheBodyKey  `yaml:"body-key"`
 }
 
 func (c *CacheConfig) Clone() *CacheConfig {
@@ -24,10 +31,34 @@ func (c *CacheConfig) Clone() *CacheConfig {
 		ExpirationTime: c.ExpirationTime,
 		MaxSize:        c.MaxSize,
 		Methods:        slices.Clone(c.Methods),
+		Storage:        c.Storage,
+		Dir:            c.Dir,
+		HTTPSemantics:  c.HTTPSemantics,
+		BodyKey:        c.BodyKey.Clone(),
+	}
+}
+
+// IsDiskStorage reports whether cached responses are persisted on disk.
+func (c *CacheConfig) IsDiskStorage() bool {
+	return c.Storage == CacheStorageDisk
+}
+
+// DiskDir returns the directory for the disk cache. When not configured the
+// "uncors" directory inside the user cache directory is used.
+func (c *CacheConfig) DiskDir() (string, error) {
+	if c.Dir != "" {
+		return c.Dir, nil
 	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+
+	return filepath.Join(cacheDir, "uncors"), nil
 }
 
-func (c *CacheConfig) Validate(field string) error {
+func (c *CacheConfig) Validate(field string, fs afero.Fs) error {
 	var errs []error
 
 	errs = append(errs, ValidateDuration(joinPath(field, "expiration-time"), c.ExpirationTime, false))
@@ -41,9 +72,37 @@ func (c *CacheConfig) Validate(field string) error {
 		errs = append(errs, &ValidationError{"methods must not be empty"})
 	}
 
+	if c.Storage != "" {
+		errs = append(errs, ValidateStringEnum(
+			joinPath(field, "storage"), c.Storage, []string{CacheStorageMemory, CacheStorageDisk},
+		))
+	}
+
 	for i, method := range c.Methods {
 		errs = append(errs, ValidateMethod(joinPath(field, "methods", index(i)), method, false))
 	}
 
+	errs = append(errs, c.BodyKey.Validate(joinPath(field, "body-key")))
+
+	if c.IsDiskStorage() {
+		errs = append(errs, c.validateDiskDir(joinPath(field, "dir"), fs))
+	}
+
 	return errors.Join(errs...)
 }
+
+// validateDiskDir checks that the disk cache directory can be resolved. The
+// directory is created when the cache starts, it only must not be a file.
+func (c *CacheConfig) validateDiskDir(field string, fs afero.Fs) error {
+	dir, err := c.DiskDir()
+	if err != nil {
+		return &ValidationError{fmt.Sprintf("%s: %v", field, err)}
+	}
+
+	stat, err := fs.Stat(dir)
+	if err == nil && !stat.IsDir() {
+		return &ValidationError{fmt.Sprintf("%s %s is not a directory", field, dir)}
+	}
+
+	return nil
+}
@@ -2,38 +2,26 @@ package config_test
 
 import (
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestCacheGlobsClone(t *testing.T) {
-	globs := config.CacheGlobs{
-		"/api/**",
-		"/constants",
-		"/translations",
-		"/**/*.js",
-	}
-
-	cacheGlobs := globs.Clone()
-
-	t.Run("not same", func(t *testing.T) {
-		assert.NotSame(t, &globs, &cacheGlobs)
-	})
-
-	t.Run("equals values", func(t *testing.T) {
-		assert.Equal(t, globs, cacheGlobs)
-	})
-}
-
 func TestCacheConfigClone(t *testing.T) {
 	cacheConfig := &config.CacheConfig{
 		ExpirationTime: 5 * time.Minute,
 		MaxSize:        50 * 1024 * 1024,
 		Methods:        []string{http.MethodGet, http.MethodPost},
+		Storage:        config.CacheStorageDisk,
+		Dir:            "/tmp/uncors-cache",
+		HTTPSemantics:  true,
+		BodyKey:        config.CacheBodyKey{Enabled: true, Fields: []string{"operationName"}},
 	}
 
 	clonedCacheConfig := cacheConfig.Clone()
@@ -54,12 +42,27 @@ func TestCacheConfigClone(t *testing.T) {
 func TestCacheConfigValidator(t *testing.T) {
 	const field = "test"
 
+	fs := testutils.FsFromMap(t, map[string]string{
+		"/tmp/uncors-cache.json": "{}",
+	})
+
 	t.Run("should not register errors for", func(t *testing.T) {
 		err := (&config.CacheConfig{
 			ExpirationTime: 5 * time.Minute,
 			MaxSize:        100 * 1024 * 1024,
 			Methods:        []string{http.MethodGet, http.MethodPost},
-		}).Validate(field)
+		}).Validate(field, fs)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not register errors for disk storage", func(t *testing.T) {
+		err := (&config.CacheConfig{
+			ExpirationTime: 5 * time.Minute,
+			MaxSize:        100 * 1024 * 1024,
+			Methods:        []string{http.MethodGet},
+			Storage:        config.CacheStorageDisk,
+			Dir:            "/tmp/uncors-cache",
+		}).Validate(field, fs)
 		assert.NoError(t, err)
 	})
 
@@ -107,11 +110,55 @@ func TestCacheConfigValidator(t *testing.T) {
 				},
 				error: "test.methods[1] must be one of GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE",
 			},
+			{
+				name: "unknown storage",
+				value: config.CacheConfig{
+					ExpirationTime: 5 * time.Minute,
+					MaxSize:        100 * 1024 * 1024,
+					Methods:        []string{http.MethodGet},
+					Storage:        "redis",
+				},
+				error: "'redis' is not a valid option",
+			},
+			{
+				name: "disk cache directory is a file",
+				value: config.CacheConfig{
+					ExpirationTime: 5 * time.Minute,
+					MaxSize:        100 * 1024 * 1024,
+					Methods:        []string{http.MethodGet},
+					Storage:        config.CacheStorageDisk,
+					Dir:            "/tmp/uncors-cache.json",
+				},
+				error: "test.dir /tmp/uncors-cache.json is not a directory",
+			},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
-				require.EqualError(t, test.value.Validate(field), test.error)
+				require.EqualError(t, test.value.Validate(field, fs), test.error)
 			})
 		}
 	})
 }
+
+func TestCacheConfigDiskDir(t *testing.T) {
+	t.Run("uses configured directory", func(t *testing.T) {
+		dir, err := (&config.CacheConfig{Dir: "/tmp/uncors-cache"}).DiskDir()
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp/uncors-cache", dir)
+	})
+
+	t.Run("falls back to user cache directory", func(t *testing.T) {
+		userCacheDir, err := os.UserCacheDir()
+		require.NoError(t, err)
+
+		dir, err := (&config.CacheConfig{}).DiskDir()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(userCacheDir, "uncors"), dir)
+	})
+}
+
+func TestCacheConfigIsDiskStorage(t *testing.T) {
+	assert.True(t, (&config.CacheConfig{Storage: config.CacheStorageDisk}).IsDiskStorage())
+	assert.False(t, (&config.CacheConfig{Storage: config.CacheStorageMemory}).IsDiskStorage())
+	assert.False(t, (&config.CacheConfig{}).IsDiskStorage())
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// CacheRule selects requests to cache by path glob. Zero values inherit the
+// global cache configuration: expiration-time and methods from cache-config,
+// and any 2xx status code.
+type CacheRule struct {
+	Path           string        `yaml:"path"`
+	ExpirationTime time.Duration `yaml:"expiration-time"`
+	Methods        []string      `yaml:"methods"`
+	StatusCodes    []int         `yaml:"status-codes"`
+	IgnoreQuery    []string      `yaml:"ignore-query"`
+}
+
+func (r *CacheRule) Clone() CacheRule {
+	return CacheRule{
+		Path:           r.Path,
+		ExpirationTime: r.ExpirationTime,
+		Methods:        slices.Clone(r.Methods),
+		StatusCodes:    slices.Clone(r.StatusCodes),
+		IgnoreQuery:    slices.Clone(r.IgnoreQuery),
+	}
+}
+
+func (r *CacheRule) String() string {
+	var options []string
+
+	if r.ExpirationTime > 0 {
+		options = append(options, "ttl "+r.ExpirationTime.String())
+	}
+
+	if len(r.Methods) > 0 {
+		options = append(options, "methods "+strings.Join(r.Methods, ","))
+	}
+
+	if len(r.StatusCodes) > 0 {
+		codes := lo.Map(r.StatusCodes, func(code int, _ int) string {
+			return strconv.Itoa(code)
+		})
+		options = append(options, "status "+strings.Join(codes, ","))
+	}
+
+	if len(r.IgnoreQuery) > 0 {
+		options = append(options, "ignore-query "+strings.Join(r.IgnoreQuery, ","))
+	}
+
+	if len(options) == 0 {
+		return r.Path
+	}
+
+	return fmt.Sprintf("%s (%s)", r.Path, strings.Join(options, "; "))
+}
+
+// UnmarshalYAML accepts a plain glob string as a shorthand for a rule
+// without overrides.
+func (r *CacheRule) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Path = value.Value
+
+		return nil
+	}
+
+	type cacheRuleAlias CacheRule
+
+	return value.Decode((*cacheRuleAlias)(r))
+}
+
+// IsCacheableStatus reports whether a response with the given status code
+// may be stored under this rule.
+func (r *CacheRule) IsCacheableStatus(code int) bool {
+	if len(r.StatusCodes) == 0 {
+		return code >= 200 && code < 300
+	}
+
+	return slices.Contains(r.StatusCodes, code)
+}
+
+func (r *CacheRule) Validate(field string) error {
+	errs := make([]error, 0, 2+len(r.Methods)+len(r.StatusCodes))
+
+	errs = append(errs, ValidateGlobPattern(field, r.Path))
+	errs = append(errs, ValidateDuration(joinPath(field, "expiration-time"), r.ExpirationTime, true))
+
+	for i, method := range r.Methods {
+		errs = append(errs, ValidateMethod(joinPath(field, "methods", index(i)), method, false))
+	}
+
+	for i, code := range r.StatusCodes {
+		errs = append(errs, ValidateStatus(joinPath(field, "status-codes", index(i)), code))
+	}
+
+	return errors.Join(errs...)
+}
+
+type CacheRules []CacheRule
+
+func (r CacheRules) Clone() CacheRules {
+	if r == nil {
+		return nil
+	}
+
+	return lo.Map(r, func(item CacheRule, _ int) CacheRule {
+		return item.Clone()
+	})
+}
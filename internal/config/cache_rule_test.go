@@ -0,0 +1,164 @@
+package config_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCacheRulesClone(t *testing.T) {
+	rules := config.CacheRules{
+		{Path: "/api/**"},
+		{Path: "/constants"},
+		{
+			Path:           "/translations",
+			ExpirationTime: time.Hour,
+			Methods:        []string{http.MethodGet},
+			StatusCodes:    []int{http.StatusOK, http.StatusNotFound},
+			IgnoreQuery:    []string{"_ts"},
+		},
+	}
+
+	cloned := rules.Clone()
+
+	t.Run("not same", func(t *testing.T) {
+		assert.NotSame(t, &rules[0], &cloned[0])
+		assert.NotSame(t, &rules[2].StatusCodes[0], &cloned[2].StatusCodes[0])
+	})
+
+	t.Run("equals values", func(t *testing.T) {
+		assert.Equal(t, rules, cloned)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, config.CacheRules(nil).Clone())
+	})
+}
+
+func TestCacheRulesUnmarshalYAML(t *testing.T) {
+	const input = `
+- /api/static/**
+- path: /api/users/**
+  expiration-time: 30s
+  methods: [GET, POST]
+  status-codes: [200, 404]
+  ignore-query: [_ts]
+`
+
+	var rules config.CacheRules
+
+	require.NoError(t, yaml.Unmarshal([]byte(input), &rules))
+	assert.Equal(t, config.CacheRules{
+		{Path: "/api/static/**"},
+		{
+			Path:           "/api/users/**",
+			ExpirationTime: 30 * time.Second,
+			Methods:        []string{http.MethodGet, http.MethodPost},
+			StatusCodes:    []int{http.StatusOK, http.StatusNotFound},
+			IgnoreQuery:    []string{"_ts"},
+		},
+	}, rules)
+}
+
+func TestCacheRuleIsCacheableStatus(t *testing.T) {
+	t.Run("any 2xx by default", func(t *testing.T) {
+		rule := config.CacheRule{Path: "/**"}
+
+		assert.True(t, rule.IsCacheableStatus(http.StatusOK))
+		assert.True(t, rule.IsCacheableStatus(http.StatusNoContent))
+		assert.False(t, rule.IsCacheableStatus(http.StatusNotFound))
+		assert.False(t, rule.IsCacheableStatus(http.StatusMovedPermanently))
+	})
+
+	t.Run("only listed codes", func(t *testing.T) {
+		rule := config.CacheRule{Path: "/**", StatusCodes: []int{http.StatusOK, http.StatusNotFound}}
+
+		assert.True(t, rule.IsCacheableStatus(http.StatusOK))
+		assert.True(t, rule.IsCacheableStatus(http.StatusNotFound))
+		assert.False(t, rule.IsCacheableStatus(http.StatusNoContent))
+	})
+}
+
+func TestCacheRuleString(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     config.CacheRule
+		expected string
+	}{
+		{
+			name:     "path only",
+			rule:     config.CacheRule{Path: "/api/**"},
+			expected: "/api/**",
+		},
+		{
+			name: "with overrides",
+			rule: config.CacheRule{
+				Path:           "/api/**",
+				ExpirationTime: 30 * time.Second,
+				Methods:        []string{http.MethodGet, http.MethodPost},
+				StatusCodes:    []int{http.StatusOK, http.StatusNotFound},
+				IgnoreQuery:    []string{"_ts"},
+			},
+			expected: "/api/** (ttl 30s; methods GET,POST; status 200,404; ignore-query _ts)",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.rule.String())
+		})
+	}
+}
+
+func TestCacheRuleValidate(t *testing.T) {
+	const field = "cache[0]"
+
+	t.Run("valid rule", func(t *testing.T) {
+		rule := config.CacheRule{
+			Path:           "/api/**",
+			ExpirationTime: time.Minute,
+			Methods:        []string{http.MethodGet},
+			StatusCodes:    []int{http.StatusOK},
+		}
+
+		assert.NoError(t, rule.Validate(field))
+	})
+
+	tests := []struct {
+		name  string
+		rule  config.CacheRule
+		error string
+	}{
+		{
+			name:  "invalid glob",
+			rule:  config.CacheRule{Path: "/api/[a"},
+			error: "cache[0] is not a valid glob pattern",
+		},
+		{
+			name:  "negative expiration time",
+			rule:  config.CacheRule{Path: "/api/**", ExpirationTime: -time.Second},
+			error: "cache[0].expiration-time must be greater than or equal to 0",
+		},
+		{
+			name:  "invalid method",
+			rule:  config.CacheRule{Path: "/api/**", Methods: []string{"invalid"}},
+			error: "cache[0].methods[0] must be one of GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE",
+		},
+		{
+			name:  "invalid status code",
+			rule:  config.CacheRule{Path: "/api/**", StatusCodes: []int{700}},
+			error: "cache[0].status-codes[0] code must be in range 100-599",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.EqualError(t, testCase.rule.Validate(field), testCase.error)
+		})
+	}
+}
@@ -12,8 +12,11 @@ import (
 type UncorsConfig struct {
 	Mappings    Mappings    `yaml:"mappings"`
 	Proxy       string      `yaml:"proxy"`
+	Listen      string      `yaml:"listen"`
 	Debug       bool        `yaml:"debug"`
+	Offline     bool        `yaml:"offline"`
 	CacheConfig CacheConfig `yaml:"cache-config"`
+	LuaPath     LuaPath     `yaml:"lua-path"`
 	Interactive bool        `yaml:"-"`
 }
 
@@ -42,6 +45,11 @@ func LoadConfiguration(fs afero.Fs, args []string) (*UncorsConfig, string, error
 
 	cfg.Mappings = NormaliseMappings(cfg.Mappings)
 
+	err = replayRecordedMocks(fs, cfg.Mappings)
+	if err != nil {
+		return nil, "", err
+	}
+
 	err = cfg.Validate(fs)
 	if err != nil {
 		return nil, "", err
@@ -71,10 +79,18 @@ func applyFlagOverrides(cfg *UncorsConfig, flags *pflag.FlagSet) error {
 		cfg.Proxy, _ = flags.GetString("proxy")
 	}
 
+	if flags.Changed("listen") {
+		cfg.Listen, _ = flags.GetString("listen")
+	}
+
 	if flags.Changed("debug") {
 		cfg.Debug, _ = flags.GetBool("debug")
 	}
 
+	if flags.Changed("offline") {
+		cfg.Offline, _ = flags.GetBool("offline")
+	}
+
 	if flags.Changed("interactive") {
 		cfg.Interactive, _ = flags.GetBool("interactive")
 	}
@@ -97,7 +113,16 @@ func (cfg *UncorsConfig) Validate(fs afero.Fs) error {
 	}
 
 	errs = append(errs, ValidateProxy("proxy", cfg.Proxy))
-	errs = append(errs, cfg.CacheConfig.Validate("cache-config"))
+	errs = append(errs, ValidateListen("listen", cfg.Listen, true))
+	errs = append(errs, cfg.CacheConfig.Validate("cache-config", fs))
+	errs = append(errs, cfg.LuaPath.Validate("lua-path", fs))
+
+	// Grouping needs valid ports and listen values, so listeners are only
+	// checked when everything else is valid.
+	err := errors.Join(errs...)
+	if err != nil {
+		return err
+	}
 
-	return errors.Join(errs...)
+	return cfg.Mappings.GroupByPort(cfg.Listen).validateListeners(cfg.Listen)
 }
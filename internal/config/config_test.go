@@ -98,6 +98,7 @@ func TestLoadConfiguration(t *testing.T) {
 					Mappings: config.Mappings{
 						{From: hosts.Localhost.HTTPPort(8080), To: hosts.Github.HTTPS()},
 					},
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: config.DefaultExpirationTime,
 						MaxSize:        config.DefaultMaxSize,
@@ -120,11 +121,11 @@ func TestLoadConfiguration(t *testing.T) {
 									Matcher: config.RequestMatcher{
 										Path:   "/demo",
 										Method: "POST",
-										Queries: map[string]string{
-											"foo": "bar",
+										Queries: config.ValueMatchers{
+											"foo": {Equals: "bar"},
 										},
-										Headers: map[string]string{
-											acceptEncoding: "deflate",
+										Headers: config.ValueMatchers{
+											acceptEncoding: {Equals: "deflate"},
 										},
 									},
 									Response: config.Response{
@@ -138,8 +139,9 @@ func TestLoadConfiguration(t *testing.T) {
 							},
 						},
 					},
-					Proxy: hosts.Localhost.HTTPPort(8080).String(),
-					Debug: true,
+					Proxy:  hosts.Localhost.HTTPPort(8080).String(),
+					Debug:  true,
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: time.Hour,
 						MaxSize:        52428800,
@@ -162,6 +164,7 @@ func TestLoadConfiguration(t *testing.T) {
 						{From: hosts.Localhost1.HTTP(), To: hosts.Github.Host()},
 						{From: hosts.Localhost2.HTTPPort(9090), To: hosts.Stackoverflow.Host()},
 					},
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: config.DefaultExpirationTime,
 						MaxSize:        config.DefaultMaxSize,
@@ -180,6 +183,7 @@ func TestLoadConfiguration(t *testing.T) {
 					Mappings: config.Mappings{
 						{From: hosts.Localhost1.HTTP(), To: hosts.Github.Host()},
 					},
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: config.DefaultExpirationTime,
 						MaxSize:        config.DefaultMaxSize,
@@ -206,8 +210,8 @@ func TestLoadConfiguration(t *testing.T) {
 									Matcher: config.RequestMatcher{
 										Path:    "/demo",
 										Method:  "POST",
-										Queries: map[string]string{"foo": "bar"},
-										Headers: map[string]string{acceptEncoding: "deflate"},
+										Queries: config.ValueMatchers{"foo": {Equals: "bar"}},
+										Headers: config.ValueMatchers{acceptEncoding: {Equals: "deflate"}},
 									},
 									Response: config.Response{
 										Code:    201,
@@ -218,8 +222,9 @@ func TestLoadConfiguration(t *testing.T) {
 							},
 						},
 					},
-					Proxy: "http://newproxy:9999",
-					Debug: false,
+					Proxy:  "http://newproxy:9999",
+					Debug:  false,
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: time.Hour, MaxSize: 52428800,
 						Methods: []string{http.MethodGet, http.MethodPost},
@@ -227,6 +232,45 @@ func TestLoadConfiguration(t *testing.T) {
 					Interactive: true,
 				},
 			},
+			{
+				name: "CLI listen flag overrides default listen address",
+				args: []string{
+					params.From, hosts.Localhost1.HTTP().String(), params.To, hosts.Github.Host().String(),
+					"--listen", "::1",
+				},
+				expected: &config.UncorsConfig{
+					Mappings: config.Mappings{
+						{From: hosts.Localhost1.HTTP(), To: hosts.Github.Host()},
+					},
+					Listen: "::1",
+					CacheConfig: config.CacheConfig{
+						ExpirationTime: config.DefaultExpirationTime,
+						MaxSize:        config.DefaultMaxSize,
+						Methods:        []string{http.MethodGet},
+					},
+					Interactive: true,
+				},
+			},
+			{
+				name: "CLI offline flag enables offline mode",
+				args: []string{
+					params.From, hosts.Localhost1.HTTP().String(), params.To, hosts.Github.Host().String(),
+					"--offline",
+				},
+				expected: &config.UncorsConfig{
+					Mappings: config.Mappings{
+						{From: hosts.Localhost1.HTTP(), To: hosts.Github.Host()},
+					},
+					Listen:  config.DefaultListen,
+					Offline: true,
+					CacheConfig: config.CacheConfig{
+						ExpirationTime: config.DefaultExpirationTime,
+						MaxSize:        config.DefaultMaxSize,
+						Methods:        []string{http.MethodGet},
+					},
+					Interactive: true,
+				},
+			},
 			{
 				name: "CLI from/to updates existing mapping from config file",
 				args: []string{
@@ -237,6 +281,7 @@ func TestLoadConfiguration(t *testing.T) {
 					Mappings: config.Mappings{
 						{From: hosts.Localhost.HTTPPort(8080), To: hosts.Stackoverflow.HTTPS()},
 					},
+					Listen: config.DefaultListen,
 					CacheConfig: config.CacheConfig{
 						ExpirationTime: config.DefaultExpirationTime,
 						MaxSize:        config.DefaultMaxSize,
@@ -359,6 +404,35 @@ func TestUncorsConfigValidator(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "mapping listen address equal to the global one",
+				value: &config.UncorsConfig{
+					Mappings: []config.Mapping{
+						{From: hosts.Localhost.Port(8080), To: hosts.Github.HTTPS()},
+						{From: hosts.Localhost1.Port(8080), To: hosts.Github.HTTPS(), Listen: "127.0.0.1"},
+					},
+					Listen: "127.0.0.1",
+					CacheConfig: config.CacheConfig{
+						MaxSize:        100 * 1024 * 1024,
+						ExpirationTime: 10 * time.Minute,
+						Methods:        []string{http.MethodGet},
+					},
+				},
+			},
+			{
+				name: "IPv4 wildcard and IPv6 address on the same port",
+				value: &config.UncorsConfig{
+					Mappings: []config.Mapping{
+						{From: hosts.Localhost.Port(8080), To: hosts.Github.HTTPS(), Listen: "0.0.0.0"},
+						{From: hosts.Localhost1.Port(8080), To: hosts.Github.HTTPS(), Listen: "::1"},
+					},
+					CacheConfig: config.CacheConfig{
+						MaxSize:        100 * 1024 * 1024,
+						ExpirationTime: 10 * time.Minute,
+						Methods:        []string{http.MethodGet},
+					},
+				},
+			},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
@@ -387,6 +461,53 @@ func TestUncorsConfigValidator(t *testing.T) {
 				},
 				error: "mappings must not be empty",
 			},
+			{
+				name: "invalid listen address",
+				value: &config.UncorsConfig{
+					Mappings: []config.Mapping{
+						{From: hosts.Localhost.Port(8080), To: hosts.Localhost.HTTPSPort(8443)},
+					},
+					Listen: "localhost",
+					CacheConfig: config.CacheConfig{
+						MaxSize:        100 * 1024 * 1024,
+						ExpirationTime: 10 * time.Minute,
+						Methods:        []string{http.MethodGet},
+					},
+				},
+				error: `listen must be an IPv4 or IPv6 address or "*" for all interfaces`,
+			},
+			{
+				name: "wildcard and specific listen address on the same port",
+				value: &config.UncorsConfig{
+					Mappings: []config.Mapping{
+						{From: hosts.Localhost.Port(80), To: hosts.Github.HTTPS()},
+						{From: hosts.Localhost1.Port(80), To: hosts.Github.HTTPS(), Listen: "0.0.0.0"},
+					},
+					Listen: "127.0.0.1",
+					CacheConfig: config.CacheConfig{
+						MaxSize:        100 * 1024 * 1024,
+						ExpirationTime: 10 * time.Minute,
+						Methods:        []string{http.MethodGet},
+					},
+				},
+				error: "mappings listen on overlapping addresses 0.0.0.0:80 and 127.0.0.1:80",
+			},
+			{
+				name: "all interfaces and IPv6 address on the same port",
+				value: &config.UncorsConfig{
+					Mappings: []config.Mapping{
+						{From: hosts.Localhost.Port(3000), To: hosts.Github.HTTPS(), Listen: "*"},
+						{From: hosts.Localhost1.Port(3000), To: hosts.Github.HTTPS(), Listen: "::1"},
+					},
+					Listen: "127.0.0.1",
+					CacheConfig: config.CacheConfig{
+						MaxSize:        100 * 1024 * 1024,
+						ExpirationTime: 10 * time.Minute,
+						Methods:        []string{http.MethodGet},
+					},
+				},
+				error: "mappings listen on overlapping addresses :3000 and [::1]:3000",
+			},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
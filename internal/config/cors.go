@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const corsPassthrough = "passthrough"
+
+var ErrCORSShorthandValue = errors.New("cors shorthand value must be \"passthrough\"")
+
+// CORSConfig describes the CORS policy applied to responses of a mapping.
+// The zero value keeps the permissive default: the request origin is
+// reflected and any headers, methods and credentials are allowed.
+type CORSConfig struct {
+	Passthrough      bool          `yaml:"passthrough"`
+	AllowedOrigins   []string      `yaml:"allowed-origins"`
+	AllowedMethods   []string      `yaml:"allowed-methods"`
+	AllowedHeaders   []string      `yaml:"allowed-headers"`
+	ExposedHeaders   []string      `yaml:"exposed-headers"`
+	AllowCredentials *bool         `yaml:"allow-credentials"`
+	MaxAge           time.Duration `yaml:"max-age"`
+}
+
+// CredentialsAllowed reports whether Access-Control-Allow-Credentials
+// should be sent. Credentials are allowed unless explicitly disabled.
+func (c *CORSConfig) CredentialsAllowed() bool {
+	return c.AllowCredentials == nil || *c.AllowCredentials
+}
+
+func (c *CORSConfig) Clone() CORSConfig {
+	var allowCredentials *bool
+	if c.AllowCredentials != nil {
+		value := *c.AllowCredentials
+		allowCredentials = &value
+	}
+
+	return CORSConfig{
+		Passthrough:      c.Passthrough,
+		AllowedOrigins:   slices.Clone(c.AllowedOrigins),
+		AllowedMethods:   slices.Clone(c.AllowedMethods),
+		AllowedHeaders:   slices.Clone(c.AllowedHeaders),
+		ExposedHeaders:   slices.Clone(c.ExposedHeaders),
+		AllowCredentials: allowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+func (c *CORSConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value != corsPassthrough {
+			return ErrCORSShorthandValue
+		}
+
+		c.Passthrough = true
+
+		return nil
+	}
+
+	type corsConfigAlias CORSConfig
+
+	return value.Decode((*corsConfigAlias)(c))
+}
+
+func (c *CORSConfig) Validate(field string) error {
+	if c.Passthrough {
+		if c.hasPolicy() {
+			msg := fmt.Sprintf("%s: passthrough can not be combined with other cors options", field)
+
+			return &ValidationError{msg}
+		}
+
+		return nil
+	}
+
+	errs := make([]error, 0, 1+len(c.AllowedOrigins)+len(c.AllowedMethods))
+
+	errs = append(errs, ValidateDuration(joinPath(field, "max-age"), c.MaxAge, true))
+
+	for i, origin := range c.AllowedOrigins {
+		errs = append(errs, ValidateGlobPattern(joinPath(field, "allowed-origins", index(i)), origin))
+	}
+
+	for i, method := range c.AllowedMethods {
+		errs = append(errs, ValidateMethod(joinPath(field, "allowed-methods", index(i)), method, false))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *CORSConfig) hasPolicy() bool {
+	return len(c.AllowedOrigins) > 0 ||
+		len(c.AllowedMethods) > 0 ||
+		len(c.AllowedHeaders) > 0 ||
+		len(c.ExposedHeaders) > 0 ||
+		c.AllowCredentials != nil ||
+		c.MaxAge != 0
+}
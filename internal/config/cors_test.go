@@ -0,0 +1,169 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCORSConfigCredentialsAllowed(t *testing.T) {
+	enabled, disabled := true, false
+
+	t.Run("allowed by default", func(t *testing.T) {
+		assert.True(t, (&config.CORSConfig{}).CredentialsAllowed())
+	})
+
+	t.Run("allowed when enabled", func(t *testing.T) {
+		assert.True(t, (&config.CORSConfig{AllowCredentials: &enabled}).CredentialsAllowed())
+	})
+
+	t.Run("not allowed when disabled", func(t *testing.T) {
+		assert.False(t, (&config.CORSConfig{AllowCredentials: &disabled}).CredentialsAllowed())
+	})
+}
+
+func TestCORSConfigClone(t *testing.T) {
+	disabled := false
+	cfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{"GET"},
+		AllowedHeaders:   []string{"Content-Type"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: &disabled,
+		MaxAge:           time.Minute,
+	}
+
+	cloned := cfg.Clone()
+
+	assert.Equal(t, cfg, cloned)
+	assert.NotSame(t, cfg.AllowCredentials, cloned.AllowCredentials)
+	assert.NotSame(t, &cfg.AllowedOrigins[0], &cloned.AllowedOrigins[0])
+}
+
+func TestCORSConfigUnmarshalYAML(t *testing.T) {
+	t.Run("passthrough shorthand", func(t *testing.T) {
+		var cfg config.CORSConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(`passthrough`), &cfg))
+		assert.Equal(t, config.CORSConfig{Passthrough: true}, cfg)
+	})
+
+	t.Run("unknown shorthand", func(t *testing.T) {
+		var cfg config.CORSConfig
+
+		require.ErrorIs(t, yaml.Unmarshal([]byte(`strict`), &cfg), config.ErrCORSShorthandValue)
+	})
+
+	t.Run("map form decoded normally", func(t *testing.T) {
+		const input = `
+allowed-origins:
+  - https://*.example.com
+allowed-methods: [GET, POST]
+allowed-headers: [Content-Type]
+exposed-headers: [X-Request-Id]
+allow-credentials: false
+max-age: 10m
+`
+
+		var cfg config.CORSConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(input), &cfg))
+
+		disabled := false
+		assert.Equal(t, config.CORSConfig{
+			AllowedOrigins:   []string{"https://*.example.com"},
+			AllowedMethods:   []string{"GET", "POST"},
+			AllowedHeaders:   []string{"Content-Type"},
+			ExposedHeaders:   []string{"X-Request-Id"},
+			AllowCredentials: &disabled,
+			MaxAge:           10 * time.Minute,
+		}, cfg)
+	})
+}
+
+func TestCORSShorthandInMapping(t *testing.T) {
+	const input = `
+from: http://localhost:3000
+to: https://api.example.com
+cors: passthrough
+`
+
+	var actual config.Mapping
+
+	require.NoError(t, yaml.Unmarshal([]byte(input), &actual))
+
+	assert.True(t, actual.CORS.Passthrough)
+}
+
+func TestCORSValidator(t *testing.T) {
+	const field = "mappings[0].cors"
+
+	t.Run("valid cases", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			value config.CORSConfig
+		}{
+			{
+				name:  "default",
+				value: config.CORSConfig{},
+			},
+			{
+				name:  "passthrough",
+				value: config.CORSConfig{Passthrough: true},
+			},
+			{
+				name: "full policy",
+				value: config.CORSConfig{
+					AllowedOrigins: []string{"*", "https://*.example.com"},
+					AllowedMethods: []string{"GET", "POST"},
+					MaxAge:         time.Hour,
+				},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.NoError(t, tc.value.Validate(field))
+			})
+		}
+	})
+
+	t.Run("invalid cases", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			value config.CORSConfig
+			error string
+		}{
+			{
+				name:  "passthrough with policy",
+				value: config.CORSConfig{Passthrough: true, AllowedMethods: []string{"GET"}},
+				error: "mappings[0].cors: passthrough can not be combined with other cors options",
+			},
+			{
+				name:  "invalid origin glob",
+				value: config.CORSConfig{AllowedOrigins: []string{"https://[.example.com"}},
+				error: "mappings[0].cors.allowed-origins[0] is not a valid glob pattern",
+			},
+			{
+				name:  "invalid method",
+				value: config.CORSConfig{AllowedMethods: []string{"FETCH"}},
+				error: "mappings[0].cors.allowed-methods[0] must be one of",
+			},
+			{
+				name:  "negative max age",
+				value: config.CORSConfig{MaxAge: -time.Second},
+				error: "mappings[0].cors.max-age must be greater than or equal to 0",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.ErrorContains(t, tc.value.Validate(field), tc.error)
+			})
+		}
+	})
+}
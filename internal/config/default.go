@@ -10,11 +10,13 @@ const (
 	defaultHTTPSPort      = 443
 	DefaultExpirationTime = 30 * time.Minute
 	DefaultMaxSize        = 100 * 1024 * 1024 // 100 MB
+	DefaultListen         = "127.0.0.1"
 )
 
 func defaultConfig() *UncorsConfig {
 	return &UncorsConfig{
 		Mappings: Mappings{},
+		Listen:   DefaultListen,
 		CacheConfig: CacheConfig{
 			ExpirationTime: DefaultExpirationTime,
 			MaxSize:        DefaultMaxSize,
@@ -1,15 +1,36 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	HARFormatHAR    = "har"
+	HARFormatNDJSON = "ndjson"
+)
+
 type HARConfig struct {
-	File                 string `yaml:"file"`
-	CaptureSecureHeaders bool   `yaml:"capture-secure-headers"`
+	File                 string       `yaml:"file"`
+	CaptureSecureHeaders bool         `yaml:"capture-secure-headers"`
+	Format               string       `yaml:"format"`
+	Rotate               HARRotation  `yaml:"rotate"`
+	Include              HARFilter    `yaml:"include"`
+	Exclude              HARFilter    `yaml:"exclude"`
+	MaxBodySize          int64        `yaml:"max-body-size"`
+	Redact               HARRedaction `yaml:"redact"`
+}
+
+// HARRotation starts a new file when the current one gets too big, has too
+// many entries or is too old. Zero values disable the corresponding limit.
+type HARRotation struct {
+	MaxSize    int64         `yaml:"max-size"`
+	MaxEntries int           `yaml:"max-entries"`
+	Interval   time.Duration `yaml:"interval"`
 }
 
 func (h *HARConfig) Enabled() bool {
@@ -20,6 +41,12 @@ func (h *HARConfig) Clone() HARConfig {
 	return HARConfig{
 		File:                 h.File,
 		CaptureSecureHeaders: h.CaptureSecureHeaders,
+		Format:               h.Format,
+		Rotate:               h.Rotate,
+		Include:              h.Include.Clone(),
+		Exclude:              h.Exclude.Clone(),
+		MaxBodySize:          h.MaxBodySize,
+		Redact:               h.Redact.Clone(),
 	}
 }
 
@@ -40,9 +67,43 @@ func (h *HARConfig) Validate(field string) error {
 		return nil
 	}
 
+	var errs []error
+
 	if filepath.Ext(h.File) == "" {
-		return &ValidationError{fmt.Sprintf("%s: HAR file path %q must have a file extension (e.g. .har)", field, h.File)}
+		errs = append(errs, &ValidationError{
+			fmt.Sprintf("%s: HAR file path %q must have a file extension (e.g. .har)", field, h.File),
+		})
+	}
+
+	if h.Format != "" && h.Format != HARFormatHAR && h.Format != HARFormatNDJSON {
+		errs = append(errs, &ValidationError{fmt.Sprintf(
+			"%s must be %s or %s", joinPath(field, "format"), HARFormatHAR, HARFormatNDJSON,
+		)})
 	}
 
-	return nil
+	if h.Rotate.MaxSize < 0 {
+		errs = append(errs, &ValidationError{
+			fmt.Sprintf("%s must be greater than or equal to 0", joinPath(field, "rotate", "max-size")),
+		})
+	}
+
+	if h.Rotate.MaxEntries < 0 {
+		errs = append(errs, &ValidationError{
+			fmt.Sprintf("%s must be greater than or equal to 0", joinPath(field, "rotate", "max-entries")),
+		})
+	}
+
+	errs = append(errs, ValidateDuration(joinPath(field, "rotate", "interval"), h.Rotate.Interval, true))
+
+	if h.MaxBodySize < 0 {
+		errs = append(errs, &ValidationError{
+			fmt.Sprintf("%s must be greater than or equal to 0", joinPath(field, "max-body-size")),
+		})
+	}
+
+	errs = append(errs, h.Include.Validate(joinPath(field, "include")))
+	errs = append(errs, h.Exclude.Validate(joinPath(field, "exclude")))
+	errs = append(errs, h.Redact.Validate(joinPath(field, "redact")))
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HARFilter selects recorded entries. An entry matches the filter when it
+// matches every criterion that is set; empty criteria match everything.
+type HARFilter struct {
+	Paths        []string `yaml:"paths"`
+	Methods      []string `yaml:"methods"`
+	Statuses     []string `yaml:"statuses"`
+	ContentTypes []string `yaml:"content-types"`
+}
+
+func (f *HARFilter) Clone() HARFilter {
+	return HARFilter{
+		Paths:        slices.Clone(f.Paths),
+		Methods:      slices.Clone(f.Methods),
+		Statuses:     slices.Clone(f.Statuses),
+		ContentTypes: slices.Clone(f.ContentTypes),
+	}
+}
+
+func (f *HARFilter) Empty() bool {
+	return len(f.Paths) == 0 && len(f.Methods) == 0 && len(f.Statuses) == 0 && len(f.ContentTypes) == 0
+}
+
+func (f *HARFilter) Validate(field string) error {
+	errs := make([]error, 0, len(f.Paths)+len(f.Methods)+len(f.Statuses))
+
+	for i, path := range f.Paths {
+		errs = append(errs, ValidateGlobPattern(joinPath(field, "paths", index(i)), path))
+	}
+
+	for i, method := range f.Methods {
+		errs = append(errs, ValidateMethod(joinPath(field, "methods", index(i)), method, false))
+	}
+
+	for i, status := range f.Statuses {
+		if _, _, err := ParseStatusRange(status); err != nil {
+			errs = append(errs, &ValidationError{fmt.Sprintf(
+				"%s must be a status code, a class like 4xx or a range like 400-499",
+				joinPath(field, "statuses", index(i)),
+			)})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+var errInvalidStatusRange = errors.New("invalid status range")
+
+// ParseStatusRange parses a status code (404), a status class (4xx) or an
+// inclusive range (400-499) and returns its bounds.
+func ParseStatusRange(value string) (int, int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if class, ok := strings.CutSuffix(value, "xx"); ok {
+		code, err := strconv.Atoi(class)
+		if err != nil || code < 1 || code > 5 {
+			return 0, 0, errInvalidStatusRange
+		}
+
+		return code * 100, code*100 + 99, nil
+	}
+
+	fromValue, toValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		toValue = fromValue
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(fromValue))
+	if err != nil {
+		return 0, 0, errInvalidStatusRange
+	}
+
+	to, err := strconv.Atoi(strings.TrimSpace(toValue))
+	if err != nil {
+		return 0, 0, errInvalidStatusRange
+	}
+
+	if from < 100 || to > 599 || from > to {
+		return 0, 0, errInvalidStatusRange
+	}
+
+	return from, to, nil
+}
+
+// HARRedaction masks secrets before entries are written. Headers, query
+// parameters and JSON fields are matched by name ignoring case; patterns are
+// regular expressions applied to bodies, header values and URLs.
+type HARRedaction struct {
+	Headers    []string `yaml:"headers"`
+	Query      []string `yaml:"query"`
+	JSONFields []string `yaml:"json-fields"`
+	Patterns   []string `yaml:"patterns"`
+}
+
+func (r *HARRedaction) Clone() HARRedaction {
+	return HARRedaction{
+		Headers:    slices.Clone(r.Headers),
+		Query:      slices.Clone(r.Query),
+		JSONFields: slices.Clone(r.JSONFields),
+		Patterns:   slices.Clone(r.Patterns),
+	}
+}
+
+func (r *HARRedaction) Validate(field string) error {
+	errs := make([]error, 0, len(r.Patterns))
+
+	for i, pattern := range r.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, &ValidationError{fmt.Sprintf(
+				"%s is not a valid regular expression",
+				joinPath(field, "patterns", index(i)),
+			)})
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseStatusRange(t *testing.T) {
+	t.Run("valid values", func(t *testing.T) {
+		tests := []struct {
+			value string
+			from  int
+			to    int
+		}{
+			{value: "404", from: 404, to: 404},
+			{value: "2xx", from: 200, to: 299},
+			{value: "5XX", from: 500, to: 599},
+			{value: "400-499", from: 400, to: 499},
+			{value: " 301 - 308 ", from: 301, to: 308},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.value, func(t *testing.T) {
+				from, to, err := config.ParseStatusRange(testCase.value)
+
+				require.NoError(t, err)
+				assert.Equal(t, testCase.from, from)
+				assert.Equal(t, testCase.to, to)
+			})
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		values := []string{"", "ok", "6xx", "0xx", "x", "99", "600", "499-400", "200-", "-200"}
+
+		for _, value := range values {
+			t.Run(value, func(t *testing.T) {
+				_, _, err := config.ParseStatusRange(value)
+
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestHARFilterUnmarshalYAML(t *testing.T) {
+	const input = `
+paths: [/api/**]
+methods: [GET, POST]
+statuses: [2xx, 404, 500-599]
+content-types: [application/json]
+`
+
+	var filter config.HARFilter
+
+	require.NoError(t, yaml.Unmarshal([]byte(input), &filter))
+	assert.Equal(t, config.HARFilter{
+		Paths:        []string{"/api/**"},
+		Methods:      []string{"GET", "POST"},
+		Statuses:     []string{"2xx", "404", "500-599"},
+		ContentTypes: []string{"application/json"},
+	}, filter)
+}
+
+func TestHARFilterClone(t *testing.T) {
+	filter := config.HARFilter{
+		Paths:        []string{"/api/**"},
+		Methods:      []string{"GET"},
+		Statuses:     []string{"2xx"},
+		ContentTypes: []string{"application/json"},
+	}
+
+	clone := filter.Clone()
+
+	assert.Equal(t, filter, clone)
+	assert.NotSame(t, &filter.Paths[0], &clone.Paths[0])
+	assert.NotSame(t, &filter.Statuses[0], &clone.Statuses[0])
+}
+
+func TestHARRedactionClone(t *testing.T) {
+	redaction := config.HARRedaction{
+		Headers:    []string{"X-Api-Key"},
+		Query:      []string{"access_token"},
+		JSONFields: []string{"password"},
+		Patterns:   []string{"secret"},
+	}
+
+	clone := redaction.Clone()
+
+	assert.Equal(t, redaction, clone)
+	assert.NotSame(t, &redaction.JSONFields[0], &clone.JSONFields[0])
+}
+
+func TestHARFilterValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter config.HARFilter
+		error  string
+	}{
+		{
+			name:   "invalid path",
+			filter: config.HARFilter{Paths: []string{"/api/["}},
+			error:  "har.include.paths[0] is not a valid glob pattern",
+		},
+		{
+			name:   "invalid method",
+			filter: config.HARFilter{Methods: []string{"FETCH"}},
+			error:  "har.include.methods[0] must be one of GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE",
+		},
+		{
+			name:   "invalid status",
+			filter: config.HARFilter{Statuses: []string{"2xx", "ok"}},
+			error:  "har.include.statuses[1] must be a status code, a class like 4xx or a range like 400-499",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.EqualError(t, testCase.filter.Validate("har.include"), testCase.error)
+		})
+	}
+}
+
+func TestHARRedactionValidate(t *testing.T) {
+	t.Run("valid patterns", func(t *testing.T) {
+		redaction := config.HARRedaction{Patterns: []string{`Bearer \S+`}}
+
+		assert.NoError(t, redaction.Validate("har.redact"))
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		redaction := config.HARRedaction{Patterns: []string{`Bearer \S+`, `(`}}
+
+		assert.EqualError(t, redaction.Validate("har.redact"), "har.redact.patterns[1] is not a valid regular expression")
+	})
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	HARReplayOnMissNotFound = "not-found"
+	HARReplayOnMissProxy    = "proxy"
+	HARReplayOnMissError    = "error"
+)
+
+// HARReplayConfig serves responses recorded in a HAR file instead of proxying
+// requests to the upstream.
+type HARReplayConfig struct {
+	File             string   `yaml:"file"`
+	IgnoreQueryOrder bool     `yaml:"ignore-query-order"`
+	MatchHeaders     bool     `yaml:"match-headers"`
+	IgnoreHeaders    []string `yaml:"ignore-headers"`
+	MatchBody        bool     `yaml:"match-body"`
+	OnMiss           string   `yaml:"on-miss"`
+}
+
+func (h *HARReplayConfig) Clone() HARReplayConfig {
+	return HARReplayConfig{
+		File:             h.File,
+		IgnoreQueryOrder: h.IgnoreQueryOrder,
+		MatchHeaders:     h.MatchHeaders,
+		IgnoreHeaders:    slices.Clone(h.IgnoreHeaders),
+		MatchBody:        h.MatchBody,
+		OnMiss:           h.OnMiss,
+	}
+}
+
+// UnmarshalYAML accepts a HAR file path as a shorthand for the default options.
+func (h *HARReplayConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		h.File = value.Value
+
+		return nil
+	}
+
+	type harReplayConfigAlias HARReplayConfig
+
+	return value.Decode((*harReplayConfigAlias)(h))
+}
+
+func (h *HARReplayConfig) Enabled() bool {
+	return h.File != ""
+}
+
+// Miss returns the behavior for requests without a recorded entry.
+func (h *HARReplayConfig) Miss() string {
+	if h.OnMiss == "" {
+		return HARReplayOnMissNotFound
+	}
+
+	return h.OnMiss
+}
+
+func (h *HARReplayConfig) Validate(field string, fs afero.Fs) error {
+	if !h.Enabled() {
+		if h.IgnoreQueryOrder || h.MatchHeaders || len(h.IgnoreHeaders) > 0 || h.MatchBody || h.OnMiss != "" {
+			return &ValidationError{fmt.Sprintf("%s must be set", joinPath(field, "file"))}
+		}
+
+		return nil
+	}
+
+	errs := []error{ValidateFile(joinPath(field, "file"), h.File, fs)}
+
+	if !slices.Contains([]string{HARReplayOnMissNotFound, HARReplayOnMissProxy, HARReplayOnMissError}, h.Miss()) {
+		errs = append(errs, &ValidationError{fmt.Sprintf(
+			"%s must be %s, %s or %s", joinPath(field, "on-miss"),
+			HARReplayOnMissNotFound, HARReplayOnMissProxy, HARReplayOnMissError,
+		)})
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHARReplayConfigUnmarshalYAML(t *testing.T) {
+	t.Run("string shorthand sets file", func(t *testing.T) {
+		var actual config.HARReplayConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(`./session.har`), &actual))
+		assert.Equal(t, config.HARReplayConfig{File: "./session.har"}, actual)
+	})
+
+	t.Run("map form decoded normally", func(t *testing.T) {
+		const input = `
+file: ./session.har
+ignore-query-order: true
+match-headers: true
+ignore-headers: [User-Agent]
+match-body: true
+on-miss: proxy
+`
+
+		var actual config.HARReplayConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(input), &actual))
+		assert.Equal(t, config.HARReplayConfig{
+			File:             "./session.har",
+			IgnoreQueryOrder: true,
+			MatchHeaders:     true,
+			IgnoreHeaders:    []string{"User-Agent"},
+			MatchBody:        true,
+			OnMiss:           config.HARReplayOnMissProxy,
+		}, actual)
+	})
+}
+
+func TestHARReplayConfigClone(t *testing.T) {
+	original := config.HARReplayConfig{
+		File:             "session.har",
+		IgnoreQueryOrder: true,
+		MatchHeaders:     true,
+		IgnoreHeaders:    []string{"User-Agent"},
+		MatchBody:        true,
+		OnMiss:           config.HARReplayOnMissError,
+	}
+
+	cloned := original.Clone()
+
+	assert.Equal(t, original, cloned)
+	cloned.IgnoreHeaders[0] = "Cookie"
+	assert.Equal(t, "User-Agent", original.IgnoreHeaders[0])
+}
+
+func TestHARReplayConfigMiss(t *testing.T) {
+	assert.Equal(t, config.HARReplayOnMissNotFound, (&config.HARReplayConfig{}).Miss())
+	assert.Equal(t, config.HARReplayOnMissProxy, (&config.HARReplayConfig{OnMiss: "proxy"}).Miss())
+}
+
+func TestHARReplayConfigValidate(t *testing.T) {
+	fs := testutils.FsFromMap(t, map[string]string{"/session.har": "{}"})
+
+	tests := []struct {
+		name   string
+		config config.HARReplayConfig
+		errors []string
+	}{
+		{name: "disabled", config: config.HARReplayConfig{}},
+		{name: "valid", config: config.HARReplayConfig{File: "/session.har", OnMiss: config.HARReplayOnMissProxy}},
+		{
+			name:   "options without file",
+			config: config.HARReplayConfig{MatchBody: true},
+			errors: []string{"har-replay.file must be set"},
+		},
+		{
+			name:   "missing file",
+			config: config.HARReplayConfig{File: "/missing.har"},
+			errors: []string{"har-replay.file /missing.har does not exist"},
+		},
+		{
+			name:   "unknown miss behavior",
+			config: config.HARReplayConfig{File: "/session.har", OnMiss: "404"},
+			errors: []string{"har-replay.on-miss must be not-found, proxy or error"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.config.Validate("har-replay", fs)
+
+			if len(testCase.errors) == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+
+			for _, message := range testCase.errors {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
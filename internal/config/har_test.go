@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,21 @@ func TestHARConfigUnmarshalYAML(t *testing.T) {
 		const input = `
 file: ./out.har
 capture-secure-headers: true
+format: ndjson
+rotate:
+  max-size: 1048576
+  max-entries: 1000
+  interval: 1h
+include:
+  paths: [/api/**]
+exclude:
+  statuses: [5xx]
+max-body-size: 65536
+redact:
+  headers: [X-Api-Key]
+  query: [access_token]
+  json-fields: [password]
+  patterns: ['Bearer \S+']
 `
 
 		var cfg config.HARConfig
@@ -41,6 +57,21 @@ capture-secure-headers: true
 		assert.Equal(t, config.HARConfig{
 			File:                 "./out.har",
 			CaptureSecureHeaders: true,
+			Format:               config.HARFormatNDJSON,
+			Rotate: config.HARRotation{
+				MaxSize:    1048576,
+				MaxEntries: 1000,
+				Interval:   time.Hour,
+			},
+			Include:     config.HARFilter{Paths: []string{"/api/**"}},
+			Exclude:     config.HARFilter{Statuses: []string{"5xx"}},
+			MaxBodySize: 65536,
+			Redact: config.HARRedaction{
+				Headers:    []string{"X-Api-Key"},
+				Query:      []string{"access_token"},
+				JSONFields: []string{"password"},
+				Patterns:   []string{`Bearer \S+`},
+			},
 		}, cfg)
 	})
 }
@@ -78,6 +109,14 @@ func TestHARValidator(t *testing.T) {
 				name:  "path with directory and extension",
 				value: config.HARConfig{File: "/tmp/trace.har"},
 			},
+			{
+				name: "ndjson format with rotation",
+				value: config.HARConfig{
+					File:   "trace.ndjson",
+					Format: config.HARFormatNDJSON,
+					Rotate: config.HARRotation{MaxSize: 1024, MaxEntries: 10, Interval: time.Minute},
+				},
+			},
 		}
 
 		for _, tc := range cases {
@@ -91,5 +130,53 @@ func TestHARValidator(t *testing.T) {
 		t.Run("file path without extension", func(t *testing.T) {
 			assert.Error(t, (&config.HARConfig{File: "outputfile"}).Validate("mappings[0].har"))
 		})
+
+		cases := []struct {
+			name  string
+			value config.HARConfig
+			error string
+		}{
+			{
+				name:  "unknown format",
+				value: config.HARConfig{File: "out.har", Format: "xml"},
+				error: "mappings[0].har.format must be har or ndjson",
+			},
+			{
+				name:  "negative max size",
+				value: config.HARConfig{File: "out.har", Rotate: config.HARRotation{MaxSize: -1}},
+				error: "mappings[0].har.rotate.max-size must be greater than or equal to 0",
+			},
+			{
+				name:  "negative max entries",
+				value: config.HARConfig{File: "out.har", Rotate: config.HARRotation{MaxEntries: -1}},
+				error: "mappings[0].har.rotate.max-entries must be greater than or equal to 0",
+			},
+			{
+				name:  "negative interval",
+				value: config.HARConfig{File: "out.har", Rotate: config.HARRotation{Interval: -time.Second}},
+				error: "mappings[0].har.rotate.interval must be greater than or equal to 0",
+			},
+			{
+				name:  "negative max body size",
+				value: config.HARConfig{File: "out.har", MaxBodySize: -1},
+				error: "mappings[0].har.max-body-size must be greater than or equal to 0",
+			},
+			{
+				name:  "invalid exclude status",
+				value: config.HARConfig{File: "out.har", Exclude: config.HARFilter{Statuses: []string{"9xx"}}},
+				error: "mappings[0].har.exclude.statuses[0] must be a status code, a class like 4xx or a range like 400-499",
+			},
+			{
+				name:  "invalid redact pattern",
+				value: config.HARConfig{File: "out.har", Redact: config.HARRedaction{Patterns: []string{"["}}},
+				error: "mappings[0].har.redact.patterns[0] is not a valid regular expression",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.EqualError(t, tc.value.Validate("mappings[0].har"), tc.error)
+			})
+		}
 	})
 }
@@ -67,6 +67,7 @@ func NormaliseMappings(mappings Mappings) Mappings {
 	for _, mapping := range mappings {
 		normalizedMapping := mapping.Clone()
 		normalizedMapping.From = normalizeHost(mapping.From)
+		normalizedMapping.Listen = normalizeListen(mapping.Listen)
 		processedMappings = append(processedMappings, normalizedMapping)
 	}
 
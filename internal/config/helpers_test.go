@@ -78,6 +78,24 @@ func TestNormaliseMappings(t *testing.T) {
 					{From: hosts.Localhost.HTTP(), To: hosts.Github.HTTPS()},
 				},
 			},
+			{
+				name: "bracketed IPv6 listen address - should drop brackets",
+				mappings: config.Mappings{
+					{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: "[::1]"},
+				},
+				expected: config.Mappings{
+					{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: "::1"},
+				},
+			},
+			{
+				name: "all interfaces listen address - should keep as is",
+				mappings: config.Mappings{
+					{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: config.AllInterfaces},
+				},
+				expected: config.Mappings{
+					{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: config.AllInterfaces},
+				},
+			},
 			{
 				name: "mixed ports in different mappings",
 				mappings: config.Mappings{
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AllInterfaces is the listen value that binds the server on every IPv4 and
+// IPv6 interface of the machine.
+const AllInterfaces = "*"
+
+// ListenHost converts a listen value from the configuration into the host
+// part of a listener address: brackets around IPv6 addresses are dropped and
+// AllInterfaces becomes an empty host.
+func ListenHost(value string) string {
+	if value == AllInterfaces {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+}
+
+// IsLoopbackListen reports whether a listen value only accepts connections
+// from the local machine.
+func IsLoopbackListen(value string) bool {
+	ip := net.ParseIP(ListenHost(value))
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// normalizeListen drops brackets around IPv6 addresses so equal addresses
+// written differently end up in the same listener.
+func normalizeListen(value string) string {
+	if value == AllInterfaces {
+		return value
+	}
+
+	return ListenHost(value)
+}
+
+// listenOverlaps reports whether listeners on the same port with the listen
+// values a and b cannot be bound together. The IPv4 unspecified address
+// overlaps every IPv4 address, all interfaces and the IPv6 unspecified address
+// overlap every address.
+func listenOverlaps(a, b string) bool {
+	ipA, ipB := net.ParseIP(ListenHost(a)), net.ParseIP(ListenHost(b))
+	if ipA == nil || ipB == nil {
+		return a == AllInterfaces || b == AllInterfaces || a == b
+	}
+
+	return ipA.Equal(ipB) || coversListen(ipA, ipB) || coversListen(ipB, ipA)
+}
+
+func coversListen(wildcard, ip net.IP) bool {
+	if !wildcard.IsUnspecified() {
+		return false
+	}
+
+	return wildcard.To4() == nil || ip.To4() != nil
+}
+
+func ValidateListen(field, value string, allowEmpty bool) error {
+	if allowEmpty && value == "" {
+		return nil
+	}
+
+	if value == AllInterfaces || net.ParseIP(ListenHost(value)) != nil {
+		return nil
+	}
+
+	return &ValidationError{fmt.Sprintf("%s must be an IPv4 or IPv6 address or %q for all interfaces", field, AllInterfaces)}
+}
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenHost(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "127.0.0.1", expected: "127.0.0.1"},
+		{value: "0.0.0.0", expected: "0.0.0.0"},
+		{value: "::1", expected: "::1"},
+		{value: "[::1]", expected: "::1"},
+		{value: config.AllInterfaces, expected: ""},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.value, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, config.ListenHost(testCase.value))
+		})
+	}
+}
+
+func TestIsLoopbackListen(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "127.0.0.1", expected: true},
+		{value: "127.0.0.2", expected: true},
+		{value: "::1", expected: true},
+		{value: "[::1]", expected: true},
+		{value: "0.0.0.0", expected: false},
+		{value: "::", expected: false},
+		{value: "192.168.1.10", expected: false},
+		{value: config.AllInterfaces, expected: false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.value, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, config.IsLoopbackListen(testCase.value))
+		})
+	}
+}
+
+func TestValidateListen(t *testing.T) {
+	t.Run("valid values", func(t *testing.T) {
+		for _, value := range []string{"127.0.0.1", "0.0.0.0", "::1", "[::1]", "::", "fe80::1", config.AllInterfaces} {
+			t.Run(value, func(t *testing.T) {
+				assert.NoError(t, config.ValidateListen("listen", value, false))
+			})
+		}
+	})
+
+	t.Run("empty value is allowed when requested", func(t *testing.T) {
+		assert.NoError(t, config.ValidateListen("listen", "", true))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, value := range []string{"", "localhost", "127.0.0.1:8080", "300.0.0.1"} {
+			t.Run(value, func(t *testing.T) {
+				assert.EqualError(t, config.ValidateListen("mappings[0].listen", value, false),
+					`mappings[0].listen must be an IPv4 or IPv6 address or "*" for all interfaces`)
+			})
+		}
+	})
+}
+
+func TestPortGroupAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		group         config.PortGroup
+		defaultListen string
+		expected      string
+	}{
+		{
+			name:     "falls back to loopback",
+			group:    config.PortGroup{Port: 3000},
+			expected: "127.0.0.1:3000",
+		},
+		{
+			name:          "uses global listen address",
+			group:         config.PortGroup{Port: 3000},
+			defaultListen: "0.0.0.0",
+			expected:      "0.0.0.0:3000",
+		},
+		{
+			name:          "mapping listen address overrides global one",
+			group:         config.PortGroup{Port: 3000, Listen: "::1"},
+			defaultListen: "0.0.0.0",
+			expected:      "[::1]:3000",
+		},
+		{
+			name:          "all interfaces",
+			group:         config.PortGroup{Port: 443},
+			defaultListen: config.AllInterfaces,
+			expected:      ":443",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.group.Address(testCase.defaultListen))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// LuaPath lists the directories scripts load modules from with require.
+type LuaPath []string
+
+func (p LuaPath) Clone() LuaPath {
+	return slices.Clone(p)
+}
+
+// UnmarshalYAML accepts a single directory as a shorthand.
+func (p *LuaPath) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*p = LuaPath{value.Value}
+
+		return nil
+	}
+
+	return value.Decode((*[]string)(p))
+}
+
+func (p LuaPath) Validate(field string, fs afero.Fs) error {
+	errs := make([]error, 0, len(p))
+
+	for i, dir := range p {
+		errs = append(errs, ValidateDirectory(joinPath(field, index(i)), dir, fs))
+	}
+
+	return errors.Join(errs...)
+}
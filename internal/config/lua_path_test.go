@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLuaPathUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.LuaPath
+	}{
+		{
+			name:     "single directory shorthand",
+			input:    `./lua`,
+			expected: config.LuaPath{"./lua"},
+		},
+		{
+			name:     "list of directories",
+			input:    `[./lua, ./shared]`,
+			expected: config.LuaPath{"./lua", "./shared"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual config.LuaPath
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestLuaPathClone(t *testing.T) {
+	original := config.LuaPath{"./lua"}
+
+	cloned := original.Clone()
+	cloned[0] = "./other"
+
+	assert.Equal(t, config.LuaPath{"./lua"}, original)
+	assert.Nil(t, config.LuaPath(nil).Clone())
+}
+
+func TestLuaPathValidate(t *testing.T) {
+	fs := testutils.FsFromMap(t, map[string]string{
+		"/lua/utils.lua": `return {}`,
+	})
+
+	tests := []struct {
+		name     string
+		luaPath  config.LuaPath
+		expected string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "existing directory",
+			luaPath: config.LuaPath{"/lua"},
+		},
+		{
+			name:     "missing directory",
+			luaPath:  config.LuaPath{"/lua", "/missing"},
+			expected: "lua-path[1] directory does not exist",
+		},
+		{
+			name:     "file",
+			luaPath:  config.LuaPath{"/lua/utils.lua"},
+			expected: "lua-path[0] is not a directory",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.luaPath.Validate("lua-path", fs)
+
+			if testCase.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.expected)
+			}
+		})
+	}
+}
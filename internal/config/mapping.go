@@ -19,16 +19,25 @@ type Mapping struct {
 	Statics         StaticDirectories `yaml:"statics"`
 	Mocks           Mocks             `yaml:"mocks"`
 	Scripts         Scripts           `yaml:"scripts"`
-	Cache           CacheGlobs        `yaml:"cache"`
+	Middlewares     ScriptMiddlewares `yaml:"script-middlewares"`
+	ScriptStore     ScriptStoreConfig `yaml:"script-store"`
+	LuaPath         LuaPath           `yaml:"lua-path"`
+	Cache           CacheRules        `yaml:"cache"`
 	Rewrites        RewriteOptions    `yaml:"rewrites"`
 	OptionsHandling OptionsHandling   `yaml:"options-handling"`
 	HAR             HARConfig         `yaml:"har"`
+	Record          RecordConfig      `yaml:"record"`
+	HARReplay       HARReplayConfig   `yaml:"har-replay"`
+	CORS            CORSConfig        `yaml:"cors"`
+	BodyRewrite     BodyRewriteConfig `yaml:"body-rewrite"`
+	Listen          string            `yaml:"listen"`
 }
 
 var knownMappingFields = map[string]bool{
 	"from": true, "to": true, "statics": true, "mocks": true,
-	"scripts": true, "cache": true, "rewrites": true,
-	"options-handling": true, "har": true,
+	"scripts": true, "script-middlewares": true, "script-store": true, "lua-path": true, "cache": true, "rewrites": true,
+	"options-handling": true, "har": true, "record": true, "har-replay": true, "cors": true,
+	"body-rewrite": true, "listen": true,
 }
 
 func (m *Mapping) UnmarshalYAML(value *yaml.Node) error {
@@ -58,10 +67,18 @@ func (m *Mapping) Clone() Mapping {
 		Statics:         m.Statics.Clone(),
 		Mocks:           m.Mocks.Clone(),
 		Scripts:         m.Scripts.Clone(),
+		Middlewares:     m.Middlewares.Clone(),
+		ScriptStore:     m.ScriptStore.Clone(),
+		LuaPath:         m.LuaPath.Clone(),
 		Cache:           m.Cache.Clone(),
 		Rewrites:        m.Rewrites.Clone(),
 		OptionsHandling: m.OptionsHandling.Clone(),
 		HAR:             m.HAR.Clone(),
+		Record:          m.Record.Clone(),
+		HARReplay:       m.HARReplay.Clone(),
+		CORS:            m.CORS.Clone(),
+		BodyRewrite:     m.BodyRewrite.Clone(),
+		Listen:          m.Listen,
 	}
 }
 
@@ -93,12 +110,20 @@ func ValidateTLS(_ string, mapping Mapping, fs afero.Fs) error {
 }
 
 func (m *Mapping) Validate(field string, fs afero.Fs) error {
-	errs := make([]error, 0, 5+len(m.Statics)+len(m.Mocks)+len(m.Cache)+len(m.Rewrites)+len(m.Scripts))
+	errs := make([]error, 0, 10+len(m.Statics)+len(m.Mocks)+len(m.Cache)+len(m.Rewrites)+len(m.Scripts)+
+		len(m.Middlewares))
 
 	errs = append(errs, ValidateHost(joinPath(field, "from"), m.From))
 	errs = append(errs, ValidateHost(joinPath(field, "to"), m.To))
 	errs = append(errs, m.OptionsHandling.Validate(joinPath(field, "options-handling")))
 	errs = append(errs, m.HAR.Validate(joinPath(field, "har")))
+	errs = append(errs, m.Record.Validate(joinPath(field, "record")))
+	errs = append(errs, m.HARReplay.Validate(joinPath(field, "har-replay"), fs))
+	errs = append(errs, m.CORS.Validate(joinPath(field, "cors")))
+	errs = append(errs, m.BodyRewrite.Validate(joinPath(field, "body-rewrite")))
+	errs = append(errs, m.ScriptStore.Validate(joinPath(field, "script-store"), fs))
+	errs = append(errs, m.LuaPath.Validate(joinPath(field, "lua-path"), fs))
+	errs = append(errs, ValidateListen(joinPath(field, "listen"), m.Listen, true))
 	errs = append(errs, ValidateTLS(field, *m, fs))
 
 	for i, static := range m.Statics {
@@ -109,8 +134,8 @@ func (m *Mapping) Validate(field string, fs afero.Fs) error {
 		errs = append(errs, mock.Validate(joinPath(field, "mocks", index(i)), fs))
 	}
 
-	for i, glob := range m.Cache {
-		errs = append(errs, ValidateGlobPattern(joinPath(field, "cache", index(i)), glob))
+	for i, rule := range m.Cache {
+		errs = append(errs, rule.Validate(joinPath(field, "cache", index(i))))
 	}
 
 	for i, rewrite := range m.Rewrites {
@@ -121,6 +146,10 @@ func (m *Mapping) Validate(field string, fs afero.Fs) error {
 		errs = append(errs, script.Validate(joinPath(field, "scripts", index(i)), fs))
 	}
 
+	for i, middleware := range m.Middlewares {
+		errs = append(errs, middleware.Validate(joinPath(field, "script-middlewares", index(i)), fs))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/server"
@@ -55,6 +56,26 @@ har: ./recordings/api.har
 					HAR:  config.HARConfig{File: "./recordings/api.har"},
 				},
 			},
+			{
+				name: "mapping with mixed cache rules",
+				input: `
+from: http://localhost:3000
+to: https://api.example.com
+cache:
+  - /api/static/**
+  - path: /api/search
+    methods: [POST]
+    expiration-time: 10s
+`,
+				expected: config.Mapping{
+					From: hosts.Localhost.HTTPPort(3000),
+					To:   hosts.Parse("https://api.example.com"),
+					Cache: config.CacheRules{
+						{Path: "/api/static/**"},
+						{Path: "/api/search", Methods: []string{http.MethodPost}, ExpirationTime: 10 * time.Second},
+					},
+				},
+			},
 		}
 
 		for _, testCase := range tests {
@@ -167,9 +188,9 @@ func TestMappingValidator(t *testing.T) {
 							},
 						},
 					},
-					Cache: config.CacheGlobs{
-						"/api/constants",
-						"/**",
+					Cache: config.CacheRules{
+						{Path: "/api/constants"},
+						{Path: "/**"},
 					},
 				},
 			},
@@ -180,7 +201,7 @@ func TestMappingValidator(t *testing.T) {
 					To:      hosts.Github.Host(),
 					Statics: []config.StaticDirectory{},
 					Mocks:   []config.Mock{},
-					Cache:   config.CacheGlobs{},
+					Cache:   config.CacheRules{},
 				},
 			},
 		}
@@ -208,7 +229,7 @@ func TestMappingValidator(t *testing.T) {
 					To:      hosts.Github.Host(),
 					Statics: []config.StaticDirectory{},
 					Mocks:   []config.Mock{},
-					Cache:   config.CacheGlobs{},
+					Cache:   config.CacheRules{},
 				},
 				error: "mapping.from must not be empty",
 			},
@@ -219,7 +240,7 @@ func TestMappingValidator(t *testing.T) {
 					To:      hosts.Parse(""),
 					Statics: []config.StaticDirectory{},
 					Mocks:   []config.Mock{},
-					Cache:   config.CacheGlobs{},
+					Cache:   config.CacheRules{},
 				},
 				error: "mapping.to must not be empty",
 			},
@@ -233,7 +254,7 @@ func TestMappingValidator(t *testing.T) {
 						{Path: "/", Dir: ""},
 					},
 					Mocks: []config.Mock{},
-					Cache: config.CacheGlobs{},
+					Cache: config.CacheRules{},
 				},
 				error: "mapping.statics[1].directory must not be empty",
 			},
@@ -255,7 +276,7 @@ func TestMappingValidator(t *testing.T) {
 							},
 						},
 					},
-					Cache: config.CacheGlobs{},
+					Cache: config.CacheRules{},
 				},
 				error: "mapping.mocks[0].method must be one of GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE",
 			},
@@ -266,12 +287,25 @@ func TestMappingValidator(t *testing.T) {
 					To:      hosts.Github.Host(),
 					Statics: []config.StaticDirectory{},
 					Mocks:   []config.Mock{},
-					Cache: config.CacheGlobs{
-						"/api/info[",
+					Cache: config.CacheRules{
+						{Path: "/api/info["},
 					},
 				},
 				error: "mapping.cache[0] is not a valid glob pattern",
 			},
+			{
+				name: "mapping with invalid cache rule status code",
+				value: config.Mapping{
+					From:    hosts.Parse("localhost"),
+					To:      hosts.Github.Host(),
+					Statics: []config.StaticDirectory{},
+					Mocks:   []config.Mock{},
+					Cache: config.CacheRules{
+						{Path: "/api/**", StatusCodes: []int{99}},
+					},
+				},
+				error: "mapping.cache[0].status-codes[0] code must be in range 100-599",
+			},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,11 +14,30 @@ import (
 type Mappings []Mapping
 
 type PortGroup struct {
+	Listen   string
 	Port     int
 	Scheme   string
 	Mappings Mappings
 }
 
+// EffectiveListen returns the listen value of the group. Mappings without
+// their own listen value use defaultListen, falling back to DefaultListen.
+func (g PortGroup) EffectiveListen(defaultListen string) string {
+	switch {
+	case g.Listen != "":
+		return g.Listen
+	case defaultListen != "":
+		return defaultListen
+	default:
+		return DefaultListen
+	}
+}
+
+// Address returns the listener address of the group.
+func (g PortGroup) Address(defaultListen string) string {
+	return net.JoinHostPort(ListenHost(g.EffectiveListen(defaultListen)), strconv.Itoa(g.Port))
+}
+
 type PortGroups []PortGroup
 
 func (m Mappings) String() string {
@@ -36,8 +57,8 @@ func (m Mappings) String() string {
 			lines = append(lines, fmt.Sprintf("    static: %s", static.String()))
 		}
 
-		for _, cacheGlob := range mapping.Cache {
-			lines = append(lines, fmt.Sprintf("    cache: %s", cacheGlob))
+		for _, cacheRule := range mapping.Cache {
+			lines = append(lines, fmt.Sprintf("    cache: %s", cacheRule.String()))
 		}
 	}
 
@@ -48,8 +69,13 @@ func extractHost(item Mapping) string {
 	return item.From.Hostname
 }
 
-func (m Mappings) GroupByPort() PortGroups {
+// GroupByPort groups the mappings by the listener they are served on. Mappings
+// without their own listen value share the listener of defaultListen, so a
+// mapping that repeats the global listen value does not get a second listener
+// on the same address.
+func (m Mappings) GroupByPort(defaultListen string) PortGroups {
 	type portKey struct {
+		listen string
 		port   int
 		scheme string
 	}
@@ -71,13 +97,15 @@ func (m Mappings) GroupByPort() PortGroups {
 			port = defaultHTTPSPort
 		}
 
-		key := portKey{port: port, scheme: mapping.From.Scheme}
+		listen := normalizeListen(PortGroup{Listen: mapping.Listen}.EffectiveListen(defaultListen))
+		key := portKey{listen: listen, port: port, scheme: mapping.From.Scheme}
 		grouped[key] = append(grouped[key], mapping)
 	}
 
 	result := make(PortGroups, 0, len(grouped))
 	for key, mappings := range grouped {
 		result = append(result, PortGroup{
+			Listen:   key.listen,
 			Port:     key.port,
 			Scheme:   key.scheme,
 			Mappings: mappings,
@@ -89,8 +117,31 @@ func (m Mappings) GroupByPort() PortGroups {
 			return result[i].Port < result[j].Port
 		}
 
-		return result[i].Scheme < result[j].Scheme
+		if result[i].Scheme != result[j].Scheme {
+			return result[i].Scheme < result[j].Scheme
+		}
+
+		return result[i].Listen < result[j].Listen
 	})
 
 	return result
 }
+
+// validateListeners checks that no two groups bind overlapping addresses, e.g.
+// the same port on all interfaces and on the loopback interface.
+func (g PortGroups) validateListeners(defaultListen string) error {
+	var errs []error
+
+	for i, group := range g {
+		for _, other := range g[i+1:] {
+			if group.Port == other.Port && listenOverlaps(group.Listen, other.Listen) {
+				errs = append(errs, &ValidationError{fmt.Sprintf(
+					"mappings listen on overlapping addresses %s and %s",
+					group.Address(defaultListen), other.Address(defaultListen),
+				)})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
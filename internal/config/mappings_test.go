@@ -9,6 +9,7 @@ import (
 	"github.com/evg4b/uncors/pkg/urlt"
 	"github.com/evg4b/uncors/testing/hosts"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMappings(t *testing.T) {
@@ -68,9 +69,9 @@ func TestMappings(t *testing.T) {
 				{
 					From: hosts.Localhost.HTTP(),
 					To:   hosts.Github.HTTPS(),
-					Cache: config.CacheGlobs{
-						"/static",
-						"/static2",
+					Cache: config.CacheRules{
+						{Path: "/static"},
+						{Path: "/static2"},
 					},
 				},
 			},
@@ -101,8 +102,8 @@ func TestMappings(t *testing.T) {
 							Matcher: config.RequestMatcher{
 								Path:   "/demo",
 								Method: http.MethodGet,
-								Queries: map[string]string{
-									"param1": "value1",
+								Queries: config.ValueMatchers{
+									"param1": {Equals: "value1"},
 								},
 							},
 							Response: config.Response{
@@ -114,8 +115,8 @@ func TestMappings(t *testing.T) {
 							Matcher: config.RequestMatcher{
 								Path:   "/healthcheck",
 								Method: http.MethodGet,
-								Headers: map[string]string{
-									"param1": "value1",
+								Headers: config.ValueMatchers{
+									"param1": {Equals: "value1"},
 								},
 							},
 							Response: config.Response{
@@ -270,7 +271,7 @@ func TestMappings_GroupByPort(t *testing.T) {
 
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
-			groups := testCase.mappings.GroupByPort()
+			groups := testCase.mappings.GroupByPort("")
 
 			assert.Len(t, groups, len(testCase.expected), "number of port groups should match")
 
@@ -282,10 +283,37 @@ func TestMappings_GroupByPort(t *testing.T) {
 		})
 	}
 
+	t.Run("mappings with different listen addresses are grouped separately", func(t *testing.T) {
+		mappings := config.Mappings{
+			{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS()},
+			{From: hosts.Localhost1.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: "0.0.0.0"},
+		}
+
+		groups := mappings.GroupByPort("")
+
+		require.Len(t, groups, 2)
+		assert.Equal(t, "0.0.0.0", groups[0].Listen)
+		assert.Equal(t, config.DefaultListen, groups[1].Listen)
+	})
+
+	t.Run("mappings with the effective listen address share a group", func(t *testing.T) {
+		mappings := config.Mappings{
+			{From: hosts.Localhost.HTTPPort(3000), To: hosts.Github.HTTPS()},
+			{From: hosts.Localhost1.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: "::1"},
+			{From: hosts.Localhost2.HTTPPort(3000), To: hosts.Github.HTTPS(), Listen: "[::1]"},
+		}
+
+		groups := mappings.GroupByPort("::1")
+
+		require.Len(t, groups, 1)
+		assert.Equal(t, "::1", groups[0].Listen)
+		assert.Len(t, groups[0].Mappings, 3)
+	})
+
 	t.Run("empty mappings", func(t *testing.T) {
 		var mappings config.Mappings
 
-		groups := mappings.GroupByPort()
+		groups := mappings.GroupByPort("")
 		assert.Empty(t, groups)
 	})
 
@@ -295,7 +323,7 @@ func TestMappings_GroupByPort(t *testing.T) {
 		}
 
 		assert.Panics(t, func() {
-			_ = mappings.GroupByPort()
+			_ = mappings.GroupByPort("")
 		})
 	})
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/samber/lo"
 	"github.com/spf13/afero"
@@ -11,12 +13,18 @@ import (
 type Mock struct {
 	Matcher  RequestMatcher `yaml:",inline"`
 	Response Response       `yaml:"response"`
+	Sequence MockSequence   `yaml:"sequence"`
+	Scenario MockScenario   `yaml:"scenario"`
+	Priority int            `yaml:"priority"`
 }
 
 func (m *Mock) Clone() Mock {
 	return Mock{
 		Matcher:  m.Matcher.Clone(),
 		Response: m.Response.Clone(),
+		Sequence: m.Sequence.Clone(),
+		Scenario: m.Scenario.Clone(),
+		Priority: m.Priority,
 	}
 }
 
@@ -26,7 +34,18 @@ func (m *Mock) String() string {
 		method = m.Matcher.Method
 	}
 
-	return fmt.Sprintf("[%s %d] %s", method, m.Response.Code, m.Matcher.Path)
+	codes := strconv.Itoa(m.Response.Code)
+	if m.Sequence.IsDefined() {
+		codes = strings.Join(lo.Map(m.Sequence.Responses, func(response Response, _ int) string {
+			return strconv.Itoa(response.Code)
+		}), ",")
+	}
+
+	if m.Scenario.IsDefined() {
+		return fmt.Sprintf("[%s %s] %s (scenario %s)", method, codes, m.Matcher.Path, m.Scenario.String())
+	}
+
+	return fmt.Sprintf("[%s %s] %s", method, codes, m.Matcher.Path)
 }
 
 type Mocks []Mock
@@ -42,8 +61,27 @@ func (m Mocks) Clone() Mocks {
 }
 
 func (m *Mock) Validate(field string, fs afero.Fs) error {
-	return errors.Join(
+	errs := []error{
 		m.Matcher.Validate(field),
-		m.Response.Validate(joinPath(field, "response"), fs),
-	)
+		m.Scenario.Validate(joinPath(field, "scenario")),
+	}
+
+	switch {
+	case m.Sequence.IsDefined() && m.hasResponse():
+		errs = append(errs, &ValidationError{fmt.Sprintf(
+			"only one of %s or %s must be set",
+			joinPath(field, "response"),
+			joinPath(field, "sequence"),
+		)})
+	case m.Sequence.IsDefined():
+		errs = append(errs, m.Sequence.Validate(joinPath(field, "sequence"), fs))
+	default:
+		errs = append(errs, m.Response.Validate(joinPath(field, "response"), fs))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Mock) hasResponse() bool {
+	return m.Response.Code != 0 || m.Response.IsRaw() || m.Response.IsFile()
 }
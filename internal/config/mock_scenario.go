@@ -0,0 +1,48 @@
+package config
+
+import "fmt"
+
+// DefaultScenarioState is the state every scenario starts in.
+const DefaultScenarioState = "started"
+
+// MockScenario ties a mock to a named state machine. The mock matches only
+// while the scenario is in State (any state when empty) and moves the
+// scenario to NewState after responding.
+type MockScenario struct {
+	Name     string `yaml:"name"`
+	State    string `yaml:"state"`
+	NewState string `yaml:"new-state"`
+}
+
+func (s *MockScenario) Clone() MockScenario {
+	return MockScenario{
+		Name:     s.Name,
+		State:    s.State,
+		NewState: s.NewState,
+	}
+}
+
+func (s *MockScenario) IsDefined() bool {
+	return s.Name != ""
+}
+
+func (s *MockScenario) String() string {
+	state := s.State
+	if state == "" {
+		state = "*"
+	}
+
+	if s.NewState == "" {
+		return fmt.Sprintf("%s: %s", s.Name, state)
+	}
+
+	return fmt.Sprintf("%s: %s -> %s", s.Name, state, s.NewState)
+}
+
+func (s *MockScenario) Validate(field string) error {
+	if s.Name == "" && (s.State != "" || s.NewState != "") {
+		return &ValidationError{fmt.Sprintf("%s must be set", joinPath(field, "name"))}
+	}
+
+	return nil
+}
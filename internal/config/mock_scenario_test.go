@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockScenarioString(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario config.MockScenario
+		expected string
+	}{
+		{
+			name:     "any state",
+			scenario: config.MockScenario{Name: "order"},
+			expected: "order: *",
+		},
+		{
+			name:     "required state",
+			scenario: config.MockScenario{Name: "order", State: "pending"},
+			expected: "order: pending",
+		},
+		{
+			name:     "transition",
+			scenario: config.MockScenario{Name: "order", NewState: "shipped"},
+			expected: "order: * -> shipped",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.scenario.String())
+		})
+	}
+}
+
+func TestMockScenarioValidate(t *testing.T) {
+	t.Run("valid scenarios", func(t *testing.T) {
+		scenarios := []config.MockScenario{
+			{},
+			{Name: "order"},
+			{Name: "order", State: "pending", NewState: "shipped"},
+		}
+
+		for _, scenario := range scenarios {
+			assert.NoError(t, scenario.Validate("scenario"))
+		}
+	})
+
+	t.Run("state without name", func(t *testing.T) {
+		scenario := config.MockScenario{NewState: "shipped"}
+
+		require.EqualError(t, scenario.Validate("scenario"), "scenario.name must be set")
+	})
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// MockSequence is a list of responses returned one after another. Once the
+// last response is reached it is repeated, unless Loop starts the list over.
+type MockSequence struct {
+	Responses []Response `yaml:"responses"`
+	Loop      bool       `yaml:"loop"`
+}
+
+func (s *MockSequence) Clone() MockSequence {
+	var responses []Response
+	if s.Responses != nil {
+		responses = lo.Map(s.Responses, func(item Response, _ int) Response {
+			return item.Clone()
+		})
+	}
+
+	return MockSequence{
+		Responses: responses,
+		Loop:      s.Loop,
+	}
+}
+
+func (s *MockSequence) IsDefined() bool {
+	return len(s.Responses) > 0
+}
+
+// UnmarshalYAML accepts a plain list of responses as a shorthand for a
+// sequence without looping.
+func (s *MockSequence) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&s.Responses)
+	}
+
+	type mockSequenceAlias MockSequence
+
+	var alias mockSequenceAlias
+
+	err := value.Decode(&alias)
+	if err != nil {
+		return err
+	}
+
+	*s = MockSequence(alias)
+
+	return nil
+}
+
+func (s *MockSequence) Validate(field string, fs afero.Fs) error {
+	errs := make([]error, 0, len(s.Responses))
+
+	for i, response := range s.Responses {
+		errs = append(errs, response.Validate(joinPath(field, "responses", index(i)), fs))
+	}
+
+	return errors.Join(errs...)
+}
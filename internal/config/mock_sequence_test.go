@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMockSequenceUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.MockSequence
+	}{
+		{
+			name: "list shorthand",
+			input: `
+- code: 202
+  raw: pending
+- code: 200
+  raw: done
+`,
+			expected: config.MockSequence{Responses: []config.Response{
+				{Code: http.StatusAccepted, Raw: "pending"},
+				{Code: http.StatusOK, Raw: "done"},
+			}},
+		},
+		{
+			name: "full form",
+			input: `
+loop: true
+responses:
+  - code: 202
+    raw: pending
+`,
+			expected: config.MockSequence{
+				Responses: []config.Response{{Code: http.StatusAccepted, Raw: "pending"}},
+				Loop:      true,
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual config.MockSequence
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestMockSequenceClone(t *testing.T) {
+	sequence := config.MockSequence{
+		Responses: []config.Response{
+			{Code: http.StatusAccepted, Raw: "pending", Headers: map[string]string{"X-State": "pending"}},
+			{Code: http.StatusOK, Raw: "done"},
+		},
+		Loop: true,
+	}
+
+	cloned := sequence.Clone()
+
+	assert.Equal(t, sequence, cloned)
+	assert.NotSame(t, &sequence.Responses[0], &cloned.Responses[0])
+	assert.NotSame(t, &sequence.Responses[0].Headers, &cloned.Responses[0].Headers)
+}
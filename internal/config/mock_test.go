@@ -16,19 +16,20 @@ func TestMockClone(t *testing.T) {
 		Matcher: config.RequestMatcher{
 			Path:   "/constants",
 			Method: http.MethodGet,
-			Queries: map[string]string{
-				"page": "10",
-				"size": "50",
+			Queries: config.ValueMatchers{
+				"page": {Equals: "10"},
+				"size": {Equals: "50"},
 			},
-			Headers: map[string]string{
-				headers.ContentType:  "plain/text",
-				headers.CacheControl: "none",
+			Headers: config.ValueMatchers{
+				headers.ContentType:  {Equals: "plain/text"},
+				headers.CacheControl: {Equals: "none"},
 			},
 		},
 		Response: config.Response{
 			Code: http.StatusOK,
 			Raw:  `{ "status": "ok" }`,
 		},
+		Priority: 10,
 	}
 
 	clonedMock := mock.Clone()
@@ -83,3 +84,90 @@ func TestMockValidator(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestMockValidateResponseSources(t *testing.T) {
+	response := config.Response{Code: http.StatusOK, Raw: "ok"}
+	sequence := config.MockSequence{Responses: []config.Response{response, {Code: http.StatusOK}}}
+
+	tests := []struct {
+		name  string
+		mock  config.Mock
+		error string
+	}{
+		{
+			name: "response and sequence",
+			mock: config.Mock{
+				Matcher:  config.RequestMatcher{Path: "/api"},
+				Response: response,
+				Sequence: config.MockSequence{Responses: []config.Response{response}},
+			},
+			error: "only one of mock.response or mock.sequence must be set",
+		},
+		{
+			name: "invalid sequence response",
+			mock: config.Mock{
+				Matcher:  config.RequestMatcher{Path: "/api"},
+				Sequence: sequence,
+			},
+			error: "mock.sequence.responses[1].raw or mock.sequence.responses[1].file must be set",
+		},
+		{
+			name: "scenario state without name",
+			mock: config.Mock{
+				Matcher:  config.RequestMatcher{Path: "/api"},
+				Response: response,
+				Scenario: config.MockScenario{State: "shipped"},
+			},
+			error: "mock.scenario.name must be set",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.EqualError(t, testCase.mock.Validate("mock", afero.NewMemMapFs()), testCase.error)
+		})
+	}
+}
+
+func TestMockString(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     config.Mock
+		expected string
+	}{
+		{
+			name: "single response",
+			mock: config.Mock{
+				Matcher:  config.RequestMatcher{Path: "/api", Method: http.MethodGet},
+				Response: config.Response{Code: http.StatusOK},
+			},
+			expected: "[GET 200] /api",
+		},
+		{
+			name: "sequence",
+			mock: config.Mock{
+				Matcher: config.RequestMatcher{Path: "/api"},
+				Sequence: config.MockSequence{Responses: []config.Response{
+					{Code: http.StatusAccepted},
+					{Code: http.StatusOK},
+				}},
+			},
+			expected: "[* 202,200] /api",
+		},
+		{
+			name: "scenario",
+			mock: config.Mock{
+				Matcher:  config.RequestMatcher{Path: "/api/ship", Method: http.MethodPost},
+				Response: config.Response{Code: http.StatusNoContent},
+				Scenario: config.MockScenario{Name: "order", State: "pending", NewState: "shipped"},
+			},
+			expected: "[POST 204] /api/ship (scenario order: pending -> shipped)",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.mock.String())
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RecordModeRecord = "record"
+	RecordModeReplay = "replay"
+
+	// RecordedMocksFile is the file in the record directory that holds the
+	// generated mock definitions.
+	RecordedMocksFile = "mocks.yaml"
+)
+
+// RecordConfig turns proxied responses into mock definitions. In record mode
+// responses for requests matching Paths are saved to Dir; in replay mode the
+// saved mocks are served instead of proxying.
+type RecordConfig struct {
+	Dir   string   `yaml:"dir"`
+	Mode  string   `yaml:"mode"`
+	Paths []string `yaml:"paths"`
+}
+
+func (r *RecordConfig) Clone() RecordConfig {
+	return RecordConfig{
+		Dir:   r.Dir,
+		Mode:  r.Mode,
+		Paths: slices.Clone(r.Paths),
+	}
+}
+
+// UnmarshalYAML accepts a directory as a shorthand for recording all paths.
+func (r *RecordConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Dir = value.Value
+
+		return nil
+	}
+
+	type recordConfigAlias RecordConfig
+
+	return value.Decode((*recordConfigAlias)(r))
+}
+
+func (r *RecordConfig) Enabled() bool {
+	return r.Dir != ""
+}
+
+func (r *RecordConfig) Recording() bool {
+	return r.Enabled() && (r.Mode == "" || r.Mode == RecordModeRecord)
+}
+
+func (r *RecordConfig) Replaying() bool {
+	return r.Enabled() && r.Mode == RecordModeReplay
+}
+
+// MocksFile returns the path of the generated mock definitions.
+func (r *RecordConfig) MocksFile() string {
+	return filepath.Join(r.Dir, RecordedMocksFile)
+}
+
+// LoadMocks reads the mocks saved in record mode.
+func (r *RecordConfig) LoadMocks(fs afero.Fs) (Mocks, error) {
+	file, err := fs.Open(r.MocksFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded mocks '%s': %w", r.MocksFile(), err)
+	}
+
+	defer file.Close()
+
+	var recorded struct {
+		Mocks Mocks `yaml:"mocks"`
+	}
+
+	err = yaml.NewDecoder(file).Decode(&recorded)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read recorded mocks '%s': %w", r.MocksFile(), err)
+	}
+
+	return recorded.Mocks, nil
+}
+
+func (r *RecordConfig) Validate(field string) error {
+	if !r.Enabled() {
+		if r.Mode != "" || len(r.Paths) > 0 {
+			return &ValidationError{fmt.Sprintf("%s must be set", joinPath(field, "dir"))}
+		}
+
+		return nil
+	}
+
+	var errs []error
+
+	if r.Mode != "" && r.Mode != RecordModeRecord && r.Mode != RecordModeReplay {
+		errs = append(errs, &ValidationError{fmt.Sprintf(
+			"%s must be %s or %s", joinPath(field, "mode"), RecordModeRecord, RecordModeReplay,
+		)})
+	}
+
+	for i, path := range r.Paths {
+		errs = append(errs, ValidateGlobPattern(joinPath(field, "paths", index(i)), path))
+	}
+
+	return errors.Join(errs...)
+}
+
+// replayRecordedMocks adds the recorded mocks to mappings in replay mode.
+// They are appended after the configured mocks.
+func replayRecordedMocks(fs afero.Fs, mappings Mappings) error {
+	var errs []error
+
+	for i := range mappings {
+		mapping := &mappings[i]
+		if !mapping.Record.Replaying() {
+			continue
+		}
+
+		mocks, err := mapping.Record.LoadMocks(fs)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		mapping.Mocks = append(mapping.Mocks, mocks...)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/evg4b/uncors/testing/testutils/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRecordConfigModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    config.RecordConfig
+		recording bool
+		replaying bool
+	}{
+		{name: "disabled", config: config.RecordConfig{}},
+		{name: "default mode records", config: config.RecordConfig{Dir: "rec"}, recording: true},
+		{name: "record mode", config: config.RecordConfig{Dir: "rec", Mode: config.RecordModeRecord}, recording: true},
+		{name: "replay mode", config: config.RecordConfig{Dir: "rec", Mode: config.RecordModeReplay}, replaying: true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.recording, testCase.config.Recording())
+			assert.Equal(t, testCase.replaying, testCase.config.Replaying())
+		})
+	}
+}
+
+func TestRecordConfigUnmarshalYAML(t *testing.T) {
+	t.Run("string shorthand sets dir", func(t *testing.T) {
+		var actual config.RecordConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(`./recordings`), &actual))
+		assert.Equal(t, config.RecordConfig{Dir: "./recordings"}, actual)
+	})
+
+	t.Run("map form decoded normally", func(t *testing.T) {
+		const input = `
+dir: ./recordings
+mode: replay
+paths: [/api/**]
+`
+
+		var actual config.RecordConfig
+
+		require.NoError(t, yaml.Unmarshal([]byte(input), &actual))
+		assert.Equal(t, config.RecordConfig{
+			Dir:   "./recordings",
+			Mode:  config.RecordModeReplay,
+			Paths: []string{"/api/**"},
+		}, actual)
+	})
+}
+
+func TestRecordConfigClone(t *testing.T) {
+	original := config.RecordConfig{Dir: "rec", Mode: config.RecordModeRecord, Paths: []string{"/api/**"}}
+
+	cloned := original.Clone()
+
+	assert.Equal(t, original, cloned)
+	cloned.Paths[0] = "/other/**"
+	assert.Equal(t, "/api/**", original.Paths[0])
+}
+
+func TestRecordConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.RecordConfig
+		errors []string
+	}{
+		{name: "disabled", config: config.RecordConfig{}},
+		{name: "valid", config: config.RecordConfig{Dir: "rec", Mode: config.RecordModeReplay, Paths: []string{"/api/**"}}},
+		{
+			name:   "options without dir",
+			config: config.RecordConfig{Mode: config.RecordModeReplay},
+			errors: []string{"record.dir must be set"},
+		},
+		{
+			name:   "unknown mode",
+			config: config.RecordConfig{Dir: "rec", Mode: "playback"},
+			errors: []string{"record.mode must be record or replay"},
+		},
+		{
+			name:   "invalid glob",
+			config: config.RecordConfig{Dir: "rec", Paths: []string{"/api/["}},
+			errors: []string{"record.paths[0] is not a valid glob pattern"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.config.Validate("record")
+
+			if len(testCase.errors) == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+
+			for _, message := range testCase.errors {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationReplaysRecordedMocks(t *testing.T) {
+	const configFile = "/config.yaml"
+
+	configContent := `
+mappings:
+  - from: http://localhost:3000
+    to: https://api.example.com
+    record:
+      dir: /recordings
+      mode: replay
+    mocks:
+      - path: /api/health
+        response:
+          code: 200
+          raw: ok
+`
+
+	t.Run("appends recorded mocks", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			configFile: configContent,
+			"/recordings/mocks.yaml": `mocks:
+  - path: /api/users
+    method: GET
+    response:
+      code: 200
+      file: /recordings/responses/get-api-users.json
+`,
+			"/recordings/responses/get-api-users.json": `[]`,
+		})
+
+		actual, _, err := config.LoadConfiguration(fs, []string{params.Config, configFile})
+		require.NoError(t, err)
+
+		mocks := actual.Mappings[0].Mocks
+		require.Len(t, mocks, 2)
+		assert.Equal(t, "/api/health", mocks[0].Matcher.Path)
+		assert.Equal(t, "/api/users", mocks[1].Matcher.Path)
+		assert.Equal(t, "/recordings/responses/get-api-users.json", mocks[1].Response.File)
+	})
+
+	t.Run("fails without recordings", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{configFile: configContent})
+
+		_, _, err := config.LoadConfiguration(fs, []string{params.Config, configFile})
+
+		require.ErrorContains(t, err, "failed to read recorded mocks")
+	})
+}
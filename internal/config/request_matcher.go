@@ -2,15 +2,18 @@ package config
 
 import (
 	"errors"
+	"maps"
+	"slices"
 
 	"github.com/evg4b/uncors/internal/helpers"
 )
 
 type RequestMatcher struct {
-	Path    string            `yaml:"path"`
-	Method  string            `yaml:"method"`
-	Queries map[string]string `yaml:"queries"`
-	Headers map[string]string `yaml:"headers"`
+	Path    string        `yaml:"path"`
+	Method  string        `yaml:"method"`
+	Queries ValueMatchers `yaml:"queries"`
+	Headers ValueMatchers `yaml:"headers"`
+	Body    BodyMatchers  `yaml:"body"`
 }
 
 func (r *RequestMatcher) Clone() RequestMatcher {
@@ -19,16 +22,31 @@ func (r *RequestMatcher) Clone() RequestMatcher {
 		Method:  r.Method,
 		Queries: helpers.CloneMap(r.Queries),
 		Headers: helpers.CloneMap(r.Headers),
+		Body:    r.Body.Clone(),
 	}
 }
 
 func (r *RequestMatcher) IsPathOnly() bool {
-	return r.Method == "" && len(r.Queries) == 0 && len(r.Headers) == 0
+	return r.Method == "" && len(r.Queries) == 0 && len(r.Headers) == 0 && len(r.Body) == 0
 }
 
 func (r *RequestMatcher) Validate(field string) error {
-	return errors.Join(
+	errs := []error{
 		ValidatePath(joinPath(field, "path"), r.Path, false),
 		ValidateMethod(joinPath(field, "method"), r.Method, true),
-	)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(r.Queries)) {
+		errs = append(errs, r.Queries[key].Validate(joinPath(field, "queries", key)))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(r.Headers)) {
+		errs = append(errs, r.Headers[key].Validate(joinPath(field, "headers", key)))
+	}
+
+	for i, matcher := range r.Body {
+		errs = append(errs, matcher.Validate(joinPath(field, "body", index(i))))
+	}
+
+	return errors.Join(errs...)
 }
@@ -23,7 +23,7 @@ func TestRequestMatcherIsPathOnly(t *testing.T) {
 	t.Run("returns false when queries are set", func(t *testing.T) {
 		m := &config.RequestMatcher{
 			Path:    "/api",
-			Queries: map[string]string{"key": "value"},
+			Queries: config.ValueMatchers{"key": {Equals: "value"}},
 		}
 		assert.False(t, m.IsPathOnly())
 	})
@@ -31,7 +31,15 @@ func TestRequestMatcherIsPathOnly(t *testing.T) {
 	t.Run("returns false when headers are set", func(t *testing.T) {
 		m := &config.RequestMatcher{
 			Path:    "/api",
-			Headers: map[string]string{"X-Token": "abc"},
+			Headers: config.ValueMatchers{"X-Token": {Equals: "abc"}},
+		}
+		assert.False(t, m.IsPathOnly())
+	})
+
+	t.Run("returns false when body matchers are set", func(t *testing.T) {
+		m := &config.RequestMatcher{
+			Path: "/api",
+			Body: config.BodyMatchers{{Field: "username", ValueMatcher: config.ValueMatcher{Equals: "locked"}}},
 		}
 		assert.False(t, m.IsPathOnly())
 	})
@@ -49,13 +57,13 @@ func TestRequestMatcherValidator(t *testing.T) {
 		err := (&config.RequestMatcher{
 			Path:   requestMatcherTestPath,
 			Method: "GET",
-			Queries: map[string]string{
-				"param1": "value1",
-				"param2": "value2",
+			Queries: config.ValueMatchers{
+				"param1": {Equals: "value1"},
+				"param2": {Equals: "value2"},
 			},
-			Headers: map[string]string{
-				headers.ContentType: "application/json",
-				headers.Accept:      "application/json",
+			Headers: config.ValueMatchers{
+				headers.ContentType: {Equals: "application/json"},
+				headers.Accept:      {Equals: "application/json"},
 			},
 		}).Validate("test")
 		assert.NoError(t, err)
@@ -77,6 +85,18 @@ func TestRequestMatcherValidator(t *testing.T) {
 		assert.Contains(t, err.Error(), "method must be one of")
 	})
 
+	t.Run("should register errors for value matchers", func(t *testing.T) {
+		err := (&config.RequestMatcher{
+			Path:    requestMatcherTestPath,
+			Queries: config.ValueMatchers{"page": {Regex: "[0-9"}},
+			Headers: config.ValueMatchers{"X-Debug": {Absent: true, Contains: "1"}},
+			Body:    config.BodyMatchers{{Field: ".name"}},
+		}).Validate("test")
+		require.EqualError(t, err, "test.queries.page.regex is not a valid regular expression\n"+
+			"test.headers.X-Debug.absent can not be combined with other conditions\n"+
+			"test.body[0].field must be a field name or dotted path")
+	})
+
 	t.Run("should register multiple validation errors", func(t *testing.T) {
 		err := (&config.RequestMatcher{Path: "", Method: "INVALID"}).Validate("test")
 		require.Error(t, err)
@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/evg4b/uncors/internal/helpers"
@@ -10,20 +12,22 @@ import (
 )
 
 type Response struct {
-	Code    int               `yaml:"code"`
-	Headers map[string]string `yaml:"headers"`
-	Delay   time.Duration     `yaml:"delay"`
-	Raw     string            `yaml:"raw"`
-	File    string            `yaml:"file"`
+	Code     int               `yaml:"code"`
+	Headers  map[string]string `yaml:"headers"`
+	Delay    time.Duration     `yaml:"delay"`
+	Raw      string            `yaml:"raw"`
+	File     string            `yaml:"file"`
+	Template bool              `yaml:"template"`
 }
 
 func (r *Response) Clone() Response {
 	return Response{
-		Code:    r.Code,
-		Headers: helpers.CloneMap(r.Headers),
-		Raw:     r.Raw,
-		File:    r.File,
-		Delay:   r.Delay,
+		Code:     r.Code,
+		Headers:  helpers.CloneMap(r.Headers),
+		Raw:      r.Raw,
+		File:     r.File,
+		Delay:    r.Delay,
+		Template: r.Template,
 	}
 }
 
@@ -58,5 +62,26 @@ func (r *Response) Validate(field string, fs afero.Fs) error {
 		errs = append(errs, ValidateFile(joinPath(field, "file"), r.File, fs))
 	}
 
+	if r.Template {
+		errs = append(errs, r.validateTemplates(field))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTemplates checks the syntax of the raw content and header values.
+// File templates are parsed when the mock is served, since the file may
+// change while uncors is running.
+func (r *Response) validateTemplates(field string) error {
+	var errs []error
+
+	if r.Raw != "" {
+		errs = append(errs, ValidateTemplate(joinPath(field, "raw"), r.Raw))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(r.Headers)) {
+		errs = append(errs, ValidateTemplate(joinPath(field, "headers", key), r.Headers[key]))
+	}
+
 	return errors.Join(errs...)
 }
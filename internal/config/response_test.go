@@ -23,6 +23,7 @@ headers:
 delay: 200ms
 raw: '{"ok":true}'
 file: ./body.json
+template: true
 `
 
 		var actual config.Response
@@ -34,9 +35,10 @@ file: ./body.json
 				"Content-Type": "application/json",
 				"X-Custom":     "value",
 			},
-			Delay: 200 * time.Millisecond,
-			Raw:   `{"ok":true}`,
-			File:  "./body.json",
+			Delay:    200 * time.Millisecond,
+			Raw:      `{"ok":true}`,
+			File:     "./body.json",
+			Template: true,
 		}, actual)
 	})
 
@@ -74,9 +76,10 @@ func TestResponseClone(t *testing.T) {
 			headers.ContentType:  "plain/text",
 			headers.CacheControl: "none",
 		},
-		Raw:   "this is plain text",
-		File:  "~/projects/uncors/response/demo.json",
-		Delay: time.Hour,
+		Raw:      "this is plain text",
+		File:     "~/projects/uncors/response/demo.json",
+		Delay:    time.Hour,
+		Template: true,
 	}
 
 	clonedResponse := response.Clone()
@@ -139,6 +142,16 @@ func TestResponseValidator(t *testing.T) {
 			{name: "with file", value: config.Response{Code: 200, File: file, Delay: 3 * time.Second}},
 			{name: "with raw", value: config.Response{Code: 200, Raw: `{ "test": "test" }`, Delay: 3 * time.Second}},
 			{name: "without delay", value: config.Response{Code: 200, Raw: `{ "test": "test" }`}},
+			{
+				name: "with template",
+				value: config.Response{
+					Code:     200,
+					Raw:      `{ "id": "{{ .Params.id }}", "uuid": "{{ uuid }}" }`,
+					Headers:  map[string]string{"X-Request": "{{ .Method }}"},
+					Template: true,
+				},
+			},
+			{name: "with template syntax but disabled", value: config.Response{Code: 200, Raw: "{{ unknown }}"}},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
@@ -178,6 +191,21 @@ func TestResponseValidator(t *testing.T) {
 				value: config.Response{Code: 200, File: file, Raw: "test", Delay: 3 * time.Second},
 				error: "only one of test.raw or test.file must be set",
 			},
+			{
+				name:  "raw template",
+				value: config.Response{Code: 200, Raw: "{{ unknown }}", Template: true},
+				error: "test.raw is not a valid template: template: test.raw:1: function \"unknown\" not defined",
+			},
+			{
+				name: "header template",
+				value: config.Response{
+					Code:     200,
+					Raw:      "ok",
+					Headers:  map[string]string{"X-Id": "{{ .Params.id"},
+					Template: true,
+				},
+				error: "test.headers.X-Id is not a valid template: template: test.headers.X-Id:1: unclosed action",
+			},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
@@ -2,21 +2,27 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"slices"
 
 	"github.com/evg4b/uncors/pkg/urlt"
 )
 
 type RewritingOption struct {
-	From string    `yaml:"from"`
-	To   string    `yaml:"to"`
-	Host urlt.Host `yaml:"host"`
+	From     string    `yaml:"from"`
+	To       string    `yaml:"to"`
+	Host     urlt.Host `yaml:"host"`
+	Priority int       `yaml:"priority"`
 }
 
 func (r RewritingOption) Clone() RewritingOption {
 	return r
 }
 
+func (r RewritingOption) String() string {
+	return fmt.Sprintf("%s => %s", r.From, r.To)
+}
+
 type RewriteOptions []RewritingOption
 
 func (r RewriteOptions) Clone() RewriteOptions {
@@ -34,9 +34,10 @@ func TestRewritingOptionClone(t *testing.T) {
 		{
 			name: "structure with all fields",
 			expected: config.RewritingOption{
-				From: "from",
-				To:   "to",
-				Host: hosts.Parse("host"),
+				From:     "from",
+				To:       "to",
+				Host:     hosts.Parse("host"),
+				Priority: 1,
 			},
 		},
 	}
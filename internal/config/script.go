@@ -9,16 +9,20 @@ import (
 )
 
 type Script struct {
-	Matcher RequestMatcher `yaml:",inline"`
-	Script  string         `yaml:"script"`
-	File    string         `yaml:"file"`
+	Matcher  RequestMatcher `yaml:",inline"`
+	Script   string         `yaml:"script"`
+	File     string         `yaml:"file"`
+	Priority int            `yaml:"priority"`
+	Sandbox  ScriptSandbox  `yaml:",inline"`
 }
 
 func (s *Script) Clone() Script {
 	return Script{
-		Matcher: s.Matcher.Clone(),
-		Script:  s.Script,
-		File:    s.File,
+		Matcher:  s.Matcher.Clone(),
+		Script:   s.Script,
+		File:     s.File,
+		Priority: s.Priority,
+		Sandbox:  s.Sandbox.Clone(),
 	}
 }
 
@@ -49,30 +53,40 @@ func (s Scripts) Clone() Scripts {
 }
 
 func (s *Script) Validate(field string, fs afero.Fs) error {
-	var errs []error
-
-	errs = append(errs, s.Matcher.Validate(field))
+	return errors.Join(
+		s.Matcher.Validate(field),
+		validateScriptSource(field, s.Script, s.File, fs),
+		s.Sandbox.Validate(field),
+	)
+}
 
+// validateScriptSource checks that exactly one of the inline script and the
+// script file is set and that the file exists.
+func validateScriptSource(field, script, file string, fs afero.Fs) error {
 	switch {
-	case s.Script == "" && s.File == "":
+	case script == "" && file == "":
 		scriptField := joinPath(field, "script")
 		fileField := joinPath(field, "file")
 
 		const neitherMsg = ": either 'script' or 'file' must be provided"
 
-		errs = append(errs, &ValidationError{scriptField + neitherMsg})
-		errs = append(errs, &ValidationError{fileField + neitherMsg})
-	case s.Script != "" && s.File != "":
+		return errors.Join(
+			&ValidationError{scriptField + neitherMsg},
+			&ValidationError{fileField + neitherMsg},
+		)
+	case script != "" && file != "":
 		scriptField := joinPath(field, "script")
 		fileField := joinPath(field, "file")
 
 		const bothMsg = ": only one of 'script' or 'file' can be provided"
 
-		errs = append(errs, &ValidationError{scriptField + bothMsg})
-		errs = append(errs, &ValidationError{fileField + bothMsg})
-	case s.File != "":
-		errs = append(errs, ValidateFile(joinPath(field, "file"), s.File, fs))
+		return errors.Join(
+			&ValidationError{scriptField + bothMsg},
+			&ValidationError{fileField + bothMsg},
+		)
+	case file != "":
+		return ValidateFile(joinPath(field, "file"), file, fs)
 	}
 
-	return errors.Join(errs...)
+	return nil
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+)
+
+// ScriptMiddleware is a Lua script that runs around the proxy for matching
+// requests. The script defines on_request and/or on_response functions.
+type ScriptMiddleware struct {
+	Matcher RequestMatcher `yaml:",inline"`
+	Script  string         `yaml:"script"`
+	File    string         `yaml:"file"`
+	Sandbox ScriptSandbox  `yaml:",inline"`
+}
+
+func (s *ScriptMiddleware) Clone() ScriptMiddleware {
+	return ScriptMiddleware{
+		Matcher: s.Matcher.Clone(),
+		Script:  s.Script,
+		File:    s.File,
+		Sandbox: s.Sandbox.Clone(),
+	}
+}
+
+func (s *ScriptMiddleware) String() string {
+	method := "*"
+	if s.Matcher.Method != "" {
+		method = s.Matcher.Method
+	}
+
+	path := "*"
+	if s.Matcher.Path != "" {
+		path = s.Matcher.Path
+	}
+
+	scriptType := "inline"
+	if s.File != "" {
+		scriptType = "file: " + s.File
+	}
+
+	return fmt.Sprintf("[%s script-middleware:%s] %s", method, scriptType, path)
+}
+
+func (s *ScriptMiddleware) Validate(field string, fs afero.Fs) error {
+	// The path is optional: a middleware without a path applies to every request.
+	matcher := s.Matcher
+	if matcher.Path == "" {
+		matcher.Path = "/"
+	}
+
+	return errors.Join(
+		matcher.Validate(field),
+		validateScriptSource(field, s.Script, s.File, fs),
+		s.Sandbox.Validate(field),
+	)
+}
+
+type ScriptMiddlewares []ScriptMiddleware
+
+func (s ScriptMiddlewares) Clone() ScriptMiddlewares {
+	if s == nil {
+		return nil
+	}
+
+	return lo.Map(s, func(item ScriptMiddleware, _ int) ScriptMiddleware {
+		return item.Clone()
+	})
+}
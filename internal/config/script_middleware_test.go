@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptMiddleware_Clone(t *testing.T) {
+	original := config.ScriptMiddleware{
+		Matcher: config.RequestMatcher{
+			Path:    "/api/{id}",
+			Method:  "GET",
+			Headers: config.ValueMatchers{"X-Feature": {Equals: "on"}},
+		},
+		Script: "function on_request(request) end",
+		File:   "/scripts/middleware.lua",
+	}
+
+	cloned := original.Clone()
+
+	assert.Equal(t, original, cloned)
+
+	cloned.Matcher.Headers["X-Feature"] = config.ValueMatcher{Equals: "off"}
+	assert.NotEqual(t, original.Matcher.Headers["X-Feature"], cloned.Matcher.Headers["X-Feature"])
+}
+
+func TestScriptMiddlewares_Clone(t *testing.T) {
+	t.Run("non-nil middlewares", func(t *testing.T) {
+		original := config.ScriptMiddlewares{
+			{Matcher: config.RequestMatcher{Path: "/one"}, Script: "script1"},
+			{File: "/scripts/two.lua"},
+		}
+
+		cloned := original.Clone()
+
+		assert.Equal(t, original, cloned)
+
+		cloned[0].Matcher.Path = "/modified"
+		assert.NotEqual(t, original[0].Matcher.Path, cloned[0].Matcher.Path)
+	})
+
+	t.Run("nil middlewares", func(t *testing.T) {
+		var original config.ScriptMiddlewares
+
+		assert.Nil(t, original.Clone())
+	})
+}
+
+func TestScriptMiddleware_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware config.ScriptMiddleware
+		expected   string
+	}{
+		{
+			name: "inline script with method and path",
+			middleware: config.ScriptMiddleware{
+				Matcher: config.RequestMatcher{Path: "/api/test", Method: "POST"},
+				Script:  "function on_request(request) end",
+			},
+			expected: "[POST script-middleware:inline] /api/test",
+		},
+		{
+			name: "file script without matcher",
+			middleware: config.ScriptMiddleware{
+				File: "/scripts/middleware.lua",
+			},
+			expected: "[* script-middleware:file: /scripts/middleware.lua] *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.middleware.String())
+		})
+	}
+}
+
+func TestScriptMiddleware_Validate(t *testing.T) {
+	noFS := testutils.FsFromMap(t, map[string]string{})
+
+	t.Run("valid inline script without path", func(t *testing.T) {
+		err := (&config.ScriptMiddleware{
+			Script: testScriptContent,
+		}).Validate("middleware", noFS)
+		assert.NoError(t, err)
+	})
+
+	t.Run("valid file script with matcher", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{testScriptFilePath: testScriptContent})
+
+		err := (&config.ScriptMiddleware{
+			Matcher: config.RequestMatcher{Path: testAPIPath, Method: "POST"},
+			File:    testScriptFilePath,
+		}).Validate("middleware", fs)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		err := (&config.ScriptMiddleware{
+			Matcher: config.RequestMatcher{Path: "invalid-path"},
+			Script:  testScriptContent,
+		}).Validate("middleware", noFS)
+		require.EqualError(t, err, "middleware.path must be absolute and start with /")
+	})
+
+	t.Run("neither script nor file provided", func(t *testing.T) {
+		err := (&config.ScriptMiddleware{}).Validate("middleware", noFS)
+		require.EqualError(t, err, "middleware.script: either 'script' or 'file' must be provided\n"+
+			"middleware.file: either 'script' or 'file' must be provided")
+	})
+
+	t.Run("both script and file provided", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{testScriptFilePath: testScriptContent})
+
+		err := (&config.ScriptMiddleware{
+			Script: testScriptContent,
+			File:   testScriptFilePath,
+		}).Validate("middleware", fs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only one of 'script' or 'file' can be provided")
+	})
+
+	t.Run("file does not exist", func(t *testing.T) {
+		err := (&config.ScriptMiddleware{
+			File: "/scripts/nonexistent.lua",
+		}).Validate("middleware", noFS)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "middleware.file")
+	})
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const DefaultScriptTimeout = 30 * time.Second
+
+// UnsafeOSFunctions are the functions of the Lua os library that change the
+// system or stop the process. Scripts get them only when they are listed in
+// allow-os.
+var UnsafeOSFunctions = []string{"execute", "exit", "remove", "rename", "setenv", "setlocale", "tmpname"}
+
+// UnsafeLibraries are the Lua standard libraries that access files or the
+// internals of the VM. Scripts get them only when they are listed in
+// allow-libs.
+var UnsafeLibraries = []string{"debug", "io"}
+
+// ScriptSandbox limits the resources a script can use. Zero values keep the
+// defaults. MaxStackSize limits the values on the Lua stack (the registry of
+// gopher-lua), not the memory used by tables and strings.
+type ScriptSandbox struct {
+	Timeout         time.Duration `yaml:"timeout"`
+	MaxInstructions int           `yaml:"max-instructions"`
+	MaxCallDepth    int           `yaml:"max-call-depth"`
+	MaxStackSize    int           `yaml:"max-stack-size"`
+	AllowOS         []string      `yaml:"allow-os"`
+	AllowLibs       []string      `yaml:"allow-libs"`
+}
+
+func (s *ScriptSandbox) Clone() ScriptSandbox {
+	return ScriptSandbox{
+		Timeout:         s.Timeout,
+		MaxInstructions: s.MaxInstructions,
+		MaxCallDepth:    s.MaxCallDepth,
+		MaxStackSize:    s.MaxStackSize,
+		AllowOS:         slices.Clone(s.AllowOS),
+		AllowLibs:       slices.Clone(s.AllowLibs),
+	}
+}
+
+// ExecutionTimeout returns the time the script may run, DefaultScriptTimeout
+// when it is not set.
+func (s *ScriptSandbox) ExecutionTimeout() time.Duration {
+	if s.Timeout == 0 {
+		return DefaultScriptTimeout
+	}
+
+	return s.Timeout
+}
+
+func (s *ScriptSandbox) Validate(field string) error {
+	errs := []error{
+		ValidateDuration(joinPath(field, "timeout"), s.Timeout, true),
+		validateNotNegative(joinPath(field, "max-instructions"), s.MaxInstructions),
+		validateNotNegative(joinPath(field, "max-call-depth"), s.MaxCallDepth),
+		validateNotNegative(joinPath(field, "max-stack-size"), s.MaxStackSize),
+	}
+
+	errs = append(errs, validateAllowed(joinPath(field, "allow-os"), s.AllowOS, UnsafeOSFunctions))
+	errs = append(errs, validateAllowed(joinPath(field, "allow-libs"), s.AllowLibs, UnsafeLibraries))
+
+	return errors.Join(errs...)
+}
+
+func validateAllowed(field string, values, allowed []string) error {
+	var errs []error
+
+	for i, name := range values {
+		if !slices.Contains(allowed, name) {
+			errs = append(errs, &ValidationError{fmt.Sprintf(
+				"%s must be one of %s", joinPath(field, index(i)), strings.Join(allowed, ", "),
+			)})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateNotNegative(field string, value int) error {
+	if value < 0 {
+		return &ValidationError{fmt.Sprintf("%s must be greater than or equal to 0", field)}
+	}
+
+	return nil
+}
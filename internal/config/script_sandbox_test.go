@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestScriptSandboxUnmarshalYAML(t *testing.T) {
+	var actual config.Script
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+path: /api
+script: response:WriteString("ok")
+timeout: 5s
+max-instructions: 100000
+max-call-depth: 64
+max-stack-size: 2048
+allow-os: [execute, remove]
+allow-libs: [io]
+`), &actual))
+
+	assert.Equal(t, config.ScriptSandbox{
+		Timeout:         5 * time.Second,
+		MaxInstructions: 100000,
+		MaxCallDepth:    64,
+		MaxStackSize:    2048,
+		AllowOS:         []string{"execute", "remove"},
+		AllowLibs:       []string{"io"},
+	}, actual.Sandbox)
+}
+
+func TestScriptSandboxClone(t *testing.T) {
+	original := config.ScriptSandbox{
+		Timeout:         time.Second,
+		MaxInstructions: 100,
+		AllowOS:         []string{"execute"},
+		AllowLibs:       []string{"io"},
+	}
+
+	cloned := original.Clone()
+
+	assert.Equal(t, original, cloned)
+
+	cloned.AllowOS[0] = "exit"
+	cloned.AllowLibs[0] = "debug"
+	assert.Equal(t, []string{"execute"}, original.AllowOS)
+	assert.Equal(t, []string{"io"}, original.AllowLibs)
+}
+
+func TestScriptSandboxExecutionTimeout(t *testing.T) {
+	assert.Equal(t, config.DefaultScriptTimeout, (&config.ScriptSandbox{}).ExecutionTimeout())
+	assert.Equal(t, time.Second, (&config.ScriptSandbox{Timeout: time.Second}).ExecutionTimeout())
+}
+
+func TestScriptSandboxValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sandbox  config.ScriptSandbox
+		expected string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "all limits",
+			sandbox: config.ScriptSandbox{
+				Timeout:         time.Second,
+				MaxInstructions: 1000,
+				MaxCallDepth:    10,
+				MaxStackSize:    1000,
+				AllowOS:         config.UnsafeOSFunctions,
+				AllowLibs:       config.UnsafeLibraries,
+			},
+		},
+		{
+			name:     "negative timeout",
+			sandbox:  config.ScriptSandbox{Timeout: -time.Second},
+			expected: "script.timeout must be greater than or equal to 0",
+		},
+		{
+			name: "negative limits",
+			sandbox: config.ScriptSandbox{
+				MaxInstructions: -1,
+				MaxCallDepth:    -1,
+				MaxStackSize:    -1,
+			},
+			expected: "script.max-instructions must be greater than or equal to 0\n" +
+				"script.max-call-depth must be greater than or equal to 0\n" +
+				"script.max-stack-size must be greater than or equal to 0",
+		},
+		{
+			name:    "unknown os function",
+			sandbox: config.ScriptSandbox{AllowOS: []string{"execute", "time"}},
+			expected: "script.allow-os[1] must be one of " +
+				"execute, exit, remove, rename, setenv, setlocale, tmpname",
+		},
+		{
+			name:     "unknown library",
+			sandbox:  config.ScriptSandbox{AllowLibs: []string{"io", "os"}},
+			expected: "script.allow-libs[1] must be one of debug, io",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.sandbox.Validate("script")
+
+			if testCase.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// ScriptStoreConfig configures the key-value store shared by the scripts of a
+// mapping. The store is kept in memory unless a file is set.
+type ScriptStoreConfig struct {
+	File string `yaml:"file"`
+}
+
+func (s *ScriptStoreConfig) Clone() ScriptStoreConfig {
+	return ScriptStoreConfig{
+		File: s.File,
+	}
+}
+
+// UnmarshalYAML accepts the file path as a shorthand.
+func (s *ScriptStoreConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&s.File)
+	}
+
+	type scriptStoreConfigAlias ScriptStoreConfig
+
+	return value.Decode((*scriptStoreConfigAlias)(s))
+}
+
+func (s *ScriptStoreConfig) Validate(field string, fs afero.Fs) error {
+	if s.File == "" {
+		return nil
+	}
+
+	// The file is created on the first write, it only must not be a directory.
+	stat, err := fs.Stat(s.File)
+	if err == nil && stat.IsDir() {
+		return &ValidationError{fmt.Sprintf("%s %s is a directory", joinPath(field, "file"), s.File)}
+	}
+
+	return nil
+}
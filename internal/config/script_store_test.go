@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestScriptStoreConfigUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.ScriptStoreConfig
+	}{
+		{
+			name:     "file shorthand",
+			input:    `./store.json`,
+			expected: config.ScriptStoreConfig{File: "./store.json"},
+		},
+		{
+			name:     "full form",
+			input:    `file: ./store.json`,
+			expected: config.ScriptStoreConfig{File: "./store.json"},
+		},
+		{
+			name:     "empty full form",
+			input:    `{}`,
+			expected: config.ScriptStoreConfig{},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual config.ScriptStoreConfig
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestScriptStoreConfigValidate(t *testing.T) {
+	fs := testutils.FsFromMap(t, map[string]string{
+		"/data/store.json": `{}`,
+	})
+
+	tests := []struct {
+		name     string
+		config   config.ScriptStoreConfig
+		expected string
+	}{
+		{
+			name:   "in-memory store",
+			config: config.ScriptStoreConfig{},
+		},
+		{
+			name:   "existing file",
+			config: config.ScriptStoreConfig{File: "/data/store.json"},
+		},
+		{
+			name:   "missing file is created later",
+			config: config.ScriptStoreConfig{File: "/data/new.json"},
+		},
+		{
+			name:     "directory",
+			config:   config.ScriptStoreConfig{File: "/data"},
+			expected: "mapping.script-store.file /data is a directory",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.config.Validate("mapping.script-store", fs)
+
+			if testCase.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.expected)
+			}
+		})
+	}
+}
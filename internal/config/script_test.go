@@ -14,13 +14,13 @@ func TestRequestMatcher_Clone(t *testing.T) {
 	original := config.RequestMatcher{
 		Path:   "/api/test",
 		Method: "POST",
-		Queries: map[string]string{
-			"key1": "value1",
-			"key2": "value2",
+		Queries: config.ValueMatchers{
+			"key1": {Equals: "value1"},
+			"key2": {Equals: "value2"},
 		},
-		Headers: map[string]string{
-			headers.ContentType:   "application/json",
-			headers.Authorization: "Bearer token",
+		Headers: config.ValueMatchers{
+			headers.ContentType:   {Equals: "application/json"},
+			headers.Authorization: {Equals: "Bearer token"},
 		},
 	}
 
@@ -32,10 +32,10 @@ func TestRequestMatcher_Clone(t *testing.T) {
 	assert.Equal(t, original.Headers, cloned.Headers)
 
 	// Verify deep copy
-	cloned.Queries["key1"] = "modified"
+	cloned.Queries["key1"] = config.ValueMatcher{Equals: "modified"}
 	assert.NotEqual(t, original.Queries["key1"], cloned.Queries["key1"])
 
-	cloned.Headers[headers.ContentType] = "text/html"
+	cloned.Headers[headers.ContentType] = config.ValueMatcher{Equals: "text/html"}
 	assert.NotEqual(t, original.Headers[headers.ContentType], cloned.Headers[headers.ContentType])
 }
 
@@ -44,15 +44,16 @@ func TestScript_Clone(t *testing.T) {
 		Matcher: config.RequestMatcher{
 			Path:   "/api/script",
 			Method: "GET",
-			Queries: map[string]string{
-				"param": "value",
+			Queries: config.ValueMatchers{
+				"param": {Equals: "value"},
 			},
-			Headers: map[string]string{
-				"X-Custom": "header",
+			Headers: config.ValueMatchers{
+				"X-Custom": {Equals: "header"},
 			},
 		},
-		Script: "print('hello')",
-		File:   "/path/to/script.lua",
+		Script:   "print('hello')",
+		File:     "/path/to/script.lua",
+		Priority: 3,
 	}
 
 	cloned := original.Clone()
@@ -61,11 +62,12 @@ func TestScript_Clone(t *testing.T) {
 	assert.Equal(t, original.Matcher.Method, cloned.Matcher.Method)
 	assert.Equal(t, original.Script, cloned.Script)
 	assert.Equal(t, original.File, cloned.File)
+	assert.Equal(t, original.Priority, cloned.Priority)
 	assert.Equal(t, original.Matcher.Queries, cloned.Matcher.Queries)
 	assert.Equal(t, original.Matcher.Headers, cloned.Matcher.Headers)
 
 	// Verify deep copy
-	cloned.Matcher.Queries["param"] = "modified"
+	cloned.Matcher.Queries["param"] = config.ValueMatcher{Equals: "modified"}
 	assert.NotEqual(t, original.Matcher.Queries["param"], cloned.Matcher.Queries["param"])
 }
 
@@ -214,8 +216,8 @@ func TestScriptValidator(t *testing.T) {
 		err := (&config.Script{
 			Matcher: config.RequestMatcher{
 				Path:    "/api/test",
-				Queries: map[string]string{"filter": "active"},
-				Headers: map[string]string{headers.Authorization: "Bearer token"},
+				Queries: config.ValueMatchers{"filter": {Equals: "active"}},
+				Headers: config.ValueMatchers{headers.Authorization: {Equals: "Bearer token"}},
 			},
 			Script: testScriptContent,
 		}).Validate("script", noFS)
@@ -11,16 +11,18 @@ import (
 )
 
 type StaticDirectory struct {
-	Path  string `yaml:"path"`
-	Dir   string `yaml:"dir"`
-	Index string `yaml:"index"`
+	Path     string `yaml:"path"`
+	Dir      string `yaml:"dir"`
+	Index    string `yaml:"index"`
+	Priority int    `yaml:"priority"`
 }
 
 func (s *StaticDirectory) Clone() StaticDirectory {
 	return StaticDirectory{
-		Path:  s.Path,
-		Dir:   s.Dir,
-		Index: s.Index,
+		Path:     s.Path,
+		Dir:      s.Dir,
+		Index:    s.Index,
+		Priority: s.Priority,
 	}
 }
 
@@ -80,6 +80,16 @@ statics:
 					{Path: anotherPath, Dir: anotherStaticDir},
 				},
 			},
+			{
+				name: "object map with priority",
+				input: `
+statics:
+  /path: { dir: /static-dir, priority: 10 }
+`,
+				expected: config.StaticDirectories{
+					{Path: path, Dir: staticDir, Priority: 10},
+				},
+			},
 		}
 
 		for _, testCase := range tests {
@@ -148,9 +158,10 @@ func TestStaticDirMappingClone(t *testing.T) {
 		{
 			name: "structure with all field",
 			expected: config.StaticDirectory{
-				Dir:   "dir",
-				Path:  "/one-more-path",
-				Index: indexHTML,
+				Dir:      "dir",
+				Path:     "/one-more-path",
+				Index:    indexHTML,
+				Priority: 1,
 			},
 		},
 	}
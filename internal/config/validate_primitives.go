@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/helpers"
 	"github.com/evg4b/uncors/pkg/urlt"
 	"github.com/spf13/afero"
 )
@@ -171,3 +172,12 @@ func ValidateStringEnum(_ string, value string, options []string) error {
 
 	return nil
 }
+
+func ValidateTemplate(field, value string) error {
+	_, err := helpers.ParseTemplate(field, value, time.Now)
+	if err != nil {
+		return &ValidationError{fmt.Sprintf("%s is not a valid template: %v", field, err)}
+	}
+
+	return nil
+}
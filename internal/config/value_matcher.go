@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValueMatcher is a condition on a header, query parameter or body field.
+// All set conditions must hold. A plain scalar in YAML is a shorthand for
+// Equals; an empty Equals only requires the value to be present.
+type ValueMatcher struct {
+	Equals   string `yaml:"equals"`
+	Contains string `yaml:"contains"`
+	Regex    string `yaml:"regex"`
+	Glob     string `yaml:"glob"`
+	Absent   bool   `yaml:"absent"`
+}
+
+func (m ValueMatcher) Clone() ValueMatcher {
+	return m
+}
+
+// IsExact reports whether the matcher is a plain equality check that can be
+// handled by the router itself.
+func (m ValueMatcher) IsExact() bool {
+	return m.Contains == "" && m.Regex == "" && m.Glob == "" && !m.Absent
+}
+
+func (m *ValueMatcher) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Equals)
+	}
+
+	type valueMatcherAlias ValueMatcher
+
+	var alias valueMatcherAlias
+
+	err := value.Decode(&alias)
+	if err != nil {
+		return err
+	}
+
+	*m = ValueMatcher(alias)
+
+	return nil
+}
+
+func (m ValueMatcher) Validate(field string) error {
+	var errs []error
+
+	if m.Absent && (m.Equals != "" || m.Contains != "" || m.Regex != "" || m.Glob != "") {
+		errs = append(errs, &ValidationError{fmt.Sprintf(
+			"%s can not be combined with other conditions",
+			joinPath(field, "absent"),
+		)})
+	}
+
+	if m.Regex != "" {
+		_, err := regexp.Compile(m.Regex)
+		if err != nil {
+			errs = append(errs, &ValidationError{fmt.Sprintf(
+				"%s is not a valid regular expression",
+				joinPath(field, "regex"),
+			)})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValueMatchers maps header or query parameter names to their conditions.
+type ValueMatchers = map[string]ValueMatcher
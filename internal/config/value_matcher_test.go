@@ -0,0 +1,99 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValueMatcherUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected config.ValueMatchers
+	}{
+		{
+			name:     "string shorthand",
+			input:    "X-Token: abc",
+			expected: config.ValueMatchers{"X-Token": {Equals: "abc"}},
+		},
+		{
+			name:     "number shorthand",
+			input:    "page: 10",
+			expected: config.ValueMatchers{"page": {Equals: "10"}},
+		},
+		{
+			name: "full form",
+			input: `
+User-Agent:
+  contains: Mobile
+  regex: '^Mozilla/\d'
+X-Debug:
+  absent: true
+`,
+			expected: config.ValueMatchers{
+				"User-Agent": {Contains: "Mobile", Regex: `^Mozilla/\d`},
+				"X-Debug":    {Absent: true},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual config.ValueMatchers
+
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.input), &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestValueMatcherIsExact(t *testing.T) {
+	assert.True(t, config.ValueMatcher{}.IsExact())
+	assert.True(t, config.ValueMatcher{Equals: "value"}.IsExact())
+	assert.False(t, config.ValueMatcher{Contains: "value"}.IsExact())
+	assert.False(t, config.ValueMatcher{Regex: "value"}.IsExact())
+	assert.False(t, config.ValueMatcher{Glob: "value*"}.IsExact())
+	assert.False(t, config.ValueMatcher{Absent: true}.IsExact())
+}
+
+func TestValueMatcherValidate(t *testing.T) {
+	t.Run("valid matchers", func(t *testing.T) {
+		matchers := []config.ValueMatcher{
+			{},
+			{Equals: "value"},
+			{Contains: "a", Regex: "^a+$", Glob: "a*"},
+			{Absent: true},
+		}
+
+		for _, matcher := range matchers {
+			assert.NoError(t, matcher.Validate("headers.X-Test"))
+		}
+	})
+
+	tests := []struct {
+		name    string
+		matcher config.ValueMatcher
+		error   string
+	}{
+		{
+			name:    "invalid regex",
+			matcher: config.ValueMatcher{Regex: "[a"},
+			error:   "headers.X-Test.regex is not a valid regular expression",
+		},
+		{
+			name:    "absent with other conditions",
+			matcher: config.ValueMatcher{Absent: true, Equals: "value"},
+			error:   "headers.X-Test.absent can not be combined with other conditions",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.EqualError(t, testCase.matcher.Validate("headers.X-Test"), testCase.error)
+		})
+	}
+}
@@ -1,17 +1,26 @@
 package contracts
 
+import "time"
+
 type CachedHeader struct {
 	Name  string
 	Value []string
 }
 
 type CachedResponse struct {
-	Code    int
-	Body    []byte
-	Headers []CachedHeader
+	Code     int
+	Body     []byte
+	Headers  []CachedHeader
+	StoredAt time.Time
 }
 
 type Cache interface {
 	Get(key string) (CachedResponse, bool)
 	Set(key string, value CachedResponse)
 }
+
+// StaleCache is implemented by caches that keep expired entries until space
+// is needed, so they can still be served in offline mode.
+type StaleCache interface {
+	GetStale(key string) (CachedResponse, bool)
+}
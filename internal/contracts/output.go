@@ -2,6 +2,11 @@ package contracts
 
 import "io"
 
+type DebugOutput interface {
+	Debug(msg any)
+	Debugf(msg string, args ...any)
+}
+
 type InfoOutput interface {
 	Info(msg any)
 	Infof(msg string, args ...any)
@@ -22,6 +27,7 @@ type WarnOutput interface {
 
 type Output interface {
 	io.Writer
+	DebugOutput
 	InfoOutput
 	ErrorOutput
 	WarnOutput
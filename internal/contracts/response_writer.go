@@ -9,6 +9,7 @@ type ResponseCapture struct {
 	StatusCode int
 	Header     http.Header
 	Body       []byte
+	Truncated  bool
 	Duration   time.Duration
 }
 
@@ -19,6 +20,7 @@ type BodyCapturer interface {
 
 type ResponseWriter interface {
 	http.ResponseWriter
+	http.Flusher
 	StatusCode() int
 	BodyCapturer
 }
@@ -0,0 +1,35 @@
+package contracts
+
+import (
+	"net/http"
+	"time"
+)
+
+// UpstreamKey holds a *UpstreamTrace. Middlewares that need to know how a
+// request was forwarded put an empty trace into the request context, and the
+// proxy handler fills it in once the response has been copied.
+const UpstreamKey contextKey = "uncors-upstream"
+
+// UpstreamTrace describes the request the proxy sent to the target server and
+// when each phase of it happened. Zero times mean the phase did not happen,
+// e.g. DNS lookup and connect for a reused connection.
+type UpstreamTrace struct {
+	Method           string
+	URL              string
+	Header           http.Header
+	ServerIPAddress  string
+	LocalAddress     string
+	ConnectionReused bool
+
+	Start        time.Time
+	DNSStart     time.Time
+	DNSDone      time.Time
+	ConnectStart time.Time
+	ConnectDone  time.Time
+	TLSStart     time.Time
+	TLSDone      time.Time
+	GotConn      time.Time
+	WroteRequest time.Time
+	FirstByte    time.Time
+	Done         time.Time
+}
@@ -7,6 +7,10 @@ import (
 	"github.com/evg4b/uncors/internal/commands"
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/evg4b/uncors/internal/handler/script"
 	"github.com/evg4b/uncors/internal/helpers"
 	"github.com/evg4b/uncors/internal/server"
 	"github.com/spf13/afero"
@@ -20,9 +24,14 @@ type Container struct {
 	cliOutput            factory[contracts.Output]
 	requestTracker       factory[*server.RequestTracker]
 	generateCertsCommand factory[*commands.GenerateCertsCommand]
+	harImportCommand     factory[*commands.HARImportCommand]
 	hostCertManager      factory[*server.HostCertManager]
 	server               factory[*server.Server]
 	cache                factory1[contracts.Cache, *config.CacheConfig]
+	offlineMode          factory[*cache.OfflineMode]
+	scenarios            factory[*mock.Scenarios]
+	scriptStores         factory[*script.Stores]
+	harStats             factory[*har.Stats]
 
 	closers []io.Closer
 }
@@ -60,9 +69,14 @@ func NewContainer(options ...ContainerOption) *Container {
 	container.cliOutput = newFactory(container.newCliOutput)
 	container.requestTracker = newFactory(server.NewRequestTracker)
 	container.generateCertsCommand = newFactory(container.newGenerateCertsCommand)
+	container.harImportCommand = newFactory(container.newHARImportCommand)
 	container.hostCertManager = newFactory(container.newHostCertManager)
 	container.server = newFactory(container.newServer)
 	container.cache = newFactory1(container.newCache)
+	container.offlineMode = newFactory(container.newOfflineMode)
+	container.scenarios = newFactory(mock.NewScenarios)
+	container.scriptStores = newFactory(container.newScriptStores)
+	container.harStats = newFactory(har.NewStats)
 
 	return container
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/script"
 	"github.com/evg4b/uncors/internal/server"
 	"github.com/evg4b/uncors/internal/tui"
 )
@@ -16,10 +17,22 @@ func (c *Container) newGenerateCertsCommand() *commands.GenerateCertsCommand {
 	)
 }
 
+func (c *Container) newHARImportCommand() *commands.HARImportCommand {
+	return commands.NewHARImportCommand(
+		commands.ForHARImportWithOutput(c.CliOutput()),
+		commands.ForHARImportWithFs(c.fs),
+		commands.ForHARImportWithStdout(c.stdout),
+	)
+}
+
 func (c *Container) newHostCertManager() *server.HostCertManager {
 	return server.NewHostCertManager(c.fs)
 }
 
+func (c *Container) newScriptStores() *script.Stores {
+	return script.NewStores(c.fs)
+}
+
 func (c *Container) Server() *server.Server {
 	return c.server.GetOrBuild()
 }
@@ -32,9 +45,22 @@ func (c *Container) newServer() *server.Server {
 	return server.New(c.HostCertManager(), c.RequestTracker())
 }
 
-func (c *Container) newCache(cfs *config.CacheConfig) contracts.Cache {
+func (c *Container) newCache(cfs *config.CacheConfig) (contracts.Cache, error) {
+	if cfs.IsDiskStorage() {
+		dir, err := cfs.DiskDir()
+		if err != nil {
+			return nil, err
+		}
+
+		return cache.NewDiskCache(c.fs, dir, cfs.MaxSize, cfs.ExpirationTime)
+	}
+
 	instance := cache.NewRistrettoCache(cfs.MaxSize, cfs.ExpirationTime)
 	c.closers = append(c.closers, instance)
 
-	return instance
+	return instance, nil
+}
+
+func (c *Container) newOfflineMode() *cache.OfflineMode {
+	return cache.NewOfflineMode(false)
 }
@@ -21,21 +21,24 @@ func newFactory[T any](factoryFunc func() T) factory[T] {
 	return factory[T]{factory: factoryFunc}
 }
 
+// factory1 builds the value once from the argument of the first call. The
+// error of the build is returned by every call.
 type factory1[T any, D comparable] struct {
 	once sync.Once
 
 	cache   T
-	factory func(arg D) T
+	err     error
+	factory func(arg D) (T, error)
 }
 
-func (f *factory1[T, D]) GetOrBuild(arg D) T {
+func (f *factory1[T, D]) GetOrBuild(arg D) (T, error) {
 	f.once.Do(func() {
-		f.cache = f.factory(arg)
+		f.cache, f.err = f.factory(arg)
 	})
 
-	return f.cache
+	return f.cache, f.err
 }
 
-func newFactory1[T any, D comparable](factoryFunc func(arg D) T) factory1[T, D] {
+func newFactory1[T any, D comparable](factoryFunc func(arg D) (T, error)) factory1[T, D] {
 	return factory1[T, D]{factory: factoryFunc}
 }
@@ -8,10 +8,13 @@ import (
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/handler/har"
 	"github.com/evg4b/uncors/internal/handler/mock"
 	"github.com/evg4b/uncors/internal/handler/options"
 	"github.com/evg4b/uncors/internal/handler/proxy"
+	"github.com/evg4b/uncors/internal/handler/record"
+	"github.com/evg4b/uncors/internal/handler/replay"
 	"github.com/evg4b/uncors/internal/handler/rewrite"
 	"github.com/evg4b/uncors/internal/handler/router"
 	"github.com/evg4b/uncors/internal/handler/script"
@@ -48,6 +51,10 @@ func (c *Container) GenerateCertsCommand() *commands.GenerateCertsCommand {
 	return c.generateCertsCommand.GetOrBuild()
 }
 
+func (c *Container) HARImportCommand() *commands.HARImportCommand {
+	return c.harImportCommand.GetOrBuild()
+}
+
 func (c *Container) HostCertManager() *server.HostCertManager {
 	return c.hostCertManager.GetOrBuild()
 }
@@ -62,6 +69,14 @@ func (c *Container) OptionsMiddleware(cfg config.OptionsHandling) contracts.Midd
 	)
 }
 
+func (c *Container) CORSMiddleware(cfg *config.CORSConfig) contracts.Middleware {
+	return cors.NewMiddleware(cors.WithConfig(cfg))
+}
+
+func (c *Container) BodyRewriteMiddleware(cfg *config.BodyRewriteConfig) contracts.Middleware {
+	return proxy.NewBodyRewriteMiddleware(proxy.WithBodyRewriteConfig(cfg))
+}
+
 func (c *Container) StaticMiddleware(path string, dir config.StaticDirectory) contracts.Middleware {
 	return infra.NewPrefixedMiddleware(
 		static.NewStaticMiddleware(
@@ -81,19 +96,50 @@ func (c *Container) VersionChecker(proxy string) *version.Checker {
 	)
 }
 
-func (c *Container) Cache(cfs *config.CacheConfig) contracts.Cache {
+func (c *Container) Cache(cfs *config.CacheConfig) (contracts.Cache, error) {
 	return c.cache.GetOrBuild(cfs)
 }
 
-func (c *Container) CacheMiddleware(cfg *config.CacheConfig, globs config.CacheGlobs) contracts.Middleware {
+func (c *Container) OfflineMode() *cache.OfflineMode {
+	return c.offlineMode.GetOrBuild()
+}
+
+// HARStats is shared by all HAR writers so dropped entries are counted across
+// config reloads.
+func (c *Container) HARStats() *har.Stats {
+	return c.harStats.GetOrBuild()
+}
+
+func (c *Container) Scenarios() *mock.Scenarios {
+	return c.scenarios.GetOrBuild()
+}
+
+// ScriptStores keeps the data of the script stores between requests. It is
+// reset when the configuration is reloaded.
+func (c *Container) ScriptStores() *script.Stores {
+	return c.scriptStores.GetOrBuild()
+}
+
+// CacheMiddleware fails when the cache storage can not be created, e.g. when
+// the disk cache directory is not writable.
+func (c *Container) CacheMiddleware(cfg *config.CacheConfig, rules config.CacheRules) (contracts.Middleware, error) {
+	storage, err := c.Cache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return infra.NewPrefixedMiddleware(
 		cache.NewMiddleware(
 			cache.WithMethods(cfg.Methods),
-			cache.WithCacheStorage(c.Cache(cfg)),
-			cache.WithGlobs(globs),
+			cache.WithCacheStorage(storage),
+			cache.WithRules(rules),
+			cache.WithOfflineMode(c.OfflineMode()),
+			cache.WithHTTPSemantics(cfg.HTTPSemantics),
+			cache.WithTTL(cfg.ExpirationTime),
+			cache.WithBodyKey(cfg.BodyKey),
 		),
 		styles.CacheStyle.Render("CACHE"),
-	)
+	), nil
 }
 
 func (c *Container) MockHandler(response *config.Response) contracts.Handler {
@@ -106,7 +152,7 @@ func (c *Container) MockHandler(response *config.Response) contracts.Handler {
 	))
 }
 
-func (c *Container) ScriptHandler(scriptConfig *config.Script) contracts.Handler {
+func (c *Container) ScriptHandler(scriptConfig *config.Script, runtime *script.Runtime) contracts.Handler {
 	prefix := styles.RewriteStyle.Render("SCRIPT")
 	output := c.CliOutput()
 
@@ -114,9 +160,30 @@ func (c *Container) ScriptHandler(scriptConfig *config.Script) contracts.Handler
 		script.WithOutput(output.NewPrefixOutput(prefix)),
 		script.WithScript(scriptConfig),
 		script.WithFileSystem(c.fs),
+		script.WithRuntime(runtime),
 	))
 }
 
+func (c *Container) ScriptMiddleware(
+	scriptConfig *config.ScriptMiddleware,
+	runtime *script.Runtime,
+) contracts.Middleware {
+	prefix := styles.RewriteStyle.Render("SCRIPT")
+	output := c.CliOutput()
+
+	return infra.NewPrefixedMiddleware(script.NewMiddleware(
+		script.WithMiddlewareOutput(output.NewPrefixOutput(prefix)),
+		script.WithMiddlewareScript(scriptConfig),
+		script.WithMiddlewareFileSystem(c.fs),
+		script.WithMiddlewareRuntime(runtime),
+	), prefix)
+}
+
+// ScriptModules loads the modules scripts require from the directories.
+func (c *Container) ScriptModules(dirs config.LuaPath) *script.Modules {
+	return script.NewModules(c.fs, dirs)
+}
+
 func (c *Container) RewriteMiddleware(rewriting *config.RewritingOption) contracts.Middleware {
 	return infra.NewPrefixedMiddleware(
 		rewrite.NewMiddleware(rewrite.WithRewritingOptions(rewriting)),
@@ -125,23 +192,67 @@ func (c *Container) RewriteMiddleware(rewriting *config.RewritingOption) contrac
 }
 
 func (c *Container) HARMiddleware(harConfig *config.HARConfig) contracts.Middleware {
-	w := har.NewWriter(harConfig.File)
+	w := har.NewWriter(harConfig.File,
+		har.WithFormat(harConfig.Format),
+		har.WithMaxSize(harConfig.Rotate.MaxSize),
+		har.WithMaxEntries(harConfig.Rotate.MaxEntries),
+		har.WithRotateInterval(harConfig.Rotate.Interval),
+		har.WithStats(c.HARStats()),
+	)
 	c.closers = append(c.closers, w)
 
 	return har.NewMiddleware(
 		har.WithWriter(w),
 		har.WithCaptureSecureHeaders(harConfig.CaptureSecureHeaders),
+		har.WithInclude(harConfig.Include),
+		har.WithExclude(harConfig.Exclude),
+		har.WithMaxBodySize(harConfig.MaxBodySize),
+		har.WithRedaction(harConfig.Redact),
 	)
 }
 
-func (c *Container) ProxyHandler(mappings config.Mappings, proxyURL string) contracts.Handler {
+// RecordMiddleware saves proxied responses as mocks. It fails when the
+// previously recorded mocks can not be read, so they are never overwritten.
+func (c *Container) RecordMiddleware(recordConfig *config.RecordConfig) (contracts.Middleware, error) {
+	recorder, err := record.NewRecorder(c.fs, recordConfig.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.NewMiddleware(
+		record.WithRecorder(recorder),
+		record.WithPaths(recordConfig.Paths),
+	), nil
+}
+
+// HARReplayMiddleware serves responses recorded in a HAR file. It fails when
+// the file can not be read or parsed.
+func (c *Container) HARReplayMiddleware(replayConfig *config.HARReplayConfig) (contracts.Middleware, error) {
+	archive, err := har.ReadFile(c.fs, replayConfig.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return replay.NewMiddleware(
+		replay.WithEntries(archive.Log.Entries),
+		replay.WithIgnoreQueryOrder(replayConfig.IgnoreQueryOrder),
+		replay.WithMatchHeaders(replayConfig.MatchHeaders),
+		replay.WithIgnoreHeaders(replayConfig.IgnoreHeaders),
+		replay.WithMatchBody(replayConfig.MatchBody),
+		replay.WithOnMiss(replayConfig.Miss()),
+		replay.WithPrefix(styles.MockStyle.Render("REPLAY")),
+	), nil
+}
+
+func (c *Container) ProxyHandler(mappings config.Mappings, client contracts.HTTPClient) contracts.Handler {
 	prefix := styles.ProxyStyle.Render("PROXY")
 	output := c.CliOutput()
 
 	return infra.WithPrefix(prefix, proxy.NewProxyHandler(
 		proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(mappings)),
-		proxy.WithHTTPClient(infra.MakeHTTPClient(proxyURL)),
+		proxy.WithHTTPClient(client),
 		proxy.WithOutput(output.NewPrefixOutput(prefix)),
+		proxy.WithWebSocketPrefix(styles.WSStyle.Render("WS")),
 	))
 }
 
@@ -149,15 +260,30 @@ func (c *Container) Router(
 	mappings config.Mappings,
 	cacheConfig *config.CacheConfig,
 	proxyURL string,
+	luaPath config.LuaPath,
+	debug bool,
 ) (contracts.Handler, error) {
-	router, err := router.NewRouter(
-		mappings,
+	// The proxy and the scripts share one client, so both use the same proxy
+	// settings and connection pool.
+	httpClient := infra.MakeHTTPClient(proxyURL)
+
+	options := []router.Option{
 		router.WithDiContainer(c),
-		router.ForRouterWithDefaultHandler(c.ProxyHandler(mappings, proxyURL)),
-		router.ForRouterWithCacheMiddlewareFactory(func(globs config.CacheGlobs) contracts.Middleware {
-			return c.CacheMiddleware(cacheConfig, globs)
+		router.WithScenarios(c.Scenarios()),
+		router.WithScriptStores(c.ScriptStores()),
+		router.WithLuaPath(luaPath),
+		router.WithHTTPClient(httpClient),
+		router.ForRouterWithDefaultHandler(c.ProxyHandler(mappings, httpClient)),
+		router.ForRouterWithCacheMiddlewareFactory(func(rules config.CacheRules) (contracts.Middleware, error) {
+			return c.CacheMiddleware(cacheConfig, rules)
 		}),
-	)
+	}
+
+	if debug {
+		options = append(options, router.WithDebugOutput(c.CliOutput()))
+	}
+
+	router, err := router.NewRouter(mappings, options...)
 
 	return infra.CastToContractsHandler(router), err
 }
@@ -2,6 +2,7 @@ package di_test
 
 import (
 	"bytes"
+	"net/http"
 	"testing"
 	"time"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/di"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/script"
 	"github.com/evg4b/uncors/internal/server"
 	"github.com/evg4b/uncors/internal/version"
 	"github.com/evg4b/uncors/testing/hosts"
@@ -83,6 +86,13 @@ func TestContainer(t *testing.T) {
 		assert.IsType(t, &commands.GenerateCertsCommand{}, cmd)
 	})
 
+	t.Run("har import command", func(t *testing.T) {
+		cmd := container.HARImportCommand()
+
+		assert.NotNil(t, cmd)
+		assert.IsType(t, &commands.HARImportCommand{}, cmd)
+	})
+
 	t.Run("host cert manager", func(t *testing.T) {
 		manager := container.HostCertManager()
 
@@ -97,6 +107,19 @@ func TestContainer(t *testing.T) {
 		assert.IsType(t, &server.Server{}, srv)
 	})
 
+	t.Run("script stores", func(t *testing.T) {
+		stores := container.ScriptStores()
+
+		assert.NotNil(t, stores)
+		assert.Same(t, stores, container.ScriptStores())
+	})
+
+	t.Run("script modules", func(t *testing.T) {
+		modules := container.ScriptModules(config.LuaPath{"/lua"})
+
+		assert.NotNil(t, modules)
+	})
+
 	t.Run("options middleware", func(t *testing.T) {
 		cfg := config.OptionsHandling{
 			Headers: map[string]string{"X-Test": "value"},
@@ -128,24 +151,66 @@ func TestContainer(t *testing.T) {
 
 	t.Run("cache", func(t *testing.T) {
 		cfg := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
-		c := container.Cache(cfg)
+		c, err := container.Cache(cfg)
 
+		require.NoError(t, err)
 		assert.NotNil(t, c)
 		assert.Implements(t, (*contracts.Cache)(nil), c)
 	})
 
 	t.Run("cache singleton", func(t *testing.T) {
 		cfg := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
-		c1 := container.Cache(cfg)
-		c2 := container.Cache(cfg)
+		c1, err := container.Cache(cfg)
+		require.NoError(t, err)
+
+		c2, err := container.Cache(cfg)
+		require.NoError(t, err)
 
 		assert.Same(t, c1, c2)
 	})
 
+	t.Run("disk cache", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		diskContainer := di.NewContainer(di.WithFs(fs))
+		defer testutils.Close(t, diskContainer)
+
+		cfg := &config.CacheConfig{
+			MaxSize:        1024,
+			ExpirationTime: time.Minute,
+			Storage:        config.CacheStorageDisk,
+			Dir:            "/cache",
+		}
+		c, err := diskContainer.Cache(cfg)
+		require.NoError(t, err)
+
+		assert.IsType(t, &cache.DiskCache{}, c)
+
+		exists, err := afero.DirExists(fs, "/cache")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("disk cache directory can not be created", func(t *testing.T) {
+		readOnlyContainer := di.NewContainer(di.WithFs(afero.NewReadOnlyFs(afero.NewMemMapFs())))
+		defer testutils.Close(t, readOnlyContainer)
+
+		cfg := &config.CacheConfig{
+			MaxSize:        1024,
+			ExpirationTime: time.Minute,
+			Storage:        config.CacheStorageDisk,
+			Dir:            "/cache",
+		}
+
+		_, err := readOnlyContainer.CacheMiddleware(cfg, config.CacheRules{{Path: "*.json"}})
+
+		require.ErrorContains(t, err, `cannot create directory "/cache"`)
+	})
+
 	t.Run("cache middleware", func(t *testing.T) {
 		cfg := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
-		middleware := container.CacheMiddleware(cfg, config.CacheGlobs{"*.json"})
+		middleware, err := container.CacheMiddleware(cfg, config.CacheRules{{Path: "*.json"}})
 
+		require.NoError(t, err)
 		assert.NotNil(t, middleware)
 		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
 	})
@@ -159,16 +224,26 @@ func TestContainer(t *testing.T) {
 	})
 
 	t.Run("script handler", func(t *testing.T) {
-		script := &config.Script{
+		scriptConfig := &config.Script{
 			Matcher: config.RequestMatcher{Path: "/api"},
 			Script:  `response:set_status(200)`,
 		}
-		handler := container.ScriptHandler(script)
+		handler := container.ScriptHandler(scriptConfig, &script.Runtime{HTTPClient: http.DefaultClient})
 
 		assert.NotNil(t, handler)
 		assert.Implements(t, (*contracts.Handler)(nil), handler)
 	})
 
+	t.Run("script middleware", func(t *testing.T) {
+		scriptConfig := &config.ScriptMiddleware{
+			Script: `function on_request(request) end`,
+		}
+		middleware := container.ScriptMiddleware(scriptConfig, &script.Runtime{HTTPClient: http.DefaultClient})
+
+		assert.NotNil(t, middleware)
+		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
+	})
+
 	t.Run("rewrite middleware", func(t *testing.T) {
 		rewriting := &config.RewritingOption{From: "/old", To: "/new"}
 		middleware := container.RewriteMiddleware(rewriting)
@@ -185,11 +260,70 @@ func TestContainer(t *testing.T) {
 		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
 	})
 
+	t.Run("record middleware", func(t *testing.T) {
+		middleware, err := container.RecordMiddleware(&config.RecordConfig{Dir: "/recordings"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, middleware)
+		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
+	})
+
+	t.Run("record middleware fails on broken recordings", func(t *testing.T) {
+		broken := di.NewContainer(di.WithFs(testutils.FsFromMap(t, map[string]string{
+			"/recordings/mocks.yaml": "mocks: {",
+		})))
+		defer testutils.Close(t, broken)
+
+		middleware, err := broken.RecordMiddleware(&config.RecordConfig{Dir: "/recordings"})
+
+		require.Error(t, err)
+		assert.Nil(t, middleware)
+	})
+
+	t.Run("har replay middleware", func(t *testing.T) {
+		replayContainer := di.NewContainer(di.WithFs(testutils.FsFromMap(t, map[string]string{
+			"/session.har": `{"log":{"entries":[]}}`,
+		})))
+		defer testutils.Close(t, replayContainer)
+
+		middleware, err := replayContainer.HARReplayMiddleware(&config.HARReplayConfig{File: "/session.har"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, middleware)
+		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
+	})
+
+	t.Run("har replay middleware fails on missing file", func(t *testing.T) {
+		middleware, err := container.HARReplayMiddleware(&config.HARReplayConfig{File: "/missing.har"})
+
+		require.ErrorContains(t, err, "failed to read HAR file '/missing.har'")
+		assert.Nil(t, middleware)
+	})
+
+	t.Run("har replay middleware fails on broken file", func(t *testing.T) {
+		broken := di.NewContainer(di.WithFs(testutils.FsFromMap(t, map[string]string{
+			"/session.har": `{"log":`,
+		})))
+		defer testutils.Close(t, broken)
+
+		middleware, err := broken.HARReplayMiddleware(&config.HARReplayConfig{File: "/session.har"})
+
+		require.ErrorContains(t, err, "failed to parse HAR file '/session.har'")
+		assert.Nil(t, middleware)
+	})
+
+	t.Run("body rewrite middleware", func(t *testing.T) {
+		middleware := container.BodyRewriteMiddleware(&config.BodyRewriteConfig{Enabled: true})
+
+		assert.NotNil(t, middleware)
+		assert.Implements(t, (*contracts.Middleware)(nil), middleware)
+	})
+
 	t.Run("proxy handler", func(t *testing.T) {
 		mappings := config.Mappings{
 			{From: hosts.Localhost.HTTP(), To: hosts.Localhost.HTTPS()},
 		}
-		handler := container.ProxyHandler(mappings, "")
+		handler := container.ProxyHandler(mappings, http.DefaultClient)
 
 		assert.NotNil(t, handler)
 		assert.Implements(t, (*contracts.Handler)(nil), handler)
@@ -199,7 +333,8 @@ func TestContainer(t *testing.T) {
 		mappings := config.Mappings{
 			{From: hosts.Localhost.HTTP(), To: hosts.Localhost.HTTPS()},
 		}
-		handler, err := container.Router(mappings, &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}, "")
+		cacheConfig := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
+		handler, err := container.Router(mappings, cacheConfig, "", nil, false)
 
 		require.NoError(t, err)
 		assert.NotNil(t, handler)
@@ -214,7 +349,7 @@ func TestContainer(t *testing.T) {
 			{
 				From:  hosts.Localhost.HTTP(),
 				To:    hosts.Localhost.HTTPS(),
-				Cache: config.CacheGlobs{"*.json"},
+				Cache: config.CacheRules{{Path: "*.json"}},
 				Mocks: config.Mocks{
 					{
 						Matcher:  config.RequestMatcher{Path: "/data.json"},
@@ -224,12 +359,48 @@ func TestContainer(t *testing.T) {
 			},
 		}
 
-		handler, err := cacheContainer.Router(mappings, &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}, "")
+		cacheConfig := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
+		handler, err := cacheContainer.Router(mappings, cacheConfig, "", nil, false)
 
 		require.NoError(t, err)
 		assert.NotNil(t, handler)
 	})
 
+	t.Run("router fails on broken recordings", func(t *testing.T) {
+		broken := di.NewContainer(di.WithFs(testutils.FsFromMap(t, map[string]string{
+			"/recordings/mocks.yaml": "mocks: {",
+		})))
+		defer testutils.Close(t, broken)
+
+		mappings := config.Mappings{
+			{
+				From:   hosts.Localhost.HTTP(),
+				To:     hosts.Localhost.HTTPS(),
+				Record: config.RecordConfig{Dir: "/recordings"},
+			},
+		}
+
+		cacheConfig := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
+		_, err := broken.Router(mappings, cacheConfig, "", nil, false)
+
+		require.ErrorContains(t, err, "failed to prepare http://localhost")
+	})
+
+	t.Run("router fails on unreadable HAR replay file", func(t *testing.T) {
+		mappings := config.Mappings{
+			{
+				From:      hosts.Localhost.HTTP(),
+				To:        hosts.Localhost.HTTPS(),
+				HARReplay: config.HARReplayConfig{File: "/missing.har"},
+			},
+		}
+
+		cacheConfig := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
+		_, err := container.Router(mappings, cacheConfig, "", nil, false)
+
+		require.ErrorContains(t, err, "failed to read HAR file '/missing.har'")
+	})
+
 	t.Run("singleton behavior", func(t *testing.T) {
 		output1 := container.CliOutput()
 		output2 := container.CliOutput()
@@ -297,9 +468,10 @@ func TestContainerClose(t *testing.T) {
 	t.Run("close with cache closer succeeds", func(t *testing.T) {
 		container := di.NewContainer()
 		cfg := &config.CacheConfig{MaxSize: 100, ExpirationTime: time.Minute}
-		_ = container.Cache(cfg)
+		_, err := container.Cache(cfg)
+		require.NoError(t, err)
 
-		err := container.Close()
+		err = container.Close()
 
 		require.NoError(t, err)
 	})
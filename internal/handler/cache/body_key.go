@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+)
+
+// bodyKey returns a hash of the normalized request body to be added to the
+// cache key. The body is read fully and restored so the upstream still
+// receives it. Empty bodies produce an empty key.
+func bodyKey(request *contracts.Request, cfg config.CacheBodyKey) (string, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		return "", err
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(data) == 0 {
+		return "", nil
+	}
+
+	hash := sha256.Sum256(normalizeBody(data, cfg.Fields))
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// normalizeBody canonicalizes JSON bodies so formatting and key order do not
+// change the cache key. Other bodies are used as is.
+func normalizeBody(data []byte, fields []string) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+
+	err := decoder.Decode(&value)
+	if err != nil || decoder.More() {
+		return data
+	}
+
+	if len(fields) > 0 {
+		value = selectFields(value, fields)
+	}
+
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+
+	return canonical
+}
+
+// selectFields keeps only the listed dotted paths. Missing fields are
+// recorded as null so that absent and explicit null values share a key.
+func selectFields(value any, fields []string) map[string]any {
+	selected := make(map[string]any, len(fields))
+
+	for _, field := range fields {
+		selected[field], _ = helpers.LookupJSONField(value, strings.Split(field, "."))
+	}
+
+	return selected
+}
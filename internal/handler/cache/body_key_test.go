@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheMiddlewareBodyKey(t *testing.T) {
+	const endpoint = "/graphql"
+
+	setup := func(bodyKey config.CacheBodyKey) (contracts.Handler, *testutils.CountableHandler, *[]string) {
+		var received []string
+
+		counter := testutils.NewCounter(func(writer contracts.ResponseWriter, request *contracts.Request) error {
+			body, err := io.ReadAll(request.Body)
+			if err != nil {
+				return err
+			}
+
+			received = append(received, string(body))
+
+			writer.WriteHeader(http.StatusOK)
+			_, err = writer.Write(body)
+
+			return err
+		})
+
+		middleware := cache.NewMiddleware(
+			cache.WithCacheStorage(cache.NewRistrettoCache(1<<20, time.Minute)),
+			cache.WithMethods([]string{http.MethodPost}),
+			cache.WithRules(config.CacheRules{{Path: endpoint}}),
+			cache.WithBodyKey(bodyKey),
+		)
+
+		return infra.Mddleware(middleware, counter), counter, &received
+	}
+
+	post := func(t *testing.T, handler contracts.Handler, body string) string {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, endpoint, strings.NewReader(body))
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+		return testutils.ReadBody(t, recorder)
+	}
+
+	t.Run("requests with different bodies do not collide", func(t *testing.T) {
+		handler, counter, _ := setup(config.CacheBodyKey{Enabled: true})
+
+		assert.JSONEq(t, `{"id":1}`, post(t, handler, `{"id":1}`))
+		assert.JSONEq(t, `{"id":2}`, post(t, handler, `{"id":2}`))
+		assert.JSONEq(t, `{"id":1}`, post(t, handler, `{"id":1}`))
+		assert.Equal(t, 2, counter.Count())
+	})
+
+	t.Run("json bodies are canonicalized", func(t *testing.T) {
+		handler, counter, _ := setup(config.CacheBodyKey{Enabled: true})
+
+		post(t, handler, `{"operationName":"Users","variables":{"a":1,"b":2}}`)
+		post(t, handler, "{\n  \"variables\": {\"b\": 2, \"a\": 1},\n  \"operationName\": \"Users\"\n}")
+
+		assert.Equal(t, 1, counter.Count())
+	})
+
+	t.Run("only selected fields participate", func(t *testing.T) {
+		handler, counter, _ := setup(config.CacheBodyKey{
+			Enabled: true,
+			Fields:  []string{"operationName", "variables.id"},
+		})
+
+		post(t, handler, `{"operationName":"User","variables":{"id":1,"trace":"a"},"extensions":{"x":1}}`)
+		post(t, handler, `{"operationName":"User","variables":{"id":1,"trace":"b"},"extensions":{"x":2}}`)
+		assert.Equal(t, 1, counter.Count())
+
+		post(t, handler, `{"operationName":"User","variables":{"id":2,"trace":"a"}}`)
+		assert.Equal(t, 2, counter.Count())
+	})
+
+	t.Run("non json bodies are hashed as is", func(t *testing.T) {
+		handler, counter, _ := setup(config.CacheBodyKey{Enabled: true, Fields: []string{"id"}})
+
+		post(t, handler, "a=1&b=2")
+		post(t, handler, "a=1&b=2")
+		post(t, handler, "b=2&a=1")
+
+		assert.Equal(t, 2, counter.Count())
+	})
+
+	t.Run("body is forwarded to upstream", func(t *testing.T) {
+		handler, _, received := setup(config.CacheBodyKey{Enabled: true})
+
+		post(t, handler, `{ "id": 1 }`)
+
+		assert.Equal(t, []string{`{ "id": 1 }`}, *received)
+	})
+
+	t.Run("body is ignored when disabled", func(t *testing.T) {
+		handler, counter, _ := setup(config.CacheBodyKey{})
+
+		post(t, handler, `{"id":1}`)
+		assert.JSONEq(t, `{"id":1}`, post(t, handler, `{"id":2}`))
+
+		assert.Equal(t, 1, counter.Count())
+	})
+}
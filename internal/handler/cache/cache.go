@@ -24,13 +24,22 @@ const (
 	bufferItems = 64
 )
 
+type ristrettoEntry struct {
+	response  contracts.CachedResponse
+	expiresAt time.Time
+}
+
+// RistrettoCache keeps responses in memory. Expired entries are not dropped
+// right away: they stay until ristretto evicts them to free space, so they
+// can still be served in offline mode.
 type RistrettoCache struct {
-	storage *ristretto.Cache[string, contracts.CachedResponse]
+	storage *ristretto.Cache[string, ristrettoEntry]
 	ttl     time.Duration
+	now     func() time.Time
 }
 
 func NewRistrettoCache(maxSize int64, ttl time.Duration) *RistrettoCache {
-	storage, err := ristretto.NewCache(&ristretto.Config[string, contracts.CachedResponse]{
+	storage, err := ristretto.NewCache(&ristretto.Config[string, ristrettoEntry]{
 		NumCounters: numCounters,
 		MaxCost:     maxSize,
 		BufferItems: bufferItems,
@@ -42,15 +51,30 @@ func NewRistrettoCache(maxSize int64, ttl time.Duration) *RistrettoCache {
 	return &RistrettoCache{
 		storage: storage,
 		ttl:     ttl,
+		now:     time.Now,
 	}
 }
 
 func (cs *RistrettoCache) Get(key string) (contracts.CachedResponse, bool) {
-	return cs.storage.Get(key)
+	entry, ok := cs.storage.Get(key)
+	if !ok || !entry.expiresAt.After(cs.now()) {
+		return contracts.CachedResponse{}, false
+	}
+
+	return entry.response, true
+}
+
+func (cs *RistrettoCache) GetStale(key string) (contracts.CachedResponse, bool) {
+	entry, ok := cs.storage.Get(key)
+
+	return entry.response, ok
 }
 
 func (cs *RistrettoCache) Set(key string, value contracts.CachedResponse) {
-	cs.storage.SetWithTTL(key, value, CalcCost(&value), cs.ttl)
+	cs.storage.Set(key, ristrettoEntry{
+		response:  value,
+		expiresAt: cs.now().Add(cs.ttl),
+	}, CalcCost(&value))
 	cs.storage.Wait()
 }
 
@@ -94,6 +94,22 @@ func TestRistrettoCache(t *testing.T) {
 		assert.Equal(t, value.Headers, got.Headers)
 	})
 
+	t.Run("GetStale returns stored key", func(t *testing.T) {
+		value := contracts.CachedResponse{Body: []byte("stale-body")}
+
+		cache.Set("stale-key", value)
+
+		got, ok := cache.GetStale("stale-key")
+
+		assert.True(t, ok)
+		assert.Equal(t, value.Body, got.Body)
+	})
+
+	t.Run("GetStale missing key", func(t *testing.T) {
+		_, ok := cache.GetStale("non-existent")
+		assert.False(t, ok)
+	})
+
 	t.Run("Wait call", func(t *testing.T) {
 		assert.NotPanics(t, func() {
 			cache.Wait()
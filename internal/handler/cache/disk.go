@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/spf13/afero"
+)
+
+const (
+	diskEntryExt  = ".json"
+	diskDirMode   = 0o755
+	diskFileMode  = 0o644
+	diskTmpSuffix = ".tmp"
+)
+
+// diskEntry is the on-disk representation of a single cached response.
+type diskEntry struct {
+	Key       string                   `json:"key"`
+	ExpiresAt time.Time                `json:"expiresAt"`
+	Response  contracts.CachedResponse `json:"response"`
+}
+
+type diskIndexItem struct {
+	size      int64
+	expiresAt time.Time
+	writtenAt time.Time
+}
+
+// DiskCache stores cached responses as JSON files in a directory so they
+// survive restarts. Each entry lives in its own file named after the hash
+// of the cache key. Expired entries are kept as a fallback for offline mode;
+// the total size of the entry files is kept under maxSize by evicting
+// expired and then the oldest entries.
+type DiskCache struct {
+	fs      afero.Fs
+	dir     string
+	maxSize int64
+	ttl     time.Duration
+	now     func() time.Time
+
+	mutex     sync.Mutex
+	index     map[string]diskIndexItem
+	totalSize int64
+}
+
+type DiskCacheOption = func(*DiskCache)
+
+// WithClock overrides the time source used to check entry expiration.
+func WithClock(now func() time.Time) DiskCacheOption {
+	return func(c *DiskCache) {
+		c.now = now
+	}
+}
+
+func NewDiskCache(
+	fs afero.Fs,
+	dir string,
+	maxSize int64,
+	ttl time.Duration,
+	options ...DiskCacheOption,
+) (*DiskCache, error) {
+	cache := &DiskCache{
+		fs:      fs,
+		dir:     dir,
+		maxSize: maxSize,
+		ttl:     ttl,
+		now:     time.Now,
+		index:   map[string]diskIndexItem{},
+	}
+
+	for _, option := range options {
+		option(cache)
+	}
+
+	err := fs.MkdirAll(dir, diskDirMode)
+	if err != nil {
+		return nil, fmt.Errorf("cache: cannot create directory %q: %w", dir, err)
+	}
+
+	cache.loadIndex()
+
+	return cache, nil
+}
+
+func (c *DiskCache) Get(key string) (contracts.CachedResponse, bool) {
+	return c.get(key, false)
+}
+
+func (c *DiskCache) GetStale(key string) (contracts.CachedResponse, bool) {
+	return c.get(key, true)
+}
+
+func (c *DiskCache) get(key string, allowStale bool) (contracts.CachedResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	name := entryName(key)
+
+	item, ok := c.index[name]
+	if !ok || (!allowStale && !item.expiresAt.After(c.now())) {
+		return contracts.CachedResponse{}, false
+	}
+
+	entry, err := c.readEntry(name)
+	if err != nil || entry.Key != key {
+		if err != nil {
+			c.remove(name)
+		}
+
+		return contracts.CachedResponse{}, false
+	}
+
+	return entry.Response, true
+}
+
+func (c *DiskCache) Set(key string, value contracts.CachedResponse) {
+	now := c.now()
+	entry := diskEntry{
+		Key:       key,
+		ExpiresAt: now.Add(c.ttl),
+		Response:  value,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("cache: cannot encode entry: %v", err)
+
+		return
+	}
+
+	if int64(len(data)) > c.maxSize {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	name := entryName(key)
+
+	err = c.writeFile(name, data)
+	if err != nil {
+		log.Printf("cache: cannot write entry %q: %v", name, err)
+
+		return
+	}
+
+	c.totalSize -= c.index[name].size
+	c.index[name] = diskIndexItem{
+		size:      int64(len(data)),
+		expiresAt: entry.ExpiresAt,
+		writtenAt: now,
+	}
+	c.totalSize += int64(len(data))
+
+	c.evict(name)
+}
+
+func (c *DiskCache) writeFile(name string, data []byte) error {
+	path := filepath.Join(c.dir, name)
+	tmp := path + diskTmpSuffix
+
+	err := afero.WriteFile(c.fs, tmp, data, diskFileMode)
+	if err != nil {
+		return err
+	}
+
+	err = c.fs.Rename(tmp, path)
+	if err != nil {
+		_ = c.fs.Remove(tmp)
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *DiskCache) readEntry(name string) (diskEntry, error) {
+	var entry diskEntry
+
+	data, err := afero.ReadFile(c.fs, filepath.Join(c.dir, name))
+	if err != nil {
+		return entry, err
+	}
+
+	err = json.Unmarshal(data, &entry)
+
+	return entry, err
+}
+
+// evict drops expired entries and then the oldest ones until the total size
+// fits into maxSize. The entry named keep is never evicted.
+func (c *DiskCache) evict(keep string) {
+	if c.totalSize <= c.maxSize {
+		return
+	}
+
+	now := c.now()
+	names := make([]string, 0, len(c.index))
+
+	for name, item := range c.index {
+		if name == keep {
+			continue
+		}
+
+		if !item.expiresAt.After(now) {
+			c.remove(name)
+
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		return c.index[a].writtenAt.Compare(c.index[b].writtenAt)
+	})
+
+	for _, name := range names {
+		if c.totalSize <= c.maxSize {
+			return
+		}
+
+		c.remove(name)
+	}
+}
+
+func (c *DiskCache) remove(name string) {
+	err := c.fs.Remove(filepath.Join(c.dir, name))
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("cache: cannot remove entry %q: %v", name, err)
+	}
+
+	c.totalSize -= c.index[name].size
+	delete(c.index, name)
+}
+
+// loadIndex restores the index from entries left by a previous run.
+func (c *DiskCache) loadIndex() {
+	files, err := afero.ReadDir(c.fs, c.dir)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, diskEntryExt) {
+			continue
+		}
+
+		entry, err := c.readEntry(name)
+		if err != nil {
+			_ = c.fs.Remove(filepath.Join(c.dir, name))
+
+			continue
+		}
+
+		c.index[name] = diskIndexItem{
+			size:      file.Size(),
+			expiresAt: entry.ExpiresAt,
+			writtenAt: file.ModTime(),
+		}
+		c.totalSize += file.Size()
+	}
+
+	c.evict("")
+}
+
+func entryName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:]) + diskEntryExt
+}
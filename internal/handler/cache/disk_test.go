@@ -0,0 +1,228 @@
+package cache_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diskCacheDir = "/cache/uncors"
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func diskResponse(body string) contracts.CachedResponse {
+	return contracts.CachedResponse{
+		Code: 200,
+		Body: []byte(body),
+		Headers: []contracts.CachedHeader{
+			testutils.CachedHeader("Content-Type", "text/plain"),
+		},
+	}
+}
+
+func entryFiles(t *testing.T, fs afero.Fs) []string {
+	t.Helper()
+
+	files, err := afero.ReadDir(fs, diskCacheDir)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+
+	return names
+}
+
+func newDiskCache(
+	t *testing.T,
+	fs afero.Fs,
+	maxSize int64,
+	ttl time.Duration,
+	options ...cache.DiskCacheOption,
+) *cache.DiskCache {
+	t.Helper()
+
+	storage, err := cache.NewDiskCache(fs, diskCacheDir, maxSize, ttl, options...)
+	require.NoError(t, err)
+
+	return storage
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Run("stores and returns entries", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		storage := newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now))
+
+		storage.Set("GET http://localhost/api", diskResponse("hello"))
+
+		value, ok := storage.Get("GET http://localhost/api")
+		require.True(t, ok)
+		assert.Equal(t, diskResponse("hello"), value)
+
+		_, ok = storage.Get("GET http://localhost/other")
+		assert.False(t, ok)
+	})
+
+	t.Run("creates cache directory", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		newDiskCache(t, fs, 1<<20, time.Minute)
+
+		exists, err := afero.DirExists(fs, diskCacheDir)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("fails when cache directory can not be created", func(t *testing.T) {
+		fs := afero.NewReadOnlyFs(afero.NewMemMapFs())
+
+		_, err := cache.NewDiskCache(fs, diskCacheDir, 1<<20, time.Minute)
+
+		require.ErrorContains(t, err, "cannot create directory")
+	})
+
+	t.Run("entries survive restart", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		first := newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now))
+		first.Set("key", diskResponse("persisted"))
+
+		second := newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now))
+
+		value, ok := second.Get("key")
+		require.True(t, ok)
+		assert.Equal(t, diskResponse("persisted"), value)
+	})
+
+	t.Run("expired entries are returned only as stale", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		storage := newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now))
+		storage.Set("key", diskResponse("value"))
+
+		clock.Advance(2 * time.Minute)
+
+		_, ok := storage.Get("key")
+		assert.False(t, ok)
+
+		value, ok := storage.GetStale("key")
+		require.True(t, ok)
+		assert.Equal(t, diskResponse("value"), value)
+	})
+
+	t.Run("expired entries survive restart as stale", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now)).
+			Set("key", diskResponse("value"))
+
+		clock.Advance(2 * time.Minute)
+		storage := newDiskCache(t, fs, 1<<20, time.Minute, cache.WithClock(clock.Now))
+
+		_, ok := storage.Get("key")
+		assert.False(t, ok)
+
+		_, ok = storage.GetStale("key")
+		assert.True(t, ok)
+	})
+
+	t.Run("evicts expired entries first", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		body := strings.Repeat("x", 100)
+		storage := newDiskCache(t, fs, 700, time.Minute, cache.WithClock(clock.Now))
+
+		storage.Set("first", diskResponse(body))
+		clock.Advance(50 * time.Second)
+		storage.Set("second", diskResponse(body))
+		clock.Advance(20 * time.Second)
+		storage.Set("third", diskResponse(body))
+
+		_, ok := storage.GetStale("first")
+		assert.False(t, ok)
+
+		_, ok = storage.Get("second")
+		assert.True(t, ok)
+	})
+
+	t.Run("evicts oldest entries over max size", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		clock := newFakeClock()
+		body := strings.Repeat("x", 100)
+		storage := newDiskCache(t, fs, 700, time.Hour, cache.WithClock(clock.Now))
+
+		storage.Set("first", diskResponse(body))
+		clock.Advance(time.Second)
+		storage.Set("second", diskResponse(body))
+		clock.Advance(time.Second)
+		storage.Set("third", diskResponse(body))
+
+		_, ok := storage.Get("first")
+		assert.False(t, ok)
+
+		_, ok = storage.Get("second")
+		assert.True(t, ok)
+
+		_, ok = storage.Get("third")
+		assert.True(t, ok)
+
+		var total int64
+		for _, name := range entryFiles(t, fs) {
+			info, err := fs.Stat(filepath.Join(diskCacheDir, name))
+			require.NoError(t, err)
+
+			total += info.Size()
+		}
+
+		assert.LessOrEqual(t, total, int64(700))
+	})
+
+	t.Run("skips entries larger than max size", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		storage := newDiskCache(t, fs, 64, time.Minute)
+
+		storage.Set("key", diskResponse(strings.Repeat("x", 100)))
+
+		_, ok := storage.Get("key")
+		assert.False(t, ok)
+		assert.Empty(t, entryFiles(t, fs))
+	})
+
+	t.Run("ignores corrupted files", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, fs.MkdirAll(diskCacheDir, 0o755))
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(diskCacheDir, "broken.json"), []byte("{"), 0o644))
+
+		storage := newDiskCache(t, fs, 1<<20, time.Minute)
+
+		assert.Empty(t, entryFiles(t, fs))
+
+		storage.Set("key", diskResponse("value"))
+		_, ok := storage.Get("key")
+		assert.True(t, ok)
+	})
+}
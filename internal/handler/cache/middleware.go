@@ -2,28 +2,48 @@ package cache
 
 import (
 	"fmt"
-	"net/url"
+	"net/http"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/helpers"
 	"github.com/evg4b/uncors/internal/infra"
 	"github.com/evg4b/uncors/pkg/urlt"
+	"github.com/go-http-utils/headers"
 	"github.com/samber/lo"
 )
 
+const offlineMissMessage = `uncors is running in offline mode and has no cached response for this request.
+
+Method:    %s
+URL:       %s
+Cache key: %s
+
+Disable offline mode to forward the request to the upstream server.
+`
+
 type Middleware struct {
-	cache     contracts.Cache
-	methods   []string
-	pathGlobs config.CacheGlobs
+	cache   contracts.Cache
+	methods []string
+	rules   config.CacheRules
+	offline *OfflineMode
+	bodyKey config.CacheBodyKey
+
+	httpSemantics bool
+	ttl           time.Duration
+	now           func() time.Time
+	revalidating  sync.Map
 }
 
 func NewMiddleware(options ...MiddlewareOption) *Middleware {
-	middleware := helpers.ApplyOptions(&Middleware{}, options)
+	middleware := helpers.ApplyOptions(&Middleware{now: time.Now}, options)
 
 	helpers.AssertIsDefined(middleware.cache, "Cache storage is not configured")
 
@@ -31,12 +51,12 @@ func NewMiddleware(options ...MiddlewareOption) *Middleware {
 }
 
 func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request, next contracts.Next) error {
-	isCacheable, err := m.isCacheableRequest(request)
+	rule, err := m.matchRule(request)
 	if err != nil {
 		return err
 	}
 
-	if !isCacheable {
+	if rule == nil {
 		return next(writer, request)
 	}
 
@@ -44,17 +64,29 @@ func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, request *contrac
 		return next(w, r)
 	})
 
-	return m.cacheRequest(writer, request, handler)
+	return m.cacheRequest(writer, request, handler, rule)
 }
 
 func (m *Middleware) cacheRequest(
 	writer contracts.ResponseWriter,
 	request *contracts.Request,
 	next contracts.Handler,
+	rule *config.CacheRule,
 ) error {
-	cacheKey := m.extractCacheKey(request.Method, request.URL)
+	cacheKey, err := m.extractCacheKey(request, rule)
+	if err != nil {
+		return err
+	}
+
+	if m.offline != nil && m.offline.Enabled() {
+		return m.serveOffline(writer, request, cacheKey)
+	}
+
+	if m.httpSemantics {
+		return m.cacheWithSemantics(writer, request, next, cacheKey, rule)
+	}
 
-	if cachedResponse := m.getCachedResponse(cacheKey); cachedResponse != nil {
+	if cachedResponse := m.getFreshResponse(cacheKey, rule); cachedResponse != nil {
 		m.writeCachedResponse(writer, cachedResponse)
 
 		return nil
@@ -62,34 +94,61 @@ func (m *Middleware) cacheRequest(
 
 	writer.EnableBodyCapture()
 
-	err := next.ServeHTTP(writer, request)
+	err = next.ServeHTTP(writer, request)
+
+	m.storeResponse(cacheKey, writer.Captured(), rule)
+
+	return err
+}
+
+// serveOffline answers from the cache without calling the upstream. Expired
+// entries are served as well; misses get a synthesized 504 response.
+func (m *Middleware) serveOffline(writer contracts.ResponseWriter, request *contracts.Request, cacheKey string) error {
+	if cachedResponse, _ := m.lookupStale(cacheKey, request); cachedResponse != nil {
+		m.writeCachedResponse(writer, cachedResponse)
+
+		return nil
+	}
+
+	header := writer.Header()
+	header.Set(headers.ContentType, "text/plain; charset=utf-8")
+	header.Set(headers.CacheControl, "no-cache, no-store, max-age=0, must-revalidate")
+	header.Set(headers.XContentTypeOptions, "nosniff")
+	cors.WriteHeaders(header, request)
+
+	writer.WriteHeader(http.StatusGatewayTimeout)
 
-	m.storeResponse(cacheKey, writer.Captured())
+	_, err := fmt.Fprintf(writer, offlineMissMessage, request.Method, request.URL.String(), cacheKey)
 
 	return err
 }
 
-func (m *Middleware) storeResponse(key string, capture contracts.ResponseCapture) {
-	if !helpers.Is2xxCode(capture.StatusCode) {
+func (m *Middleware) storeResponse(key string, capture contracts.ResponseCapture, rule *config.CacheRule) {
+	if !rule.IsCacheableStatus(capture.StatusCode) || capture.Truncated {
 		return
 	}
 
-	headers := lo.MapToSlice(capture.Header, func(name string, values []string) contracts.CachedHeader {
+	m.cache.Set(key, m.toCachedResponse(capture))
+}
+
+func (m *Middleware) toCachedResponse(capture contracts.ResponseCapture) contracts.CachedResponse {
+	cachedHeaders := lo.MapToSlice(capture.Header, func(name string, values []string) contracts.CachedHeader {
 		return contracts.CachedHeader{
 			Name:  name,
 			Value: values,
 		}
 	})
 
-	sort.Slice(headers, func(i, j int) bool {
-		return headers[i].Name < headers[j].Name
+	sort.Slice(cachedHeaders, func(i, j int) bool {
+		return cachedHeaders[i].Name < cachedHeaders[j].Name
 	})
 
-	m.cache.Set(key, contracts.CachedResponse{
-		Code:    capture.StatusCode,
-		Body:    capture.Body,
-		Headers: headers,
-	})
+	return contracts.CachedResponse{
+		Code:     capture.StatusCode,
+		Body:     capture.Body,
+		Headers:  cachedHeaders,
+		StoredAt: m.now(),
+	}
 }
 
 func (m *Middleware) writeCachedResponse(writer contracts.ResponseWriter, cachedResponse *contracts.CachedResponse) {
@@ -111,30 +170,54 @@ func (m *Middleware) writeCachedResponse(writer contracts.ResponseWriter, cached
 	}
 }
 
-func (m *Middleware) isCacheableRequest(request *contracts.Request) (bool, error) {
-	if !slices.Contains(m.methods, request.Method) {
-		return false, nil
-	}
+// matchRule returns the first rule whose glob and methods match the request
+// or nil when the request should not be cached.
+func (m *Middleware) matchRule(request *contracts.Request) (*config.CacheRule, error) {
+	for i := range m.rules {
+		rule := &m.rules[i]
+
+		if !slices.Contains(m.ruleMethods(rule), request.Method) {
+			continue
+		}
 
-	for _, pattern := range m.pathGlobs {
-		ok, err := doublestar.PathMatch(pattern, request.URL.Path)
+		ok, err := doublestar.PathMatch(rule.Path, request.URL.Path)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		if ok {
-			return true, nil
+			return rule, nil
 		}
 	}
 
-	return false, nil
+	return nil, nil //nolint:nilnil
 }
 
-func (m *Middleware) extractCacheKey(method string, url *url.URL) string {
-	values := urlt.URL_Query(url)
+func (m *Middleware) ruleMethods(rule *config.CacheRule) []string {
+	if len(rule.Methods) > 0 {
+		return rule.Methods
+	}
+
+	return m.methods
+}
+
+func (m *Middleware) ruleTTL(rule *config.CacheRule) time.Duration {
+	if rule.ExpirationTime > 0 {
+		return rule.ExpirationTime
+	}
+
+	return m.ttl
+}
+
+func (m *Middleware) extractCacheKey(request *contracts.Request, rule *config.CacheRule) (string, error) {
+	values := urlt.URL_Query(request.URL)
 
 	items := make([]string, 0, len(values))
 	for key, value := range values {
+		if slices.Contains(rule.IgnoreQuery, key) {
+			continue
+		}
+
 		sort.Strings(value)
 		valuesKey := key + "=" + strings.Join(value, ",")
 		items = append(items, valuesKey)
@@ -142,7 +225,25 @@ func (m *Middleware) extractCacheKey(method string, url *url.URL) string {
 
 	sort.Strings(items)
 
-	return fmt.Sprintf("[%s]%s%s?%s", method, urlt.URL_Hostname(url), url.Path, strings.Join(items, ";"))
+	cacheKey := fmt.Sprintf(
+		"[%s]%s%s?%s",
+		request.Method, urlt.URL_Hostname(request.URL), request.URL.Path, strings.Join(items, ";"),
+	)
+
+	if !m.bodyKey.Enabled {
+		return cacheKey, nil
+	}
+
+	hash, err := bodyKey(request, m.bodyKey)
+	if err != nil {
+		return "", err
+	}
+
+	if hash != "" {
+		cacheKey += "#body=" + hash
+	}
+
+	return cacheKey, nil
 }
 
 func (m *Middleware) getCachedResponse(cacheKey string) *contracts.CachedResponse {
@@ -153,6 +254,27 @@ func (m *Middleware) getCachedResponse(cacheKey string) *contracts.CachedRespons
 	return nil
 }
 
+// getFreshResponse returns the cached response unless it has expired. Rules
+// with their own expiration time check the entry age themselves, since the
+// storage only knows the global one.
+func (m *Middleware) getFreshResponse(cacheKey string, rule *config.CacheRule) *contracts.CachedResponse {
+	var cachedResponse *contracts.CachedResponse
+	if rule.ExpirationTime > 0 {
+		cachedResponse = m.getStale(cacheKey)
+		if cachedResponse != nil && !m.now().Before(cachedResponse.StoredAt.Add(rule.ExpirationTime)) {
+			return nil
+		}
+	} else {
+		cachedResponse = m.getCachedResponse(cacheKey)
+	}
+
+	if cachedResponse == nil || isVaryMarker(cachedResponse) {
+		return nil
+	}
+
+	return cachedResponse
+}
+
 type MiddlewareOption = func(*Middleware)
 
 func WithMethods(methods []string) MiddlewareOption {
@@ -161,9 +283,9 @@ func WithMethods(methods []string) MiddlewareOption {
 	}
 }
 
-func WithGlobs(globs config.CacheGlobs) MiddlewareOption {
+func WithRules(rules config.CacheRules) MiddlewareOption {
 	return func(m *Middleware) {
-		m.pathGlobs = globs
+		m.rules = rules
 	}
 }
 
@@ -172,3 +294,41 @@ func WithCacheStorage(cache contracts.Cache) MiddlewareOption {
 		m.cache = cache
 	}
 }
+
+// WithOfflineMode sets the switch that makes the middleware serve only from
+// the cache.
+func WithOfflineMode(mode *OfflineMode) MiddlewareOption {
+	return func(m *Middleware) {
+		m.offline = mode
+	}
+}
+
+// WithBodyKey makes the request body part of the cache key.
+func WithBodyKey(bodyKey config.CacheBodyKey) MiddlewareOption {
+	return func(m *Middleware) {
+		m.bodyKey = bodyKey
+	}
+}
+
+// WithHTTPSemantics makes the middleware honor Cache-Control, Vary and
+// validators of upstream responses.
+func WithHTTPSemantics(enabled bool) MiddlewareOption {
+	return func(m *Middleware) {
+		m.httpSemantics = enabled
+	}
+}
+
+// WithTTL sets the freshness lifetime of responses without explicit
+// max-age when HTTP semantics are enabled.
+func WithTTL(ttl time.Duration) MiddlewareOption {
+	return func(m *Middleware) {
+		m.ttl = ttl
+	}
+}
+
+// WithNow overrides the time source used to check freshness of entries.
+func WithNow(now func() time.Time) MiddlewareOption {
+	return func(m *Middleware) {
+		m.now = now
+	}
+}
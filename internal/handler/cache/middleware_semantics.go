@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/go-http-utils/headers"
+)
+
+// cacheWithSemantics serves the request following RFC 9111: fresh entries are
+// returned as is, entries within stale-while-revalidate are returned while a
+// background request refreshes them, and other stale entries are revalidated
+// with the upstream before use.
+func (m *Middleware) cacheWithSemantics(
+	writer contracts.ResponseWriter,
+	request *contracts.Request,
+	next contracts.Handler,
+	baseKey string,
+	rule *config.CacheRule,
+) error {
+	cachedResponse, key := m.lookupStale(baseKey, request)
+	if cachedResponse != nil {
+		switch entryFreshness(cachedResponse, m.now(), m.ruleTTL(rule)) {
+		case fresh:
+			m.writeCachedResponse(writer, cachedResponse)
+
+			return nil
+		case staleWhileRevalidate:
+			m.writeCachedResponse(writer, cachedResponse)
+			m.revalidateInBackground(key, baseKey, request, next, cachedResponse, rule)
+
+			return nil
+		case stale:
+			return m.revalidate(writer, request, next, key, baseKey, cachedResponse, rule)
+		}
+	}
+
+	writer.EnableBodyCapture()
+
+	err := next.ServeHTTP(writer, request)
+
+	m.storeWithSemantics(baseKey, request, writer.Captured(), rule)
+
+	return err
+}
+
+// revalidate asks the upstream whether the cached response is still valid.
+// On 304 the refreshed entry is served, otherwise the new response is.
+func (m *Middleware) revalidate(
+	writer contracts.ResponseWriter,
+	request *contracts.Request,
+	next contracts.Handler,
+	key, baseKey string,
+	cachedResponse *contracts.CachedResponse,
+	rule *config.CacheRule,
+) error {
+	buffered := infra.NewBufferedWriter()
+
+	err := next.ServeHTTP(buffered, conditionalRequest(request, cachedResponse))
+	if err != nil {
+		return err
+	}
+
+	capture := buffered.Captured()
+	if capture.StatusCode == http.StatusNotModified {
+		refreshed := refreshResponse(cachedResponse, capture.Header, m.now())
+		m.cache.Set(key, refreshed)
+		m.writeCachedResponse(writer, &refreshed)
+
+		return nil
+	}
+
+	m.storeWithSemantics(baseKey, request, capture, rule)
+
+	return buffered.Replay(writer)
+}
+
+// revalidateInBackground refreshes the entry without blocking the client.
+// Only one background request per entry runs at a time.
+func (m *Middleware) revalidateInBackground(
+	key, baseKey string,
+	request *contracts.Request,
+	next contracts.Handler,
+	cachedResponse *contracts.CachedResponse,
+	rule *config.CacheRule,
+) {
+	if _, running := m.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	backgroundRequest := request.Clone(detachedContext(request.Context()))
+
+	go func() {
+		defer m.revalidating.Delete(key)
+		defer helpers.PanicInterceptor(func(value any) {
+			log.Printf("cache: background revalidation of %s failed: %v", key, value)
+		})
+
+		err := m.revalidate(infra.NewBufferedWriter(), backgroundRequest, next, key, baseKey, cachedResponse, rule)
+		if err != nil {
+			log.Printf("cache: background revalidation of %s failed: %v", key, err)
+		}
+	}()
+}
+
+// storeWithSemantics stores a response unless the upstream forbids it. For
+// responses with Vary a marker listing the headers is stored under the base
+// key and the response itself under a key including their request values.
+func (m *Middleware) storeWithSemantics(
+	baseKey string,
+	request *contracts.Request,
+	capture contracts.ResponseCapture,
+	rule *config.CacheRule,
+) {
+	if !rule.IsCacheableStatus(capture.StatusCode) || capture.Truncated || !isStorable(capture.Header) {
+		return
+	}
+
+	vary := varyHeaders(capture.Header)
+	if len(vary) == 0 {
+		m.cache.Set(baseKey, m.toCachedResponse(capture))
+
+		return
+	}
+
+	m.cache.Set(baseKey, contracts.CachedResponse{
+		Headers:  []contracts.CachedHeader{{Name: headers.Vary, Value: vary}},
+		StoredAt: m.now(),
+	})
+	m.cache.Set(variantKey(baseKey, vary, request), m.toCachedResponse(capture))
+}
+
+// lookupStale returns the entry for the request regardless of its age along
+// with the key it is stored under, following Vary markers.
+func (m *Middleware) lookupStale(baseKey string, request *contracts.Request) (*contracts.CachedResponse, string) {
+	cachedResponse := m.getStale(baseKey)
+	if cachedResponse == nil || !isVaryMarker(cachedResponse) {
+		return cachedResponse, baseKey
+	}
+
+	key := variantKey(baseKey, cachedHeaderValues(cachedResponse, headers.Vary), request)
+
+	return m.getStale(key), key
+}
+
+func (m *Middleware) getStale(cacheKey string) *contracts.CachedResponse {
+	staleCache, ok := m.cache.(contracts.StaleCache)
+	if !ok {
+		return m.getCachedResponse(cacheKey)
+	}
+
+	if cachedResponse, ok := staleCache.GetStale(cacheKey); ok {
+		return &cachedResponse
+	}
+
+	return nil
+}
+
+func isVaryMarker(response *contracts.CachedResponse) bool {
+	return response.Code == 0
+}
+
+func variantKey(baseKey string, vary []string, request *contracts.Request) string {
+	var builder strings.Builder
+
+	builder.WriteString(baseKey)
+
+	for _, name := range vary {
+		builder.WriteString("|")
+		builder.WriteString(name)
+		builder.WriteString("=")
+		builder.WriteString(strings.Join(request.Header.Values(name), ","))
+	}
+
+	return builder.String()
+}
+
+// detachedContext keeps the values of the client request for the background
+// revalidation but drops its cancellation and the per-request state the
+// revalidation must not write to after the client response is done: the HAR
+// upstream trace and the request tracker prefix.
+func detachedContext(ctx context.Context) context.Context {
+	ctx = context.WithoutCancel(ctx)
+	ctx = context.WithValue(ctx, contracts.UpstreamKey, (*contracts.UpstreamTrace)(nil))
+
+	return context.WithValue(ctx, contracts.PrefixUpdaterKey, nil)
+}
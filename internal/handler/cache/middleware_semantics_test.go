@@ -0,0 +1,402 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type upstreamResponse struct {
+	code    int
+	headers map[string]string
+	body    string
+}
+
+// fakeUpstream answers with the configured response and records the
+// requests it received.
+type fakeUpstream struct {
+	mutex    sync.Mutex
+	response func(request *contracts.Request) upstreamResponse
+	requests []*contracts.Request
+}
+
+func (u *fakeUpstream) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request) error {
+	u.mutex.Lock()
+	u.requests = append(u.requests, request)
+	response := u.response(request)
+	u.mutex.Unlock()
+
+	for name, value := range response.headers {
+		writer.Header().Set(name, value)
+	}
+
+	writer.WriteHeader(response.code)
+	fmt.Fprint(writer, response.body)
+
+	return nil
+}
+
+func (u *fakeUpstream) Count() int {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return len(u.requests)
+}
+
+func (u *fakeUpstream) Last() *contracts.Request {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return u.requests[len(u.requests)-1]
+}
+
+func staticUpstream(cacheControl string) *fakeUpstream {
+	return &fakeUpstream{
+		response: func(_ *contracts.Request) upstreamResponse {
+			return upstreamResponse{
+				code:    http.StatusOK,
+				headers: map[string]string{headers.CacheControl: cacheControl},
+				body:    "body",
+			}
+		},
+	}
+}
+
+func newSemanticsHandler(t *testing.T, upstream *fakeUpstream, clock *fakeClock, ttl time.Duration) contracts.Handler {
+	t.Helper()
+
+	storage := newDiskCache(t, afero.NewMemMapFs(), 1<<20, ttl, cache.WithClock(clock.Now))
+
+	middleware := cache.NewMiddleware(
+		cache.WithCacheStorage(storage),
+		cache.WithMethods([]string{http.MethodGet}),
+		cache.WithRules(config.CacheRules{{Path: "/**"}}),
+		cache.WithHTTPSemantics(true),
+		cache.WithTTL(ttl),
+		cache.WithNow(clock.Now),
+	)
+
+	return infra.Mddleware(middleware, upstream)
+}
+
+func serveRequest(t *testing.T, handler contracts.Handler, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/data", nil)
+
+	for name, value := range header {
+		request.Header.Set(name, value)
+	}
+
+	require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+	return recorder
+}
+
+func TestCacheMiddlewareHTTPSemantics(t *testing.T) {
+	t.Run("does not store responses forbidden by Cache-Control", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			cacheControl string
+		}{
+			{name: "no-store", cacheControl: "no-store"},
+			{name: "private", cacheControl: "private, max-age=60"},
+			{name: "uppercase directive", cacheControl: "No-Store"},
+		}
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				upstream := staticUpstream(testCase.cacheControl)
+				handler := newSemanticsHandler(t, upstream, newFakeClock(), time.Minute)
+
+				serveRequest(t, handler, nil)
+				serveRequest(t, handler, nil)
+
+				assert.Equal(t, 2, upstream.Count())
+			})
+		}
+	})
+
+	t.Run("uses max-age as freshness lifetime", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := staticUpstream("max-age=10")
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+		clock.Advance(5 * time.Second)
+		serveRequest(t, handler, nil)
+		assert.Equal(t, 1, upstream.Count())
+
+		clock.Advance(10 * time.Second)
+		serveRequest(t, handler, nil)
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("max-age can exceed configured ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := staticUpstream("max-age=3600")
+		handler := newSemanticsHandler(t, upstream, clock, time.Second)
+
+		serveRequest(t, handler, nil)
+		clock.Advance(30 * time.Minute)
+		serveRequest(t, handler, nil)
+
+		assert.Equal(t, 1, upstream.Count())
+	})
+
+	t.Run("s-maxage wins over max-age", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := staticUpstream("max-age=3600, s-maxage=10")
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+		clock.Advance(time.Minute)
+		serveRequest(t, handler, nil)
+
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("falls back to configured ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := staticUpstream("")
+		handler := newSemanticsHandler(t, upstream, clock, time.Minute)
+
+		serveRequest(t, handler, nil)
+		clock.Advance(30 * time.Second)
+		serveRequest(t, handler, nil)
+		assert.Equal(t, 1, upstream.Count())
+
+		clock.Advance(time.Minute)
+		serveRequest(t, handler, nil)
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("separates responses by Vary headers", func(t *testing.T) {
+		upstream := &fakeUpstream{
+			response: func(request *contracts.Request) upstreamResponse {
+				return upstreamResponse{
+					code:    http.StatusOK,
+					headers: map[string]string{headers.Vary: "Accept-Language"},
+					body:    "lang:" + request.Header.Get(headers.AcceptLanguage),
+				}
+			},
+		}
+		handler := newSemanticsHandler(t, upstream, newFakeClock(), time.Minute)
+
+		english := map[string]string{headers.AcceptLanguage: "en"}
+		german := map[string]string{headers.AcceptLanguage: "de"}
+
+		assert.Equal(t, "lang:en", serveRequest(t, handler, english).Body.String())
+		assert.Equal(t, "lang:de", serveRequest(t, handler, german).Body.String())
+		assert.Equal(t, "lang:en", serveRequest(t, handler, english).Body.String())
+		assert.Equal(t, "lang:de", serveRequest(t, handler, german).Body.String())
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("does not store Vary: *", func(t *testing.T) {
+		upstream := &fakeUpstream{
+			response: func(_ *contracts.Request) upstreamResponse {
+				return upstreamResponse{code: http.StatusOK, headers: map[string]string{headers.Vary: "*"}}
+			},
+		}
+		handler := newSemanticsHandler(t, upstream, newFakeClock(), time.Minute)
+
+		serveRequest(t, handler, nil)
+		serveRequest(t, handler, nil)
+
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("revalidates stale entry", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			validatorHeader string
+			validator       string
+			conditional     string
+		}{
+			{
+				name:            "with ETag",
+				validatorHeader: headers.ETag,
+				validator:       `"v1"`,
+				conditional:     headers.IfNoneMatch,
+			},
+			{
+				name:            "with Last-Modified",
+				validatorHeader: headers.LastModified,
+				validator:       "Mon, 01 Jan 2024 00:00:00 GMT",
+				conditional:     headers.IfModifiedSince,
+			},
+		}
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				clock := newFakeClock()
+				upstream := &fakeUpstream{
+					response: func(request *contracts.Request) upstreamResponse {
+						if request.Header.Get(testCase.conditional) == testCase.validator {
+							return upstreamResponse{
+								code:    http.StatusNotModified,
+								headers: map[string]string{headers.CacheControl: "max-age=60"},
+							}
+						}
+
+						return upstreamResponse{
+							code: http.StatusOK,
+							headers: map[string]string{
+								headers.CacheControl:     "max-age=10",
+								testCase.validatorHeader: testCase.validator,
+							},
+							body: "original",
+						}
+					},
+				}
+				handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+				serveRequest(t, handler, nil)
+				clock.Advance(time.Minute)
+
+				recorder := serveRequest(t, handler, nil)
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "original", recorder.Body.String())
+				assert.Equal(t, "max-age=60", recorder.Header().Get(headers.CacheControl))
+				assert.Equal(t, testCase.validator, upstream.Last().Header.Get(testCase.conditional))
+				assert.Equal(t, 2, upstream.Count())
+
+				clock.Advance(30 * time.Second)
+				serveRequest(t, handler, nil)
+				assert.Equal(t, 2, upstream.Count())
+			})
+		}
+	})
+
+	t.Run("replaces stale entry when upstream returns new content", func(t *testing.T) {
+		clock := newFakeClock()
+		version := "v1"
+		upstream := &fakeUpstream{
+			response: func(_ *contracts.Request) upstreamResponse {
+				return upstreamResponse{
+					code:    http.StatusOK,
+					headers: map[string]string{headers.CacheControl: "max-age=10", headers.ETag: version},
+					body:    version,
+				}
+			},
+		}
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+		version = "v2"
+		clock.Advance(time.Minute)
+
+		assert.Equal(t, "v2", serveRequest(t, handler, nil).Body.String())
+		assert.Equal(t, "v2", serveRequest(t, handler, nil).Body.String())
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("does not forward 304 to client that sent no validators", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := &fakeUpstream{
+			response: func(request *contracts.Request) upstreamResponse {
+				if request.Header.Get(headers.IfNoneMatch) != "" {
+					return upstreamResponse{code: http.StatusNotModified}
+				}
+
+				return upstreamResponse{
+					code:    http.StatusOK,
+					headers: map[string]string{headers.CacheControl: "no-cache", headers.ETag: `"v1"`},
+					body:    "original",
+				}
+			},
+		}
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+		recorder := serveRequest(t, handler, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "original", recorder.Body.String())
+		assert.Equal(t, 2, upstream.Count())
+	})
+
+	t.Run("serves stale entry while revalidating", func(t *testing.T) {
+		clock := newFakeClock()
+		version := "v1"
+		upstream := &fakeUpstream{
+			response: func(_ *contracts.Request) upstreamResponse {
+				return upstreamResponse{
+					code:    http.StatusOK,
+					headers: map[string]string{headers.CacheControl: "max-age=10, stale-while-revalidate=60"},
+					body:    version,
+				}
+			},
+		}
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+
+		upstream.mutex.Lock()
+		version = "v2"
+		upstream.mutex.Unlock()
+
+		clock.Advance(30 * time.Second)
+
+		assert.Equal(t, "v1", serveRequest(t, handler, nil).Body.String())
+		assert.Eventually(t, func() bool {
+			return upstream.Count() == 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return serveRequest(t, handler, nil).Body.String() == "v2"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("background revalidation does not share client request state", func(t *testing.T) {
+		clock := newFakeClock()
+		upstream := staticUpstream("max-age=10, stale-while-revalidate=60")
+		handler := newSemanticsHandler(t, upstream, clock, time.Hour)
+
+		serveRequest(t, handler, nil)
+		clock.Advance(30 * time.Second)
+
+		ctx := context.WithValue(t.Context(), contracts.UpstreamKey, &contracts.UpstreamTrace{})
+		ctx = context.WithValue(ctx, contracts.PrefixUpdaterKey, func(string) {})
+		request := httptest.NewRequestWithContext(ctx, http.MethodGet, "/api/data", nil)
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(httptest.NewRecorder()), request))
+
+		require.Eventually(t, func() bool {
+			return upstream.Count() == 2
+		}, time.Second, 10*time.Millisecond)
+
+		background := upstream.Last().Context()
+		assert.Nil(t, background.Value(contracts.UpstreamKey))
+		assert.Nil(t, background.Value(contracts.PrefixUpdaterKey))
+		assert.NoError(t, background.Err())
+	})
+
+	t.Run("ignores Cache-Control when disabled", func(t *testing.T) {
+		upstream := staticUpstream("no-store")
+		middleware := cache.NewMiddleware(
+			cache.WithCacheStorage(cache.NewRistrettoCache(1<<20, time.Minute)),
+			cache.WithMethods([]string{http.MethodGet}),
+			cache.WithRules(config.CacheRules{{Path: "/**"}}),
+		)
+		handler := infra.Mddleware(middleware, upstream)
+
+		serveRequest(t, handler, nil)
+		serveRequest(t, handler, nil)
+
+		assert.Equal(t, 1, upstream.Count())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/evg4b/uncors/internal/server"
 	"github.com/evg4b/uncors/testing/testutils"
 	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,9 +35,9 @@ func TestCacheMiddleware(t *testing.T) {
 	middleware := cache.NewMiddleware(
 		cache.WithCacheStorage(cache.NewRistrettoCache(1024*1024, time.Minute)),
 		cache.WithMethods([]string{http.MethodGet}),
-		cache.WithGlobs(config.CacheGlobs{
-			"/translations",
-			cacheGlob,
+		cache.WithRules(config.CacheRules{
+			{Path: "/translations"},
+			{Path: cacheGlob},
 		}),
 	)
 
@@ -185,6 +187,37 @@ func TestCacheMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("should not cache response truncated at capture limit", func(t *testing.T) {
+		const count = 3
+
+		largeBody := strings.Repeat("a", server.MaxCaptureSize+1)
+
+		testHandler := testutils.NewCounter(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+			writer.WriteHeader(http.StatusOK)
+			fmt.Fprint(writer, largeBody)
+
+			return nil
+		})
+
+		middleware := cache.NewMiddleware(
+			cache.WithCacheStorage(cache.NewRistrettoCache(1024*1024*64, time.Minute)),
+			cache.WithMethods([]string{http.MethodGet}),
+			cache.WithRules(config.CacheRules{{Path: cacheGlob}}),
+		)
+
+		wrappedHandler := infra.Mddleware(middleware, testHandler)
+
+		testutils.Times(count, func(_ int) {
+			recorder := httptest.NewRecorder()
+			rec := server.NewResponseRecorder(recorder)
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, constantEndpoint, nil)
+			require.NoError(t, wrappedHandler.ServeHTTP(rec, request))
+			assert.Equal(t, len(largeBody), recorder.Body.Len())
+		})
+
+		assert.Equal(t, count, testHandler.Count())
+	})
+
 	t.Run("should not cache response between different hosts matched by one rule", func(t *testing.T) {
 		const count = 5
 
@@ -193,7 +226,7 @@ func TestCacheMiddleware(t *testing.T) {
 		middleware := cache.NewMiddleware(
 			cache.WithCacheStorage(cache.NewRistrettoCache(1024*1024, time.Minute)),
 			cache.WithMethods([]string{http.MethodGet}),
-			cache.WithGlobs(config.CacheGlobs{cacheGlob}),
+			cache.WithRules(config.CacheRules{{Path: cacheGlob}}),
 		)
 
 		wrappedHandler := infra.Mddleware(middleware, testHandler)
@@ -219,7 +252,7 @@ func TestCacheMiddleware(t *testing.T) {
 		middleware := cache.NewMiddleware(
 			cache.WithCacheStorage(cache.NewRistrettoCache(1024*1024, time.Minute)),
 			cache.WithMethods(methods),
-			cache.WithGlobs(config.CacheGlobs{cacheGlob}),
+			cache.WithRules(config.CacheRules{{Path: cacheGlob}}),
 		)
 
 		testHandler := testutils.NewCounter(func(writer contracts.ResponseWriter, request *contracts.Request) error {
@@ -244,3 +277,246 @@ func TestCacheMiddleware(t *testing.T) {
 		assert.Len(t, methods, testHandler.Count())
 	})
 }
+
+func TestCacheMiddlewareRules(t *testing.T) {
+	const body = "rule body"
+
+	setup := func(status int, rules config.CacheRules) (*fakeClock, contracts.Handler, *testutils.CountableHandler) {
+		clock := newFakeClock()
+		storage := newDiskCache(t, afero.NewMemMapFs(), 1<<20, time.Minute, cache.WithClock(clock.Now))
+
+		middleware := cache.NewMiddleware(
+			cache.WithCacheStorage(storage),
+			cache.WithMethods([]string{http.MethodGet}),
+			cache.WithRules(rules),
+			cache.WithNow(clock.Now),
+		)
+
+		counter := testutils.NewCounter(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+			writer.WriteHeader(status)
+			fmt.Fprint(writer, body)
+
+			return nil
+		})
+
+		return clock, infra.Mddleware(middleware, counter), counter
+	}
+
+	serve := func(t *testing.T, handler contracts.Handler, method, url string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), method, url, nil)
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+		return recorder
+	}
+
+	t.Run("uses rule expiration time shorter than global", func(t *testing.T) {
+		clock, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/**", ExpirationTime: 10 * time.Second},
+		})
+
+		serve(t, handler, http.MethodGet, "/api/data")
+		clock.Advance(5 * time.Second)
+		serve(t, handler, http.MethodGet, "/api/data")
+		assert.Equal(t, 1, counter.Count())
+
+		clock.Advance(10 * time.Second)
+		serve(t, handler, http.MethodGet, "/api/data")
+		assert.Equal(t, 2, counter.Count())
+	})
+
+	t.Run("uses rule expiration time longer than global", func(t *testing.T) {
+		clock, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/**", ExpirationTime: time.Hour},
+		})
+
+		serve(t, handler, http.MethodGet, "/api/data")
+		clock.Advance(30 * time.Minute)
+		serve(t, handler, http.MethodGet, "/api/data")
+		assert.Equal(t, 1, counter.Count())
+
+		clock.Advance(time.Hour)
+		serve(t, handler, http.MethodGet, "/api/data")
+		assert.Equal(t, 2, counter.Count())
+	})
+
+	t.Run("first matching rule wins", func(t *testing.T) {
+		clock, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/users/**", ExpirationTime: 10 * time.Second},
+			{Path: "/api/**"},
+		})
+
+		serve(t, handler, http.MethodGet, "/api/users/1")
+		serve(t, handler, http.MethodGet, "/api/other")
+		clock.Advance(30 * time.Second)
+		serve(t, handler, http.MethodGet, "/api/users/1")
+		serve(t, handler, http.MethodGet, "/api/other")
+
+		assert.Equal(t, 3, counter.Count())
+	})
+
+	t.Run("rule methods override global methods", func(t *testing.T) {
+		_, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/search", Methods: []string{http.MethodPost}},
+			{Path: "/api/**"},
+		})
+
+		testutils.Times(3, func(_ int) {
+			serve(t, handler, http.MethodPost, "/api/search")
+		})
+		assert.Equal(t, 1, counter.Count())
+
+		counter.Reset()
+		testutils.Times(3, func(_ int) {
+			serve(t, handler, http.MethodPost, "/api/other")
+		})
+		assert.Equal(t, 3, counter.Count())
+	})
+
+	t.Run("caches listed status codes", func(t *testing.T) {
+		_, handler, counter := setup(http.StatusNotFound, config.CacheRules{
+			{Path: "/api/**", StatusCodes: []int{http.StatusOK, http.StatusNotFound}},
+		})
+
+		testutils.Times(3, func(_ int) {
+			recorder := serve(t, handler, http.MethodGet, "/api/missing")
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.Equal(t, body, testutils.ReadBody(t, recorder))
+		})
+
+		assert.Equal(t, 1, counter.Count())
+	})
+
+	t.Run("does not cache status codes outside the list", func(t *testing.T) {
+		_, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/**", StatusCodes: []int{http.StatusNotFound}},
+		})
+
+		testutils.Times(3, func(_ int) {
+			serve(t, handler, http.MethodGet, "/api/data")
+		})
+
+		assert.Equal(t, 3, counter.Count())
+	})
+
+	t.Run("ignores listed query parameters in cache key", func(t *testing.T) {
+		_, handler, counter := setup(http.StatusOK, config.CacheRules{
+			{Path: "/api/**", IgnoreQuery: []string{"_ts"}},
+		})
+
+		serve(t, handler, http.MethodGet, "/api/data?id=1&_ts=100")
+		serve(t, handler, http.MethodGet, "/api/data?id=1&_ts=200")
+		serve(t, handler, http.MethodGet, "/api/data?id=1")
+		assert.Equal(t, 1, counter.Count())
+
+		serve(t, handler, http.MethodGet, "/api/data?id=2&_ts=100")
+		assert.Equal(t, 2, counter.Count())
+	})
+}
+
+func TestCacheMiddlewareOffline(t *testing.T) {
+	const (
+		cacheGlob   = "/api/**"
+		endpoint    = "/api/data"
+		missingPath = "/api/missing"
+		body        = "cached body"
+	)
+
+	setup := func() (*cache.OfflineMode, *fakeClock, contracts.Handler, *testutils.CountableHandler) {
+		clock := newFakeClock()
+		offline := cache.NewOfflineMode(false)
+		storage := newDiskCache(t, afero.NewMemMapFs(), 1<<20, time.Minute, cache.WithClock(clock.Now))
+
+		middleware := cache.NewMiddleware(
+			cache.WithCacheStorage(storage),
+			cache.WithMethods([]string{http.MethodGet}),
+			cache.WithRules(config.CacheRules{{Path: cacheGlob}}),
+			cache.WithOfflineMode(offline),
+		)
+
+		counter := testutils.NewCounter(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+			writer.WriteHeader(http.StatusOK)
+			fmt.Fprint(writer, body)
+
+			return nil
+		})
+
+		return offline, clock, infra.Mddleware(middleware, counter), counter
+	}
+
+	serve := func(t *testing.T, handler contracts.Handler, method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), method, path, nil)
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+		return recorder
+	}
+
+	t.Run("serves cached response without calling upstream", func(t *testing.T) {
+		offline, _, handler, counter := setup()
+
+		serve(t, handler, http.MethodGet, endpoint)
+		offline.Set(true)
+
+		recorder := serve(t, handler, http.MethodGet, endpoint)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, body, testutils.ReadBody(t, recorder))
+		assert.Equal(t, 1, counter.Count())
+	})
+
+	t.Run("serves expired response", func(t *testing.T) {
+		offline, clock, handler, counter := setup()
+
+		serve(t, handler, http.MethodGet, endpoint)
+		clock.Advance(time.Hour)
+		offline.Set(true)
+
+		recorder := serve(t, handler, http.MethodGet, endpoint)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, body, testutils.ReadBody(t, recorder))
+		assert.Equal(t, 1, counter.Count())
+	})
+
+	t.Run("returns 504 for cache miss", func(t *testing.T) {
+		offline, _, handler, counter := setup()
+		offline.Set(true)
+
+		recorder := serve(t, handler, http.MethodGet, missingPath)
+
+		assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get(headers.ContentType))
+
+		responseBody := testutils.ReadBody(t, recorder)
+		assert.Contains(t, responseBody, "offline mode")
+		assert.Contains(t, responseBody, missingPath)
+		assert.Equal(t, 0, counter.Count())
+	})
+
+	t.Run("passes through non cacheable requests", func(t *testing.T) {
+		offline, _, handler, counter := setup()
+		offline.Set(true)
+
+		recorder := serve(t, handler, http.MethodPost, endpoint)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, 1, counter.Count())
+	})
+
+	t.Run("calls upstream after offline mode is disabled", func(t *testing.T) {
+		offline, _, handler, counter := setup()
+		offline.Set(true)
+		serve(t, handler, http.MethodGet, missingPath)
+
+		offline.Set(false)
+		recorder := serve(t, handler, http.MethodGet, missingPath)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, 1, counter.Count())
+	})
+}
@@ -0,0 +1,35 @@
+package cache
+
+import "sync/atomic"
+
+// OfflineMode is a switch shared by all cache middlewares. While it is
+// enabled cached responses are served regardless of their expiration time
+// and cache misses are answered with 504 instead of reaching the upstream.
+type OfflineMode struct {
+	enabled atomic.Bool
+}
+
+func NewOfflineMode(enabled bool) *OfflineMode {
+	mode := &OfflineMode{}
+	mode.enabled.Store(enabled)
+
+	return mode
+}
+
+func (m *OfflineMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+func (m *OfflineMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// Toggle flips the mode and returns the new state.
+func (m *OfflineMode) Toggle() bool {
+	for {
+		current := m.enabled.Load()
+		if m.enabled.CompareAndSwap(current, !current) {
+			return !current
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineMode(t *testing.T) {
+	t.Run("initial state", func(t *testing.T) {
+		assert.True(t, cache.NewOfflineMode(true).Enabled())
+		assert.False(t, cache.NewOfflineMode(false).Enabled())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		mode := cache.NewOfflineMode(false)
+
+		mode.Set(true)
+		assert.True(t, mode.Enabled())
+
+		mode.Set(false)
+		assert.False(t, mode.Enabled())
+	})
+
+	t.Run("toggle", func(t *testing.T) {
+		mode := cache.NewOfflineMode(false)
+
+		assert.True(t, mode.Toggle())
+		assert.True(t, mode.Enabled())
+
+		assert.False(t, mode.Toggle())
+		assert.False(t, mode.Enabled())
+	})
+}
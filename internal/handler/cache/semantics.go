@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/go-http-utils/headers"
+)
+
+const (
+	directiveNoStore              = "no-store"
+	directiveNoCache              = "no-cache"
+	directivePrivate              = "private"
+	directiveMaxAge               = "max-age"
+	directiveSharedMaxAge         = "s-maxage"
+	directiveStaleWhileRevalidate = "stale-while-revalidate"
+
+	varyAll = "*"
+)
+
+type cacheControl map[string]string
+
+// parseCacheControl splits a Cache-Control header into lower-cased directives
+// and their unquoted values.
+func parseCacheControl(values []string) cacheControl {
+	directives := cacheControl{}
+
+	for _, value := range values {
+		for part := range strings.SplitSeq(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+
+			directives[strings.ToLower(name)] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+
+	return directives
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+
+	return ok
+}
+
+func (c cacheControl) seconds(directive string) (time.Duration, bool) {
+	value, ok := c[directive]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// freshnessLifetime returns how long the response may be served without
+// revalidation. s-maxage wins over max-age because uncors acts as a shared
+// cache; responses without explicit lifetime use the configured TTL.
+func (c cacheControl) freshnessLifetime(defaultTTL time.Duration) time.Duration {
+	if c.has(directiveNoCache) {
+		return 0
+	}
+
+	if lifetime, ok := c.seconds(directiveSharedMaxAge); ok {
+		return lifetime
+	}
+
+	if lifetime, ok := c.seconds(directiveMaxAge); ok {
+		return lifetime
+	}
+
+	return defaultTTL
+}
+
+func (c cacheControl) staleWhileRevalidate() time.Duration {
+	if c.has(directiveNoCache) {
+		return 0
+	}
+
+	window, _ := c.seconds(directiveStaleWhileRevalidate)
+
+	return window
+}
+
+// isStorable reports whether the upstream allows a shared cache to keep the
+// response.
+func isStorable(header http.Header) bool {
+	directives := parseCacheControl(header.Values(headers.CacheControl))
+	if directives.has(directiveNoStore) || directives.has(directivePrivate) {
+		return false
+	}
+
+	return !slices.Contains(varyHeaders(header), varyAll)
+}
+
+// varyHeaders returns the canonical names of request headers listed in Vary.
+func varyHeaders(header http.Header) []string {
+	var names []string
+
+	for _, value := range header.Values(headers.Vary) {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			if name != varyAll {
+				name = http.CanonicalHeaderKey(name)
+			}
+
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func cachedHeaderValues(response *contracts.CachedResponse, name string) []string {
+	name = http.CanonicalHeaderKey(name)
+
+	for _, header := range response.Headers {
+		if http.CanonicalHeaderKey(header.Name) == name {
+			return header.Value
+		}
+	}
+
+	return nil
+}
+
+func cachedHeaderValue(response *contracts.CachedResponse, name string) string {
+	values := cachedHeaderValues(response, name)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+type freshness int
+
+const (
+	fresh freshness = iota
+	staleWhileRevalidate
+	stale
+)
+
+func entryFreshness(response *contracts.CachedResponse, now time.Time, defaultTTL time.Duration) freshness {
+	directives := parseCacheControl(cachedHeaderValues(response, headers.CacheControl))
+	expiresAt := response.StoredAt.Add(directives.freshnessLifetime(defaultTTL))
+
+	switch {
+	case now.Before(expiresAt):
+		return fresh
+	case now.Before(expiresAt.Add(directives.staleWhileRevalidate())):
+		return staleWhileRevalidate
+	default:
+		return stale
+	}
+}
+
+// conditionalRequest clones the request and adds validators of the cached
+// response so the upstream can answer with 304 Not Modified.
+func conditionalRequest(request *contracts.Request, response *contracts.CachedResponse) *contracts.Request {
+	conditional := request.Clone(request.Context())
+	conditional.Header.Del(headers.IfNoneMatch)
+	conditional.Header.Del(headers.IfModifiedSince)
+
+	if etag := cachedHeaderValue(response, headers.ETag); etag != "" {
+		conditional.Header.Set(headers.IfNoneMatch, etag)
+	}
+
+	if lastModified := cachedHeaderValue(response, headers.LastModified); lastModified != "" {
+		conditional.Header.Set(headers.IfModifiedSince, lastModified)
+	}
+
+	return conditional
+}
+
+// refreshResponse applies headers of a 304 response to the cached entry as
+// described in RFC 9111 section 4.3.4.
+func refreshResponse(
+	response *contracts.CachedResponse,
+	notModified http.Header,
+	now time.Time,
+) contracts.CachedResponse {
+	refreshed := contracts.CachedResponse{
+		Code:     response.Code,
+		Body:     response.Body,
+		Headers:  make([]contracts.CachedHeader, 0, len(response.Headers)),
+		StoredAt: now,
+	}
+
+	for _, header := range response.Headers {
+		if values, ok := notModified[http.CanonicalHeaderKey(header.Name)]; ok {
+			refreshed.Headers = append(refreshed.Headers, contracts.CachedHeader{Name: header.Name, Value: values})
+
+			continue
+		}
+
+		refreshed.Headers = append(refreshed.Headers, header)
+	}
+
+	return refreshed
+}
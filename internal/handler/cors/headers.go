@@ -0,0 +1,154 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/go-http-utils/headers"
+)
+
+const (
+	defaultAllowMethods = "GET, PUT, POST, HEAD, TRACE, DELETE, PATCH, COPY, HEAD, LINK, OPTIONS"
+	defaultMaxAge       = 24 * time.Hour
+	wildcard            = "*"
+)
+
+type corsKeyType string
+
+const ConfigKey corsKeyType = "__uncors_cors_config"
+
+var defaultConfig = config.CORSConfig{}
+
+var responseHeaders = []string{
+	headers.AccessControlAllowOrigin,
+	headers.AccessControlAllowCredentials,
+	headers.AccessControlAllowHeaders,
+	headers.AccessControlAllowMethods,
+	headers.AccessControlMaxAge,
+	headers.AccessControlExposeHeaders,
+}
+
+// GetConfig returns the CORS policy attached to the request by Middleware,
+// or the permissive default policy when there is none.
+func GetConfig(request *contracts.Request) *config.CORSConfig {
+	if cfg, ok := request.Context().Value(ConfigKey).(*config.CORSConfig); ok && cfg != nil {
+		return cfg
+	}
+
+	return &defaultConfig
+}
+
+// IsPassthrough reports whether CORS headers of the upstream must be kept
+// untouched for the request.
+func IsPassthrough(request *contracts.Request) bool {
+	return GetConfig(request).Passthrough
+}
+
+// WriteHeaders writes CORS headers for a regular (non-preflight) response.
+func WriteHeaders(header http.Header, request *contracts.Request) {
+	cfg := GetConfig(request)
+	if cfg.Passthrough {
+		return
+	}
+
+	writeHeaders(header, request, cfg,
+		joinOrDefault(cfg.AllowedHeaders, wildcard),
+		joinOrDefault(cfg.AllowedMethods, defaultAllowMethods),
+	)
+}
+
+// WritePreflightHeaders writes CORS headers for an OPTIONS preflight response.
+// Unless pinned by the policy, the requested headers and method are allowed.
+func WritePreflightHeaders(header http.Header, request *contracts.Request) {
+	cfg := GetConfig(request)
+	if cfg.Passthrough {
+		return
+	}
+
+	writeHeaders(header, request, cfg,
+		joinOrDefault(cfg.AllowedHeaders, valueOrDefault(request.Header, headers.AccessControlRequestHeaders, wildcard)),
+		joinOrDefault(cfg.AllowedMethods, valueOrDefault(request.Header, headers.AccessControlRequestMethod, defaultAllowMethods)),
+	)
+}
+
+func writeHeaders(header http.Header, request *contracts.Request, cfg *config.CORSConfig, allowHeaders, allowMethods string) {
+	for _, name := range responseHeaders {
+		header.Del(name)
+	}
+
+	origin, allowed := allowedOrigin(cfg, request.Header.Get(headers.Origin))
+	if len(cfg.AllowedOrigins) > 0 {
+		header.Add(headers.Vary, headers.Origin)
+	}
+
+	if !allowed {
+		return
+	}
+
+	header.Set(headers.AccessControlAllowOrigin, origin)
+
+	if cfg.CredentialsAllowed() {
+		header.Set(headers.AccessControlAllowCredentials, "true")
+	}
+
+	header.Set(headers.AccessControlAllowHeaders, allowHeaders)
+	header.Set(headers.AccessControlAllowMethods, allowMethods)
+	header.Set(headers.AccessControlMaxAge, maxAge(cfg))
+	header.Set(headers.AccessControlExposeHeaders, joinOrDefault(cfg.ExposedHeaders, wildcard))
+}
+
+func allowedOrigin(cfg *config.CORSConfig, origin string) (string, bool) {
+	if len(cfg.AllowedOrigins) == 0 {
+		if origin == "" {
+			return wildcard, true
+		}
+
+		return origin, true
+	}
+
+	if origin == "" {
+		return "", false
+	}
+
+	for _, pattern := range cfg.AllowedOrigins {
+		if pattern == wildcard || strings.EqualFold(pattern, origin) {
+			return origin, true
+		}
+
+		if matched, _ := doublestar.Match(strings.ToLower(pattern), strings.ToLower(origin)); matched {
+			return origin, true
+		}
+	}
+
+	return "", false
+}
+
+func maxAge(cfg *config.CORSConfig) string {
+	value := cfg.MaxAge
+	if value == 0 {
+		value = defaultMaxAge
+	}
+
+	return strconv.Itoa(int(value.Seconds()))
+}
+
+func joinOrDefault(values []string, defaultValue string) string {
+	if len(values) == 0 {
+		return defaultValue
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func valueOrDefault(header http.Header, name, defaultValue string) string {
+	if value := header.Get(name); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
@@ -1,10 +1,11 @@
-package infra_test
+package cors_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
-	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/testing/hosts"
 	"github.com/go-http-utils/headers"
 	"github.com/stretchr/testify/assert"
@@ -12,7 +13,7 @@ import (
 
 const expectedAllowMethods = "GET, PUT, POST, HEAD, TRACE, DELETE, PATCH, COPY, HEAD, LINK, OPTIONS"
 
-func TestWriteCorsHeaders(t *testing.T) {
+func TestWriteHeaders(t *testing.T) {
 	tests := []struct {
 		name     string
 		header   http.Header
@@ -81,14 +82,19 @@ func TestWriteCorsHeaders(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			infra.WriteCorsHeaders(tt.header, tt.origin)
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				request.Header.Set(headers.Origin, tt.origin)
+			}
+
+			cors.WriteHeaders(tt.header, request)
 
 			assert.Equal(t, tt.expected, tt.header)
 		})
 	}
 }
 
-func TestWriteCorsHeadersForOptions(t *testing.T) {
+func TestWritePreflightHeaders(t *testing.T) {
 	testAllowHeaders := "Content-Type, Authorization"
 	xCustomHeader := "X-Custom-Header"
 
@@ -217,8 +223,11 @@ func TestWriteCorsHeadersForOptions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodOptions, "/", nil)
+			request.Header = tt.reqHeader
+
 			respHeader := http.Header{}
-			infra.WriteCorsHeadersForOptions(respHeader, tt.reqHeader)
+			cors.WritePreflightHeaders(respHeader, request)
 
 			assert.Equal(t, tt.expectedHeaders, respHeader)
 		})
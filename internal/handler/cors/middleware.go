@@ -0,0 +1,37 @@
+package cors
+
+import (
+	"context"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+)
+
+// Middleware attaches the CORS policy of a mapping to the request so that
+// handlers further down the chain write headers according to it.
+type Middleware struct {
+	config *config.CORSConfig
+}
+
+func NewMiddleware(options ...MiddlewareOption) *Middleware {
+	middleware := helpers.ApplyOptions(&Middleware{}, options)
+
+	helpers.AssertIsDefined(middleware.config, "CORS config is not defined")
+
+	return middleware
+}
+
+func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request, next contracts.Next) error {
+	return next(writer, request.WithContext(
+		context.WithValue(request.Context(), ConfigKey, m.config),
+	))
+}
+
+type MiddlewareOption = func(*Middleware)
+
+func WithConfig(cfg *config.CORSConfig) MiddlewareOption {
+	return func(m *Middleware) {
+		m.config = cfg
+	}
+}
@@ -0,0 +1,213 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	appOrigin   = "https://app.example.com"
+	otherOrigin = "https://evil.com"
+)
+
+// withPolicy passes request through the middleware and returns the request
+// seen by the next handler.
+func withPolicy(t *testing.T, cfg *config.CORSConfig, request *http.Request) *http.Request {
+	t.Helper()
+
+	var captured *http.Request
+
+	middleware := cors.NewMiddleware(cors.WithConfig(cfg))
+	err := middleware.ServeHTTP(
+		server.NewResponseRecorder(httptest.NewRecorder()),
+		request,
+		func(_ contracts.ResponseWriter, r *contracts.Request) error {
+			captured = r
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, captured)
+
+	return captured
+}
+
+func newRequest(t *testing.T, method, origin string) *http.Request {
+	t.Helper()
+
+	request := httptest.NewRequestWithContext(t.Context(), method, "/api", nil)
+	if origin != "" {
+		request.Header.Set(headers.Origin, origin)
+	}
+
+	return request
+}
+
+func TestNewMiddleware(t *testing.T) {
+	t.Run("panics without config", func(t *testing.T) {
+		assert.Panics(t, func() {
+			cors.NewMiddleware()
+		})
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("attaches config to request", func(t *testing.T) {
+		cfg := &config.CORSConfig{Passthrough: true}
+
+		request := withPolicy(t, cfg, newRequest(t, http.MethodGet, appOrigin))
+
+		assert.Same(t, cfg, cors.GetConfig(request))
+		assert.True(t, cors.IsPassthrough(request))
+	})
+
+	t.Run("falls back to default config", func(t *testing.T) {
+		request := newRequest(t, http.MethodGet, appOrigin)
+
+		assert.Equal(t, &config.CORSConfig{}, cors.GetConfig(request))
+		assert.False(t, cors.IsPassthrough(request))
+	})
+}
+
+func TestWriteHeadersWithPolicy(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name     string
+		cfg      config.CORSConfig
+		origin   string
+		header   http.Header
+		expected http.Header
+	}{
+		{
+			name:   "allowed origin is reflected",
+			cfg:    config.CORSConfig{AllowedOrigins: []string{appOrigin}},
+			origin: appOrigin,
+			header: http.Header{},
+			expected: http.Header{
+				headers.Vary:                          []string{headers.Origin},
+				headers.AccessControlAllowOrigin:      []string{appOrigin},
+				headers.AccessControlAllowCredentials: []string{"true"},
+				headers.AccessControlAllowHeaders:     []string{"*"},
+				headers.AccessControlAllowMethods:     []string{expectedAllowMethods},
+				headers.AccessControlMaxAge:           []string{"86400"},
+				headers.AccessControlExposeHeaders:    []string{"*"},
+			},
+		},
+		{
+			name:   "origin matched by glob is reflected",
+			cfg:    config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			origin: appOrigin,
+			header: http.Header{},
+			expected: http.Header{
+				headers.Vary:                          []string{headers.Origin},
+				headers.AccessControlAllowOrigin:      []string{appOrigin},
+				headers.AccessControlAllowCredentials: []string{"true"},
+				headers.AccessControlAllowHeaders:     []string{"*"},
+				headers.AccessControlAllowMethods:     []string{expectedAllowMethods},
+				headers.AccessControlMaxAge:           []string{"86400"},
+				headers.AccessControlExposeHeaders:    []string{"*"},
+			},
+		},
+		{
+			name:   "not allowed origin gets no cors headers",
+			cfg:    config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			origin: otherOrigin,
+			header: http.Header{
+				headers.AccessControlAllowOrigin: []string{otherOrigin},
+			},
+			expected: http.Header{
+				headers.Vary: []string{headers.Origin},
+			},
+		},
+		{
+			name:   "request without origin gets no cors headers when origins are pinned",
+			cfg:    config.CORSConfig{AllowedOrigins: []string{appOrigin}},
+			header: http.Header{},
+			expected: http.Header{
+				headers.Vary: []string{headers.Origin},
+			},
+		},
+		{
+			name: "pinned policy values are written",
+			cfg: config.CORSConfig{
+				AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+				AllowedHeaders:   []string{"Content-Type", "Authorization"},
+				ExposedHeaders:   []string{"X-Request-Id"},
+				AllowCredentials: &disabled,
+				MaxAge:           10 * time.Minute,
+			},
+			origin: appOrigin,
+			header: http.Header{
+				headers.AccessControlAllowCredentials: []string{"true"},
+			},
+			expected: http.Header{
+				headers.AccessControlAllowOrigin:   []string{appOrigin},
+				headers.AccessControlAllowHeaders:  []string{"Content-Type, Authorization"},
+				headers.AccessControlAllowMethods:  []string{"GET, POST"},
+				headers.AccessControlMaxAge:        []string{"600"},
+				headers.AccessControlExposeHeaders: []string{"X-Request-Id"},
+			},
+		},
+		{
+			name:   "passthrough keeps existing headers untouched",
+			cfg:    config.CORSConfig{Passthrough: true},
+			origin: appOrigin,
+			header: http.Header{
+				headers.AccessControlAllowOrigin: []string{"https://prod.example.com"},
+			},
+			expected: http.Header{
+				headers.AccessControlAllowOrigin: []string{"https://prod.example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := withPolicy(t, &tt.cfg, newRequest(t, http.MethodGet, tt.origin))
+
+			cors.WriteHeaders(tt.header, request)
+
+			assert.Equal(t, tt.expected, tt.header)
+		})
+	}
+}
+
+func TestWritePreflightHeadersWithPolicy(t *testing.T) {
+	t.Run("pinned methods and headers override requested ones", func(t *testing.T) {
+		cfg := &config.CORSConfig{
+			AllowedMethods: []string{http.MethodGet},
+			AllowedHeaders: []string{"Content-Type"},
+		}
+
+		request := newRequest(t, http.MethodOptions, appOrigin)
+		request.Header.Set(headers.AccessControlRequestMethod, http.MethodDelete)
+		request.Header.Set(headers.AccessControlRequestHeaders, "X-Custom")
+
+		header := http.Header{}
+		cors.WritePreflightHeaders(header, withPolicy(t, cfg, request))
+
+		assert.Equal(t, "GET", header.Get(headers.AccessControlAllowMethods))
+		assert.Equal(t, "Content-Type", header.Get(headers.AccessControlAllowHeaders))
+	})
+
+	t.Run("passthrough writes nothing", func(t *testing.T) {
+		request := withPolicy(t, &config.CORSConfig{Passthrough: true}, newRequest(t, http.MethodOptions, appOrigin))
+
+		header := http.Header{}
+		cors.WritePreflightHeaders(header, request)
+
+		assert.Empty(t, header)
+	})
+}
@@ -15,35 +15,11 @@ func buildContent(raw []byte, contentEncoding, mimeType string) Content {
 		return Content{Size: 0, MimeType: mimeType}
 	}
 
-	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
-
-	switch encoding {
-	case "gzip", "x-gzip":
-		decoded, err := decodeGzip(raw)
-		if err == nil {
-			return Content{
-				Size:     int64(len(decoded)),
-				MimeType: mimeType,
-				Text:     string(decoded),
-			}
-		}
-
-	case "deflate":
-		decoded, err := decodeDeflate(raw)
-		if err == nil {
-			return Content{
-				Size:     int64(len(decoded)),
-				MimeType: mimeType,
-				Text:     string(decoded),
-			}
-		}
-
-	case "", "identity":
-		// No compression — store as plain text.
+	if decoded, ok := DecodeBody(raw, contentEncoding); ok {
 		return Content{
-			Size:     int64(len(raw)),
+			Size:     int64(len(decoded)),
 			MimeType: mimeType,
-			Text:     string(raw),
+			Text:     string(decoded),
 		}
 	}
 
@@ -56,6 +32,75 @@ func buildContent(raw []byte, contentEncoding, mimeType string) Content {
 	}
 }
 
+// DecodeBody decompresses a response body sent with contentEncoding. The
+// second result is false when the encoding is not supported or the body could
+// not be decompressed.
+func DecodeBody(raw []byte, contentEncoding string) ([]byte, bool) {
+	var (
+		decoded []byte
+		err     error
+	)
+
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return raw, true
+	case "gzip", "x-gzip":
+		decoded, err = decodeGzip(raw)
+	case "deflate":
+		decoded, err = decodeDeflate(raw)
+	default:
+		return nil, false
+	}
+
+	if err != nil {
+		return nil, false
+	}
+
+	return decoded, true
+}
+
+// DecodeContent returns the body bytes of a HAR content. Base64 text is
+// decoded and bodies that are still compressed with contentEncoding are
+// decompressed. The second result is false when the body could not be
+// decompressed and still uses contentEncoding.
+func DecodeContent(content Content, contentEncoding string) ([]byte, bool, error) {
+	raw := []byte(content.Text)
+
+	if content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(content.Text)
+		if err != nil {
+			return nil, false, err
+		}
+
+		raw = decoded
+	}
+
+	var (
+		decoded []byte
+		err     error
+	)
+
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return raw, true, nil
+	case "gzip", "x-gzip":
+		decoded, err = decodeGzip(raw)
+	case "deflate":
+		decoded, err = decodeDeflate(raw)
+	default:
+		// Browsers export decoded text; base64 bodies with an unknown
+		// encoding are kept as they were received.
+		return raw, content.Encoding != "base64", nil
+	}
+
+	if err != nil {
+		// Not compressed, the exporter already decoded the body.
+		return raw, true, nil //nolint:nilerr
+	}
+
+	return decoded, true, nil
+}
+
 func decodeGzip(data []byte) ([]byte, error) {
 	reader, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
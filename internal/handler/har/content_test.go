@@ -1,11 +1,14 @@
 package har_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"testing"
 
 	"github.com/evg4b/uncors/internal/handler/har"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestContent_Encoding(t *testing.T) {
@@ -27,3 +30,80 @@ func TestContent_Encoding(t *testing.T) {
 		assert.Equal(t, b64, entry.Response.Content.Text)
 	})
 }
+
+func TestDecodeContent(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	tests := []struct {
+		name            string
+		content         har.Content
+		contentEncoding string
+		expected        string
+		decoded         bool
+	}{
+		{
+			name:     "plain text",
+			content:  har.Content{Text: "hello"},
+			expected: "hello",
+			decoded:  true,
+		},
+		{
+			name:     "base64 text",
+			content:  har.Content{Text: base64.StdEncoding.EncodeToString([]byte("hello")), Encoding: "base64"},
+			expected: "hello",
+			decoded:  true,
+		},
+		{
+			name: "base64 gzip body",
+			content: har.Content{
+				Text:     base64.StdEncoding.EncodeToString(compressed.Bytes()),
+				Encoding: "base64",
+			},
+			contentEncoding: "gzip",
+			expected:        "hello",
+			decoded:         true,
+		},
+		{
+			name:            "gzip body already decoded by exporter",
+			content:         har.Content{Text: "hello"},
+			contentEncoding: "gzip",
+			expected:        "hello",
+			decoded:         true,
+		},
+		{
+			name:            "unknown encoding of base64 body",
+			content:         har.Content{Text: base64.StdEncoding.EncodeToString([]byte{0x01}), Encoding: "base64"},
+			contentEncoding: "br",
+			expected:        "\x01",
+			decoded:         false,
+		},
+		{
+			name:            "unknown encoding of text body",
+			content:         har.Content{Text: "hello"},
+			contentEncoding: "br",
+			expected:        "hello",
+			decoded:         true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			body, decoded, err := har.DecodeContent(testCase.content, testCase.contentEncoding)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(body))
+			assert.Equal(t, testCase.decoded, decoded)
+		})
+	}
+
+	t.Run("invalid base64", func(t *testing.T) {
+		_, _, err := har.DecodeContent(har.Content{Text: "%%%", Encoding: "base64"}, "")
+
+		require.Error(t, err)
+	})
+}
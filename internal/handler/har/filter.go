@@ -0,0 +1,137 @@
+package har
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+)
+
+type statusRange struct {
+	from int
+	to   int
+}
+
+// filter is the compiled form of config.HARFilter.
+type filter struct {
+	paths        []string
+	methods      []string
+	statuses     []statusRange
+	contentTypes []string
+}
+
+func newFilter(cfg config.HARFilter) *filter {
+	if cfg.Empty() {
+		return nil
+	}
+
+	result := &filter{
+		paths:        cfg.Paths,
+		methods:      cfg.Methods,
+		contentTypes: cfg.ContentTypes,
+	}
+
+	for _, status := range cfg.Statuses {
+		from, to, err := config.ParseStatusRange(status)
+		if err != nil {
+			continue
+		}
+
+		result.statuses = append(result.statuses, statusRange{from: from, to: to})
+	}
+
+	return result
+}
+
+// hasResponseCriteria reports whether the filter can only be evaluated once
+// the response is known.
+func (f *filter) hasResponseCriteria() bool {
+	return len(f.statuses) > 0 || len(f.contentTypes) > 0
+}
+
+func (f *filter) matchesRequest(req *http.Request) bool {
+	if len(f.methods) > 0 && !containsFold(f.methods, req.Method) {
+		return false
+	}
+
+	if len(f.paths) == 0 {
+		return true
+	}
+
+	for _, pattern := range f.paths {
+		if ok, _ := doublestar.PathMatch(pattern, req.URL.Path); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *filter) matchesResponse(status int, contentType string) bool {
+	if len(f.statuses) > 0 && !f.matchesStatus(status) {
+		return false
+	}
+
+	if len(f.contentTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	for _, pattern := range f.contentTypes {
+		if ok, _ := doublestar.Match(strings.ToLower(pattern), mediaType); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *filter) matchesStatus(status int) bool {
+	for _, statusRange := range f.statuses {
+		if status >= statusRange.from && status <= statusRange.to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipRequest reports whether the request is not recorded regardless of the
+// response, so the middleware does not need to capture it at all.
+func (m *Middleware) skipRequest(req *http.Request) bool {
+	if m.include != nil && !m.include.matchesRequest(req) {
+		return true
+	}
+
+	return m.exclude != nil && !m.exclude.hasResponseCriteria() && m.exclude.matchesRequest(req)
+}
+
+// skipResponse reports whether the entry is dropped after the response is
+// known. Requests that passed skipRequest already match the request criteria
+// of the include filter.
+func (m *Middleware) skipResponse(req *http.Request, capture contracts.ResponseCapture) bool {
+	contentType := capture.Header.Get("Content-Type")
+
+	if m.include != nil && !m.include.matchesResponse(capture.StatusCode, contentType) {
+		return true
+	}
+
+	return m.exclude != nil && m.exclude.matchesRequest(req) && m.exclude.matchesResponse(capture.StatusCode, contentType)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
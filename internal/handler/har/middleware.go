@@ -1,19 +1,27 @@
 package har
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/helpers"
 	"github.com/evg4b/uncors/pkg/urlt"
 )
 
-const nanosecondsPerMillisecond = 1e6
+const (
+	nanosecondsPerMillisecond = 1e6
+	truncatedComment          = "body truncated at capture size limit"
+	bodySizeComment           = "body truncated at max-body-size"
+)
 
 var secureHeaderNames = map[string]bool{
 	"Cookie":              true,
@@ -27,6 +35,10 @@ var secureHeaderNames = map[string]bool{
 type Middleware struct {
 	writer               *Writer
 	captureSecureHeaders bool
+	include              *filter
+	exclude              *filter
+	maxBodySize          int64
+	redactor             *redactor
 }
 
 func NewMiddleware(opts ...MiddlewareOption) *Middleware {
@@ -39,26 +51,39 @@ func NewMiddleware(opts ...MiddlewareOption) *Middleware {
 }
 
 func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, req *contracts.Request, next contracts.Next) error {
+	if m.skipRequest(req) {
+		return next(writer, req)
+	}
+
 	start := time.Now()
 
-	var reqBodySize int64
+	var reqBody string
 
 	if req.Body != nil && req.Body != http.NoBody {
 		var buf strings.Builder
 
-		n, _ := io.Copy(&buf, req.Body)
-		reqBodySize = n
+		_, _ = io.Copy(&buf, req.Body)
+		reqBody = buf.String()
 		_ = req.Body.Close()
 
-		req.Body = io.NopCloser(strings.NewReader(buf.String()))
+		req.Body = io.NopCloser(strings.NewReader(reqBody))
 	}
 
+	upstream := &contracts.UpstreamTrace{}
+	req = req.WithContext(context.WithValue(req.Context(), contracts.UpstreamKey, upstream))
+
 	writer.EnableBodyCapture()
 
 	err := next(writer, req)
 
 	elapsed := time.Since(start)
-	entry := m.buildEntry(req, writer.Captured(), start, elapsed, reqBodySize)
+
+	capture := writer.Captured()
+	if m.skipResponse(req, capture) {
+		return err
+	}
+
+	entry := m.buildEntry(req, capture, start, elapsed, reqBody, upstream)
 	m.writer.AddEntry(entry)
 
 	return err
@@ -69,24 +94,36 @@ func (m *Middleware) buildEntry(
 	capture contracts.ResponseCapture,
 	start time.Time,
 	elapsed time.Duration,
-	reqBodySize int64,
+	reqBody string,
+	upstream *contracts.UpstreamTrace,
 ) Entry {
-	elapsedMS := float64(elapsed.Nanoseconds()) / nanosecondsPerMillisecond
-
-	return Entry{
+	entry := Entry{
 		StartedDateTime: start,
-		Time:            elapsedMS,
-		Request:         m.buildRequest(req, reqBodySize),
+		Time:            milliseconds(elapsed),
+		Request:         m.buildRequest(req, reqBody),
 		Response:        m.buildResponse(capture),
-		Timings: Timings{
-			Send:    0,
-			Wait:    elapsedMS,
-			Receive: 0,
-		},
+		Timings:         buildTimings(elapsed, upstream),
+	}
+
+	if upstream.WroteRequest.IsZero() {
+		return entry
 	}
+
+	entry.ServerIPAddress = upstream.ServerIPAddress
+	if _, port, err := net.SplitHostPort(upstream.LocalAddress); err == nil {
+		entry.Connection = port
+	}
+
+	entry.Upstream = &Upstream{
+		Method:  upstream.Method,
+		URL:     m.redactURL(upstream.URL),
+		Headers: m.headersToNameValues(upstream.Header),
+	}
+
+	return entry
 }
 
-func (m *Middleware) buildRequest(req *http.Request, bodySize int64) Request {
+func (m *Middleware) buildRequest(req *http.Request, body string) Request {
 	scheme := "http"
 	if req.TLS != nil {
 		scheme = "https"
@@ -97,19 +134,40 @@ func (m *Middleware) buildRequest(req *http.Request, bodySize int64) Request {
 	var cookies []Cookie
 
 	if m.captureSecureHeaders {
-		cookies = cookiesToHAR(req.Cookies())
+		cookies = m.cookiesToHAR("Cookie", req.Cookies())
 	}
 
 	return Request{
 		Method:      req.Method,
-		URL:         fullURL,
+		URL:         m.redactURL(fullURL),
 		HTTPVersion: req.Proto,
 		Headers:     m.headersToNameValues(req.Header),
-		QueryString: queryToNameValues(urlt.URL_Query(req.URL)),
+		QueryString: m.queryToNameValues(urlt.URL_Query(req.URL)),
 		Cookies:     cookies,
 		HeadersSize: -1,
-		BodySize:    bodySize,
+		BodySize:    int64(len(body)),
+		PostData:    m.buildPostData(req.Header.Get("Content-Type"), body),
+	}
+}
+
+// buildPostData records text request bodies. Binary bodies are only counted
+// in the request body size.
+func (m *Middleware) buildPostData(mimeType, body string) *PostData {
+	if body == "" || !utf8.ValidString(body) {
+		return nil
+	}
+
+	if m.redactor != nil {
+		body = m.redactor.body(body)
+	}
+
+	postData := &PostData{MimeType: mimeType, Text: body}
+	if text, cut := m.limitText(body); cut {
+		postData.Text = text
+		postData.Comment = bodySizeComment
 	}
+
+	return postData
 }
 
 func (m *Middleware) buildResponse(capture contracts.ResponseCapture) Response {
@@ -121,12 +179,21 @@ func (m *Middleware) buildResponse(capture contracts.ResponseCapture) Response {
 	var cookies []Cookie
 
 	if m.captureSecureHeaders {
-		cookies = cookiesToHAR(extractResponseCookies(capture.Header))
+		cookies = m.cookiesToHAR("Set-Cookie", extractResponseCookies(capture.Header))
 	}
 
 	rawBody := capture.Body
 	content := buildContent(rawBody, capture.Header.Get("Content-Encoding"), mimeType)
 
+	if m.redactor != nil && content.Encoding == "" {
+		content.Text = m.redactor.body(content.Text)
+	}
+
+	content = m.limitContent(content)
+	if capture.Truncated {
+		content.Comment = truncatedComment
+	}
+
 	return Response{
 		Status:      capture.StatusCode,
 		StatusText:  http.StatusText(capture.StatusCode),
@@ -134,7 +201,7 @@ func (m *Middleware) buildResponse(capture contracts.ResponseCapture) Response {
 		Headers:     m.headersToNameValues(capture.Header),
 		Cookies:     cookies,
 		Content:     content,
-		RedirectURL: capture.Header.Get("Location"),
+		RedirectURL: m.redactURL(capture.Header.Get("Location")),
 		HeadersSize: -1,
 		BodySize:    int64(len(rawBody)),
 	}
@@ -149,6 +216,10 @@ func (m *Middleware) headersToNameValues(h http.Header) []NameValue {
 		}
 
 		for _, v := range values {
+			if m.redactor != nil {
+				v = m.redactor.header(name, v)
+			}
+
 			result = append(result, NameValue{Name: name, Value: v})
 		}
 	}
@@ -156,11 +227,15 @@ func (m *Middleware) headersToNameValues(h http.Header) []NameValue {
 	return result
 }
 
-func queryToNameValues(q url.Values) []NameValue {
+func (m *Middleware) queryToNameValues(q url.Values) []NameValue {
 	result := make([]NameValue, 0, len(q))
 
 	for k, vals := range q {
 		for _, v := range vals {
+			if m.redactor != nil {
+				v = m.redactor.queryValue(k, v)
+			}
+
 			result = append(result, NameValue{Name: k, Value: v})
 		}
 	}
@@ -168,16 +243,67 @@ func queryToNameValues(q url.Values) []NameValue {
 	return result
 }
 
-func cookiesToHAR(cookies []*http.Cookie) []Cookie {
+// cookiesToHAR converts cookies sent in the header with the given name.
+// Cookie values are masked when that header is redacted.
+func (m *Middleware) cookiesToHAR(header string, cookies []*http.Cookie) []Cookie {
 	result := make([]Cookie, 0, len(cookies))
 
 	for _, c := range cookies {
-		result = append(result, Cookie{Name: c.Name, Value: c.Value})
+		value := c.Value
+		if m.redactor != nil {
+			value = m.redactor.header(header, value)
+		}
+
+		result = append(result, Cookie{Name: c.Name, Value: value})
 	}
 
 	return result
 }
 
+func (m *Middleware) redactURL(raw string) string {
+	if m.redactor == nil || raw == "" {
+		return raw
+	}
+
+	return m.redactor.url(raw)
+}
+
+// limitContent cuts the response body to the configured size. Base64 bodies
+// are cut before encoding so they stay decodable.
+func (m *Middleware) limitContent(content Content) Content {
+	if content.Encoding != "base64" {
+		if text, cut := m.limitText(content.Text); cut {
+			content.Text = text
+			content.Comment = bodySizeComment
+		}
+
+		return content
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(content.Text)
+	if err == nil && m.maxBodySize > 0 && int64(len(raw)) > m.maxBodySize {
+		content.Text = base64.StdEncoding.EncodeToString(raw[:m.maxBodySize])
+		content.Comment = bodySizeComment
+	}
+
+	return content
+}
+
+// limitText cuts text to the configured size without splitting a UTF-8
+// character.
+func (m *Middleware) limitText(text string) (string, bool) {
+	if m.maxBodySize <= 0 || int64(len(text)) <= m.maxBodySize {
+		return text, false
+	}
+
+	size := int(m.maxBodySize)
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+
+	return text[:size], true
+}
+
 func extractResponseCookies(h http.Header) []*http.Cookie {
 	resp := &http.Response{Header: h}
 
@@ -15,7 +15,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/handler/har"
 	"github.com/evg4b/uncors/internal/infra"
@@ -123,6 +125,31 @@ func TestMiddleware_Wrap(t *testing.T) {
 		assert.Equal(t, "hello", rec.Body.String())
 	})
 
+	t.Run("marks body truncated at capture limit", func(t *testing.T) {
+		mdlw, harWriter, path := newHARMiddleware(t)
+
+		next := infra.HandlerFunc(func(rw contracts.ResponseWriter, _ *contracts.Request) error {
+			rw.Header().Set("Content-Type", "text/event-stream")
+			rw.WriteHeader(http.StatusOK)
+			fmt.Fprint(rw, strings.Repeat("a", server.MaxCaptureSize+1))
+
+			return nil
+		})
+
+		rr := server.NewResponseRecorder(httptest.NewRecorder())
+		err := infra.Mddleware(mdlw, next).ServeHTTP(rr, makeHARRequest(t, "http://example.com/events"))
+		require.NoError(t, err)
+
+		require.NoError(t, harWriter.Close())
+
+		archive := readHARFile(t, path)
+		require.Len(t, archive.Log.Entries, 1)
+
+		content := archive.Log.Entries[0].Response.Content
+		assert.Equal(t, int64(server.MaxCaptureSize), content.Size)
+		assert.NotEmpty(t, content.Comment)
+	})
+
 	t.Run("records request with query string", func(t *testing.T) {
 		mdlw, harWriter, _ := newHARMiddleware(t)
 
@@ -341,3 +368,338 @@ func TestNewMiddleware_NilWriterPanic(t *testing.T) {
 		func() { har.NewMiddleware() },
 	)
 }
+
+type harExchange struct {
+	method      string
+	url         string
+	body        string
+	header      http.Header
+	status      int
+	contentType string
+	response    string
+	respHeader  http.Header
+}
+
+func recordExchanges(t *testing.T, opts []har.MiddlewareOption, exchanges ...harExchange) []har.Entry {
+	t.Helper()
+
+	mdlw, harWriter, path := newHARMiddleware(t, opts...)
+
+	for _, exchange := range exchanges {
+		next := infra.HandlerFunc(func(rw contracts.ResponseWriter, _ *contracts.Request) error {
+			if exchange.contentType != "" {
+				rw.Header().Set("Content-Type", exchange.contentType)
+			}
+
+			rw.Header().Set("Set-Cookie", "session=secret")
+
+			for name, values := range exchange.respHeader {
+				rw.Header()[name] = values
+			}
+
+			rw.WriteHeader(exchange.status)
+			_, err := io.WriteString(rw, exchange.response)
+
+			return err
+		})
+
+		var body io.Reader = http.NoBody
+		if exchange.body != "" {
+			body = strings.NewReader(exchange.body)
+		}
+
+		req, err := http.NewRequestWithContext(context.Background(), exchange.method, exchange.url, body)
+		require.NoError(t, err)
+
+		req.RequestURI = req.URL.RequestURI()
+
+		for name, values := range exchange.header {
+			req.Header[name] = values
+		}
+
+		rr := server.NewResponseRecorder(httptest.NewRecorder())
+		require.NoError(t, infra.Mddleware(mdlw, next).ServeHTTP(rr, req))
+	}
+
+	require.NoError(t, harWriter.Close())
+
+	return readHARFile(t, path).Log.Entries
+}
+
+func TestMiddleware_Filters(t *testing.T) {
+	exchanges := []harExchange{
+		{method: http.MethodGet, url: "http://example.com/api/users", status: http.StatusOK, contentType: "application/json"},
+		{method: http.MethodPost, url: "http://example.com/api/users", status: http.StatusCreated, contentType: "application/json"},
+		{method: http.MethodGet, url: "http://example.com/api/users/7", status: http.StatusNotFound, contentType: "text/plain"},
+		{method: http.MethodGet, url: "http://example.com/health", status: http.StatusOK, contentType: "text/plain"},
+		{method: http.MethodGet, url: "http://example.com/logo.png", status: http.StatusOK, contentType: "image/png"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []har.MiddlewareOption
+		expected []string
+	}{
+		{
+			name: "no filters",
+			expected: []string{
+				"GET /api/users", "POST /api/users", "GET /api/users/7", "GET /health", "GET /logo.png",
+			},
+		},
+		{
+			name:     "include paths",
+			opts:     []har.MiddlewareOption{har.WithInclude(config.HARFilter{Paths: []string{"/api/**"}})},
+			expected: []string{"GET /api/users", "POST /api/users", "GET /api/users/7"},
+		},
+		{
+			name:     "include methods",
+			opts:     []har.MiddlewareOption{har.WithInclude(config.HARFilter{Methods: []string{"post"}})},
+			expected: []string{"POST /api/users"},
+		},
+		{
+			name:     "include status class",
+			opts:     []har.MiddlewareOption{har.WithInclude(config.HARFilter{Statuses: []string{"4xx"}})},
+			expected: []string{"GET /api/users/7"},
+		},
+		{
+			name: "include content types",
+			opts: []har.MiddlewareOption{
+				har.WithInclude(config.HARFilter{ContentTypes: []string{"application/json", "text/*"}}),
+			},
+			expected: []string{"POST /api/users", "GET /api/users", "GET /api/users/7", "GET /health"},
+		},
+		{
+			name:     "exclude paths",
+			opts:     []har.MiddlewareOption{har.WithExclude(config.HARFilter{Paths: []string{"/health", "/*.png"}})},
+			expected: []string{"GET /api/users", "POST /api/users", "GET /api/users/7"},
+		},
+		{
+			name: "exclude requires every criterion",
+			opts: []har.MiddlewareOption{
+				har.WithExclude(config.HARFilter{Paths: []string{"/api/**"}, Statuses: []string{"200-299"}}),
+			},
+			expected: []string{"GET /api/users/7", "GET /health", "GET /logo.png"},
+		},
+		{
+			name: "include and exclude",
+			opts: []har.MiddlewareOption{
+				har.WithInclude(config.HARFilter{Paths: []string{"/api/**"}}),
+				har.WithExclude(config.HARFilter{Methods: []string{http.MethodPost}}),
+			},
+			expected: []string{"GET /api/users", "GET /api/users/7"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			entries := recordExchanges(t, testCase.opts, exchanges...)
+
+			actual := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				actual = append(actual, entry.Request.Method+" "+strings.TrimPrefix(entry.Request.URL, "http://example.com"))
+			}
+
+			assert.ElementsMatch(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestMiddleware_Redaction(t *testing.T) {
+	redaction := config.HARRedaction{
+		Headers:    []string{"x-api-key", "Cookie", "Set-Cookie"},
+		Query:      []string{"access_token"},
+		JSONFields: []string{"password", "token"},
+		Patterns:   []string{`Bearer [A-Za-z0-9._-]+`},
+	}
+
+	entries := recordExchanges(t,
+		[]har.MiddlewareOption{har.WithCaptureSecureHeaders(true), har.WithRedaction(redaction)},
+		harExchange{
+			method: http.MethodPost,
+			url:    "http://example.com/login?access_token=abc&page=2",
+			body:   `{"user":"admin","password":"hunter2"}`,
+			header: http.Header{
+				"Content-Type":  {"application/json"},
+				"X-Api-Key":     {"key-123"},
+				"Authorization": {"Bearer eyJhbGciOi.payload"},
+				"Cookie":        {"session=secret"},
+			},
+			status:      http.StatusOK,
+			contentType: "application/json",
+			response:    `{"user":{"name":"admin","token":"t-1"},"items":[{"Token":"t-2"}]}`,
+		},
+	)
+	require.Len(t, entries, 1)
+
+	request := entries[0].Request
+	assert.Equal(t, "http://example.com/login?access_token=%5BREDACTED%5D&page=2", request.URL)
+	assert.ElementsMatch(t, []har.NameValue{
+		{Name: "access_token", Value: "[REDACTED]"},
+		{Name: "page", Value: "2"},
+	}, request.QueryString)
+	assert.Contains(t, request.Headers, har.NameValue{Name: "X-Api-Key", Value: "[REDACTED]"})
+	assert.Contains(t, request.Headers, har.NameValue{Name: "Authorization", Value: "[REDACTED]"})
+	assert.Contains(t, request.Headers, har.NameValue{Name: "Cookie", Value: "[REDACTED]"})
+	assert.Equal(t, []har.Cookie{{Name: "session", Value: "[REDACTED]"}}, request.Cookies)
+	require.NotNil(t, request.PostData)
+	assert.Equal(t, "application/json", request.PostData.MimeType)
+	assert.JSONEq(t, `{"user":"admin","password":"[REDACTED]"}`, request.PostData.Text)
+
+	response := entries[0].Response
+	assert.JSONEq(t,
+		`{"user":{"name":"admin","token":"[REDACTED]"},"items":[{"Token":"[REDACTED]"}]}`,
+		response.Content.Text,
+	)
+	assert.Contains(t, response.Headers, har.NameValue{Name: "Set-Cookie", Value: "[REDACTED]"})
+	assert.Equal(t, []har.Cookie{{Name: "session", Value: "[REDACTED]"}}, response.Cookies)
+}
+
+func TestMiddleware_RedactionOfURLHeaders(t *testing.T) {
+	entries := recordExchanges(t,
+		[]har.MiddlewareOption{har.WithRedaction(config.HARRedaction{Query: []string{"access_token"}})},
+		harExchange{
+			method: http.MethodGet,
+			url:    "http://example.com/login",
+			header: http.Header{
+				"Referer": {"http://example.com/start?access_token=abc&page=2"},
+			},
+			status: http.StatusFound,
+			respHeader: http.Header{
+				"Location":         {"http://example.com/callback?access_token=abc&state=1"},
+				"Content-Location": {"/login?access_token=abc"},
+			},
+		},
+	)
+	require.Len(t, entries, 1)
+
+	request := entries[0].Request
+	assert.Contains(t, request.Headers, har.NameValue{
+		Name:  "Referer",
+		Value: "http://example.com/start?access_token=%5BREDACTED%5D&page=2",
+	})
+
+	response := entries[0].Response
+	assert.Equal(t, "http://example.com/callback?access_token=%5BREDACTED%5D&state=1", response.RedirectURL)
+	assert.Contains(t, response.Headers, har.NameValue{
+		Name:  "Location",
+		Value: "http://example.com/callback?access_token=%5BREDACTED%5D&state=1",
+	})
+	assert.Contains(t, response.Headers, har.NameValue{
+		Name:  "Content-Location",
+		Value: "/login?access_token=%5BREDACTED%5D",
+	})
+}
+
+func TestMiddleware_MaxBodySize(t *testing.T) {
+	entries := recordExchanges(t,
+		[]har.MiddlewareOption{har.WithMaxBodySize(4)},
+		harExchange{
+			method:      http.MethodPost,
+			url:         "http://example.com/upload",
+			body:        "abcdefgh",
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			response:    "ünïcode",
+		},
+		harExchange{
+			method:   http.MethodGet,
+			url:      "http://example.com/small",
+			status:   http.StatusOK,
+			response: "ok",
+		},
+	)
+	require.Len(t, entries, 2)
+
+	request := entries[0].Request
+	assert.Equal(t, int64(8), request.BodySize)
+	require.NotNil(t, request.PostData)
+	assert.Equal(t, "abcd", request.PostData.Text)
+	assert.NotEmpty(t, request.PostData.Comment)
+
+	content := entries[0].Response.Content
+	assert.Equal(t, "ün", content.Text)
+	assert.NotEmpty(t, content.Comment)
+
+	small := entries[1].Response.Content
+	assert.Equal(t, "ok", small.Text)
+	assert.Empty(t, small.Comment)
+}
+
+func TestMiddleware_Upstream(t *testing.T) {
+	t.Run("records upstream request of proxied entries", func(t *testing.T) {
+		mdlw, harWriter, path := newHARMiddleware(t, har.WithRedaction(config.HARRedaction{
+			Headers: []string{"X-Api-Key"},
+			Query:   []string{"access_token"},
+		}))
+
+		next := infra.HandlerFunc(func(rw contracts.ResponseWriter, r *contracts.Request) error {
+			trace, ok := r.Context().Value(contracts.UpstreamKey).(*contracts.UpstreamTrace)
+			require.True(t, ok)
+
+			now := time.Now()
+			*trace = contracts.UpstreamTrace{
+				Method: http.MethodGet,
+				URL:    "https://api.example.com/users?access_token=abc",
+				Header: http.Header{
+					"Host":          {"api.example.com"},
+					"X-Api-Key":     {"key"},
+					"Authorization": {"Bearer token"},
+				},
+				ServerIPAddress: "10.0.0.1",
+				LocalAddress:    "127.0.0.1:51234",
+				GotConn:         now,
+				WroteRequest:    now,
+				FirstByte:       now,
+				Done:            now,
+			}
+
+			rw.WriteHeader(http.StatusOK)
+
+			return nil
+		})
+
+		rr := server.NewResponseRecorder(httptest.NewRecorder())
+		err := infra.Mddleware(mdlw, next).ServeHTTP(rr, makeHARRequest(t, "http://localhost/users?access_token=abc"))
+		require.NoError(t, err)
+		require.NoError(t, harWriter.Close())
+
+		archive := readHARFile(t, path)
+		require.Len(t, archive.Log.Entries, 1)
+
+		entry := archive.Log.Entries[0]
+		assert.Equal(t, "10.0.0.1", entry.ServerIPAddress)
+		assert.Equal(t, "51234", entry.Connection)
+		require.NotNil(t, entry.Upstream)
+		assert.Equal(t, http.MethodGet, entry.Upstream.Method)
+		assert.Equal(t, "https://api.example.com/users?access_token=%5BREDACTED%5D", entry.Upstream.URL)
+		assert.ElementsMatch(t, []har.NameValue{
+			{Name: "Host", Value: "api.example.com"},
+			{Name: "X-Api-Key", Value: "[REDACTED]"},
+		}, entry.Upstream.Headers)
+		assert.GreaterOrEqual(t, entry.Timings.Blocked, float64(0))
+	})
+
+	t.Run("omits upstream for entries that were not proxied", func(t *testing.T) {
+		mdlw, harWriter, path := newHARMiddleware(t)
+
+		next := infra.HandlerFunc(func(rw contracts.ResponseWriter, _ *contracts.Request) error {
+			rw.WriteHeader(http.StatusOK)
+
+			return nil
+		})
+
+		rr := server.NewResponseRecorder(httptest.NewRecorder())
+		err := infra.Mddleware(mdlw, next).ServeHTTP(rr, makeHARRequest(t, "http://localhost/mock"))
+		require.NoError(t, err)
+		require.NoError(t, harWriter.Close())
+
+		archive := readHARFile(t, path)
+		require.Len(t, archive.Log.Entries, 1)
+
+		entry := archive.Log.Entries[0]
+		assert.Nil(t, entry.Upstream)
+		assert.Empty(t, entry.ServerIPAddress)
+		assert.InDelta(t, -1, entry.Timings.DNS, 0)
+		assert.InDelta(t, entry.Time, entry.Timings.Wait, 0)
+	})
+}
@@ -1,5 +1,11 @@
 package har
 
+import (
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+)
+
 // MiddlewareOption is a functional option for Middleware.
 type MiddlewareOption = func(*Middleware)
 
@@ -18,3 +24,74 @@ func WithCaptureSecureHeaders(capture bool) MiddlewareOption {
 		m.captureSecureHeaders = capture
 	}
 }
+
+// WithInclude records only requests matching the filter.
+func WithInclude(include config.HARFilter) MiddlewareOption {
+	return func(m *Middleware) {
+		m.include = newFilter(include)
+	}
+}
+
+// WithExclude skips requests matching the filter.
+func WithExclude(exclude config.HARFilter) MiddlewareOption {
+	return func(m *Middleware) {
+		m.exclude = newFilter(exclude)
+	}
+}
+
+// WithMaxBodySize cuts recorded request and response bodies to size bytes.
+// Zero keeps bodies up to the capture size limit.
+func WithMaxBodySize(size int64) MiddlewareOption {
+	return func(m *Middleware) {
+		m.maxBodySize = size
+	}
+}
+
+// WithRedaction masks headers, query parameters, JSON fields and pattern
+// matches before entries are written.
+func WithRedaction(redaction config.HARRedaction) MiddlewareOption {
+	return func(m *Middleware) {
+		m.redactor = newRedactor(redaction)
+	}
+}
+
+// WriterOption is a functional option for Writer.
+type WriterOption = func(*Writer)
+
+// WithFormat sets the output format: config.HARFormatHAR (default) or
+// config.HARFormatNDJSON.
+func WithFormat(format string) WriterOption {
+	return func(w *Writer) {
+		if format != "" {
+			w.format = format
+		}
+	}
+}
+
+// WithMaxSize rotates the file once it reaches size bytes.
+func WithMaxSize(size int64) WriterOption {
+	return func(w *Writer) {
+		w.maxSize = size
+	}
+}
+
+// WithMaxEntries rotates the file once it holds count entries.
+func WithMaxEntries(count int) WriterOption {
+	return func(w *Writer) {
+		w.maxEntries = count
+	}
+}
+
+// WithRotateInterval rotates the file once it is older than interval.
+func WithRotateInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.interval = interval
+	}
+}
+
+// WithStats sets the counters the writer reports dropped entries to.
+func WithStats(stats *Stats) WriterOption {
+	return func(w *Writer) {
+		w.stats = stats
+	}
+}
@@ -0,0 +1,74 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// ReadFile reads and parses a HAR file. Files written in the NDJSON format,
+// with one entry per line, are read as well.
+func ReadFile(fs afero.Fs, file string) (*HAR, error) {
+	data, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file '%s': %w", file, err)
+	}
+
+	archive, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file '%s': %w", file, err)
+	}
+
+	return archive, nil
+}
+
+func parse(data []byte) (*HAR, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	var archive HAR
+
+	for {
+		var value json.RawMessage
+
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		var probe struct {
+			Log *Log `json:"log"`
+		}
+
+		err = json.Unmarshal(value, &probe)
+		if err != nil {
+			return nil, err
+		}
+
+		if probe.Log != nil {
+			archive.Log.Version = probe.Log.Version
+			archive.Log.Creator = probe.Log.Creator
+			archive.Log.Entries = append(archive.Log.Entries, probe.Log.Entries...)
+
+			continue
+		}
+
+		var entry Entry
+
+		err = json.Unmarshal(value, &entry)
+		if err != nil {
+			return nil, err
+		}
+
+		archive.Log.Entries = append(archive.Log.Entries, entry)
+	}
+
+	return &archive, nil
+}
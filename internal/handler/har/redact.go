@@ -0,0 +1,177 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/config"
+)
+
+// redactedValue replaces every masked value.
+const redactedValue = "[REDACTED]"
+
+// urlHeaders are the headers whose values are URLs, so their query
+// parameters are masked like the ones of the request URL.
+var urlHeaders = map[string]bool{
+	"Location":         true,
+	"Referer":          true,
+	"Content-Location": true,
+}
+
+// redactor is the compiled form of config.HARRedaction.
+type redactor struct {
+	headers    map[string]bool
+	query      map[string]bool
+	jsonFields map[string]bool
+	patterns   []*regexp.Regexp
+}
+
+func newRedactor(cfg config.HARRedaction) *redactor {
+	result := &redactor{
+		headers:    make(map[string]bool, len(cfg.Headers)),
+		query:      lowerSet(cfg.Query),
+		jsonFields: lowerSet(cfg.JSONFields),
+	}
+
+	for _, name := range cfg.Headers {
+		result.headers[http.CanonicalHeaderKey(name)] = true
+	}
+
+	for _, pattern := range cfg.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+
+		result.patterns = append(result.patterns, compiled)
+	}
+
+	if len(result.headers) == 0 && len(result.query) == 0 &&
+		len(result.jsonFields) == 0 && len(result.patterns) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+func (r *redactor) header(name, value string) string {
+	name = http.CanonicalHeaderKey(name)
+	if r.headers[name] {
+		return redactedValue
+	}
+
+	if urlHeaders[name] {
+		return r.url(value)
+	}
+
+	return r.text(value)
+}
+
+func (r *redactor) queryValue(name, value string) string {
+	if r.query[strings.ToLower(name)] {
+		return redactedValue
+	}
+
+	return r.text(value)
+}
+
+// url masks query parameters in place, so the order of the parameters is
+// kept, and applies the patterns to the result.
+func (r *redactor) url(raw string) string {
+	base, rawQuery, hasQuery := strings.Cut(raw, "?")
+	if hasQuery && len(r.query) > 0 {
+		parts := strings.Split(rawQuery, "&")
+		for i, part := range parts {
+			name, _, _ := strings.Cut(part, "=")
+			if unescaped, err := url.QueryUnescape(name); err == nil && r.query[strings.ToLower(unescaped)] {
+				parts[i] = name + "=" + url.QueryEscape(redactedValue)
+			}
+		}
+
+		raw = base + "?" + strings.Join(parts, "&")
+	}
+
+	return r.text(raw)
+}
+
+// body masks JSON fields of JSON bodies and applies the patterns. JSON bodies
+// are only re-encoded when a field was masked.
+func (r *redactor) body(text string) string {
+	if len(r.jsonFields) > 0 {
+		if masked, ok := r.json(text); ok {
+			text = masked
+		}
+	}
+
+	return r.text(text)
+}
+
+func (r *redactor) json(text string) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return "", false
+	}
+
+	if !r.maskFields(value) {
+		return "", false
+	}
+
+	var buffer bytes.Buffer
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), true
+}
+
+func (r *redactor) maskFields(value any) bool {
+	masked := false
+
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if r.jsonFields[strings.ToLower(key)] {
+				typed[key] = redactedValue
+				masked = true
+
+				continue
+			}
+
+			masked = r.maskFields(item) || masked
+		}
+	case []any:
+		for _, item := range typed {
+			masked = r.maskFields(item) || masked
+		}
+	}
+
+	return masked
+}
+
+func (r *redactor) text(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.ReplaceAllLiteralString(value, redactedValue)
+	}
+
+	return value
+}
+
+func lowerSet(values []string) map[string]bool {
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[strings.ToLower(value)] = true
+	}
+
+	return result
+}
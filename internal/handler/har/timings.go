@@ -0,0 +1,65 @@
+package har
+
+import (
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+)
+
+// notApplicable marks timing phases that did not happen.
+const notApplicable = -1
+
+// buildTimings splits the elapsed time into HAR phases. Requests that were not
+// forwarded, e.g. mocks and cached responses, report the whole time as wait.
+// For proxied requests blocked is the time spent in uncors itself and waiting
+// for a free connection, the other phases come from the upstream trace.
+func buildTimings(elapsed time.Duration, upstream *contracts.UpstreamTrace) Timings {
+	total := milliseconds(elapsed)
+
+	if upstream == nil || upstream.WroteRequest.IsZero() {
+		return Timings{
+			Blocked: notApplicable,
+			DNS:     notApplicable,
+			Connect: notApplicable,
+			Wait:    total,
+			SSL:     notApplicable,
+		}
+	}
+
+	connectDone := upstream.ConnectDone
+	if !upstream.TLSDone.IsZero() {
+		connectDone = upstream.TLSDone
+	}
+
+	firstByte := upstream.FirstByte
+	if firstByte.IsZero() {
+		// The target failed before responding, the rest is waiting.
+		firstByte = upstream.Done
+	}
+
+	timings := Timings{
+		DNS:     phase(upstream.DNSStart, upstream.DNSDone),
+		Connect: phase(upstream.ConnectStart, connectDone),
+		Send:    max(phase(upstream.GotConn, upstream.WroteRequest), 0),
+		Wait:    max(phase(upstream.WroteRequest, firstByte), 0),
+		Receive: max(phase(firstByte, upstream.Done), 0),
+		SSL:     phase(upstream.TLSStart, upstream.TLSDone),
+	}
+
+	measured := max(timings.DNS, 0) + max(timings.Connect, 0) + timings.Send + timings.Wait + timings.Receive
+	timings.Blocked = max(total-measured, 0)
+
+	return timings
+}
+
+func phase(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() {
+		return notApplicable
+	}
+
+	return milliseconds(end.Sub(start))
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Nanoseconds()) / nanosecondsPerMillisecond
+}
@@ -0,0 +1,77 @@
+package har
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTimings(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		upstream *contracts.UpstreamTrace
+		expected Timings
+	}{
+		{
+			name:     "not forwarded",
+			elapsed:  5 * time.Millisecond,
+			upstream: &contracts.UpstreamTrace{},
+			expected: Timings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 5, Receive: 0, SSL: -1},
+		},
+		{
+			name:    "new TLS connection",
+			elapsed: 100 * time.Millisecond,
+			upstream: &contracts.UpstreamTrace{
+				Start:        at(2),
+				DNSStart:     at(2),
+				DNSDone:      at(10),
+				ConnectStart: at(10),
+				ConnectDone:  at(20),
+				TLSStart:     at(20),
+				TLSDone:      at(40),
+				GotConn:      at(40),
+				WroteRequest: at(41),
+				FirstByte:    at(91),
+				Done:         at(97),
+			},
+			expected: Timings{Blocked: 5, DNS: 8, Connect: 30, Send: 1, Wait: 50, Receive: 6, SSL: 20},
+		},
+		{
+			name:    "reused connection",
+			elapsed: 30 * time.Millisecond,
+			upstream: &contracts.UpstreamTrace{
+				Start:            at(1),
+				GotConn:          at(1),
+				WroteRequest:     at(2),
+				FirstByte:        at(22),
+				Done:             at(25),
+				ConnectionReused: true,
+			},
+			expected: Timings{Blocked: 6, DNS: -1, Connect: -1, Send: 1, Wait: 20, Receive: 3, SSL: -1},
+		},
+		{
+			name:    "no response",
+			elapsed: 20 * time.Millisecond,
+			upstream: &contracts.UpstreamTrace{
+				GotConn:      at(0),
+				WroteRequest: at(0),
+				Done:         at(20),
+			},
+			expected: Timings{Blocked: 0, DNS: -1, Connect: -1, Send: 0, Wait: 20, Receive: 0, SSL: -1},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, buildTimings(testCase.elapsed, testCase.upstream))
+		})
+	}
+}
@@ -21,12 +21,24 @@ type Creator struct {
 }
 
 // Entry represents a single request/response pair.
+// Upstream is set for proxied requests and holds the request uncors sent to
+// the target server; custom HAR fields start with an underscore.
 type Entry struct {
 	StartedDateTime time.Time `json:"startedDateTime"`
 	Time            float64   `json:"time"` // ms
 	Request         Request   `json:"request"`
 	Response        Response  `json:"response"`
 	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	Connection      string    `json:"connection,omitempty"`
+	Upstream        *Upstream `json:"_upstream,omitempty"`
+}
+
+// Upstream describes the request forwarded to the target server.
+type Upstream struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers []NameValue `json:"headers"`
 }
 
 // Request describes an HTTP request.
@@ -58,18 +70,25 @@ type Response struct {
 // Content holds response body details.
 // When Encoding is "base64", Text contains the base64-encoded body bytes
 // (used for payloads that could not be decoded, e.g. unknown compressions).
+// Comment is set when the captured body was cut at the capture size limit.
 type Content struct {
 	Size     int64  `json:"size"`
 	MimeType string `json:"mimeType"`
 	Text     string `json:"text,omitempty"`
 	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
 }
 
-// Timings breaks down request time into phases (all in ms).
+// Timings breaks down request time into phases (all in ms). Phases that did
+// not happen are -1. Connect includes the SSL handshake.
 type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
 	Send    float64 `json:"send"`
 	Wait    float64 `json:"wait"`
 	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
 }
 
 // NameValue is a generic key/value pair used for headers and query params.
@@ -85,7 +104,9 @@ type Cookie struct {
 }
 
 // PostData holds request body information.
+// Comment is set when the body was cut at the configured size limit.
 type PostData struct {
 	MimeType string `json:"mimeType"`
 	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
 }
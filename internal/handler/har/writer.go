@@ -2,10 +2,19 @@ package har
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/helpers"
 )
 
 const (
@@ -19,26 +28,64 @@ const (
 	harFileMode = 0o600
 	// harDirMode is the permission bits used when creating parent directories.
 	harDirMode = 0o755
+	// entryIndent is the indentation of entries inside the log.entries array.
+	entryIndent = "      "
+	// emptyFooter closes an archive without entries, entriesFooter closes
+	// one with entries.
+	emptyFooter   = "]\n  }\n}\n"
+	entriesFooter = "\n    ]\n  }\n}\n"
 )
 
-// Writer asynchronously appends HAR entries to a file.
+// Stats counts entries the writers had to drop. A single instance can be
+// shared by several writers.
+type Stats struct {
+	dropped atomic.Uint64
+}
+
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func (s *Stats) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Writer asynchronously appends HAR entries to a file. Entries are appended
+// in place, so neither memory usage nor write time grows with the file. In
+// the HAR format the file is kept a valid archive after every batch; in the
+// NDJSON format every line holds one entry.
 type Writer struct {
-	path    string
+	path       string
+	format     string
+	maxSize    int64
+	maxEntries int
+	interval   time.Duration
+	stats      *Stats
+
 	entries chan Entry
 	done    chan struct{}
 	once    sync.Once
 	wg      sync.WaitGroup
-	mu      sync.Mutex
-	all     []Entry
+
+	// The fields below are only used by the run goroutine.
+	file     *os.File
+	size     int64
+	count    int
+	openedAt time.Time
+	footer   string
+	rotated  int
 }
 
 // NewWriter creates a Writer that records entries to path.
-func NewWriter(path string) *Writer {
-	writer := &Writer{
+func NewWriter(path string, options ...WriterOption) *Writer {
+	writer := helpers.ApplyOptions(&Writer{
 		path:    path,
+		format:  config.HARFormatHAR,
+		stats:   &Stats{},
 		entries: make(chan Entry, entryChanBuffer),
 		done:    make(chan struct{}),
-	}
+	}, options)
 
 	writer.wg.Add(1)
 
@@ -53,6 +100,9 @@ func (w *Writer) AddEntry(entry Entry) {
 	case w.entries <- entry:
 	default:
 		// drop entry rather than block the request goroutine
+		if w.stats.dropped.Add(1) == 1 {
+			log.Printf("har: queue for %q is full, dropping entries", w.path)
+		}
 	}
 }
 
@@ -72,78 +122,201 @@ func (w *Writer) run() {
 	for {
 		select {
 		case entry := <-w.entries:
-			// Batch: append the triggering entry plus everything else already
-			// queued, then rewrite the file once instead of once per entry.
-			w.append(entry)
+			// Batch: write the triggering entry plus everything else already
+			// queued.
+			w.write(entry)
 			w.drain()
-			w.flush()
 
 		case <-w.done:
 			w.drain()
-			w.flush()
+			w.finish()
 
 			return
 		}
 	}
 }
 
-// drain appends every entry currently queued without blocking.
+// drain writes every entry currently queued without blocking.
 func (w *Writer) drain() {
 	for {
 		select {
 		case entry := <-w.entries:
-			w.append(entry)
+			w.write(entry)
 		default:
 			return
 		}
 	}
 }
 
-func (w *Writer) append(entry Entry) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
+func (w *Writer) write(entry Entry) {
+	if w.file != nil && w.shouldRotate() {
+		w.rotate()
+	}
+
+	if w.file == nil && !w.open() {
+		return
+	}
+
+	var err error
+	if w.format == config.HARFormatNDJSON {
+		err = w.writeLine(entry)
+	} else {
+		err = w.writeArchiveEntry(entry)
+	}
+
+	if err != nil {
+		log.Printf("har: cannot write entry to %q: %v", w.path, err)
 
-	w.all = append(w.all, entry)
+		return
+	}
+
+	w.count++
+}
+
+func (w *Writer) writeLine(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	written, err := w.file.Write(append(data, '\n'))
+	w.size += int64(written)
+
+	return err
 }
 
-func (w *Writer) flush() {
-	w.mu.Lock()
-	snapshot := make([]Entry, len(w.all))
-	copy(snapshot, w.all)
-	w.mu.Unlock()
+// writeArchiveEntry replaces the archive footer with the entry followed by a
+// new footer, so the file stays a valid HAR archive.
+func (w *Writer) writeArchiveEntry(entry Entry) error {
+	data, err := json.MarshalIndent(entry, entryIndent, "  ")
+	if err != nil {
+		return err
+	}
 
-	archive := HAR{
-		Log: Log{
-			Version: harVersion,
-			Creator: Creator{Name: creatorName, Version: creatorVersion},
-			Entries: snapshot,
-		},
+	separator := ","
+	if w.count == 0 {
+		separator = ""
 	}
 
-	data, err := json.MarshalIndent(archive, "", "  ")
+	chunk := separator + "\n" + entryIndent + string(data) + entriesFooter
+	offset := w.size - int64(len(w.footer))
+
+	written, err := w.file.WriteAt([]byte(chunk), offset)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = os.MkdirAll(filepath.Dir(w.path), harDirMode)
+	w.size = offset + int64(written)
+	w.footer = entriesFooter
+
+	return nil
+}
+
+func (w *Writer) shouldRotate() bool {
+	if w.count == 0 {
+		return false
+	}
+
+	return (w.maxEntries > 0 && w.count >= w.maxEntries) ||
+		(w.maxSize > 0 && w.size >= w.maxSize) ||
+		(w.interval > 0 && time.Since(w.openedAt) >= w.interval)
+}
+
+// open creates the file, replacing an existing one, and writes the empty
+// archive for the HAR format.
+func (w *Writer) open() bool {
+	err := os.MkdirAll(filepath.Dir(w.path), harDirMode)
 	if err != nil {
 		log.Printf("har: cannot create directory for %q: %v", w.path, err)
 
-		return
+		return false
 	}
 
-	tmp := w.path + ".tmp"
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, harFileMode)
+	if err != nil {
+		log.Printf("har: cannot open %q: %v", w.path, err)
+
+		return false
+	}
+
+	w.file = file
+	w.size = 0
+	w.count = 0
+	w.footer = ""
+	w.openedAt = time.Now()
+
+	if w.format == config.HARFormatNDJSON {
+		return true
+	}
 
-	err = os.WriteFile(tmp, data, harFileMode)
+	header := fmt.Sprintf(
+		"{\n  \"log\": {\n    \"version\": %q,\n    \"creator\": {\n      \"name\": %q,\n      \"version\": %q\n    },\n    \"entries\": [",
+		harVersion, creatorName, creatorVersion,
+	)
+	written, err := file.WriteString(header + emptyFooter)
 	if err != nil {
-		log.Printf("har: cannot write temp file %q: %v", tmp, err)
+		log.Printf("har: cannot write %q: %v", w.path, err)
+		w.close()
+
+		return false
+	}
 
+	w.size = int64(written)
+	w.footer = emptyFooter
+
+	return true
+}
+
+func (w *Writer) close() {
+	if w.file == nil {
 		return
 	}
 
-	err = os.Rename(tmp, w.path)
+	err := w.file.Close()
+	if err != nil {
+		log.Printf("har: cannot close %q: %v", w.path, err)
+	}
+
+	w.file = nil
+}
+
+// rotate closes the current file and moves it next to the active one with a
+// sequence number, e.g. api.har becomes api.1.har.
+func (w *Writer) rotate() {
+	w.close()
+
+	target := w.nextRotatedPath()
+
+	err := os.Rename(w.path, target)
 	if err != nil {
-		log.Printf("har: cannot rename %q to %q: %v", tmp, w.path, err)
-		_ = os.Remove(tmp)
+		log.Printf("har: cannot rotate %q to %q: %v", w.path, target, err)
+	}
+}
+
+func (w *Writer) nextRotatedPath() string {
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext)
+
+	for {
+		w.rotated++
+
+		candidate := base + "." + strconv.Itoa(w.rotated) + ext
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+// finish makes sure the file exists, even when no entries were recorded, and
+// closes it.
+func (w *Writer) finish() {
+	if w.file == nil && !w.open() {
+		return
+	}
+
+	w.close()
+
+	if dropped := w.stats.Dropped(); dropped > 0 {
+		log.Printf("har: %d entries were dropped because the queue was full", dropped)
 	}
 }
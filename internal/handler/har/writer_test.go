@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -138,4 +141,152 @@ func TestWriter(t *testing.T) {
 		require.NoError(t, json.Unmarshal(data, &archive))
 		assert.Len(t, archive.Log.Entries, 1)
 	})
+	t.Run("keeps a valid archive with many entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.har")
+		harWriter := har.NewWriter(path)
+
+		for i := range 3 {
+			harWriter.AddEntry(entryWithStatus(200 + i))
+		}
+
+		require.NoError(t, harWriter.Close())
+
+		assert.Equal(t, []int{200, 201, 202}, readStatuses(t, path))
+	})
+
+	t.Run("writes NDJSON lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.ndjson")
+		harWriter := har.NewWriter(path, har.WithFormat(config.HARFormatNDJSON))
+
+		harWriter.AddEntry(entryWithStatus(200))
+		harWriter.AddEntry(entryWithStatus(404))
+
+		require.NoError(t, harWriter.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		require.Len(t, lines, 2)
+
+		var entry har.Entry
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		assert.Equal(t, 404, entry.Response.Status)
+		assert.Equal(t, []int{200, 404}, readStatuses(t, path))
+	})
+
+	t.Run("rotates files", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			options  []har.WriterOption
+			expected map[string][]int
+		}{
+			{
+				name:    "by entry count",
+				options: []har.WriterOption{har.WithMaxEntries(2)},
+				expected: map[string][]int{
+					"out.1.har": {200, 201},
+					"out.2.har": {202, 203},
+					"out.har":   {204},
+				},
+			},
+			{
+				name:    "by size",
+				options: []har.WriterOption{har.WithMaxSize(1)},
+				expected: map[string][]int{
+					"out.1.har": {200},
+					"out.2.har": {201},
+					"out.3.har": {202},
+					"out.4.har": {203},
+					"out.har":   {204},
+				},
+			},
+			{
+				name:    "by interval",
+				options: []har.WriterOption{har.WithRotateInterval(time.Nanosecond)},
+				expected: map[string][]int{
+					"out.1.har": {200},
+					"out.2.har": {201},
+					"out.3.har": {202},
+					"out.4.har": {203},
+					"out.har":   {204},
+				},
+			},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				dir := t.TempDir()
+				harWriter := har.NewWriter(filepath.Join(dir, "out.har"), testCase.options...)
+
+				for i := range 5 {
+					harWriter.AddEntry(entryWithStatus(200 + i))
+				}
+
+				require.NoError(t, harWriter.Close())
+
+				files, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Len(t, files, len(testCase.expected))
+
+				for name, statuses := range testCase.expected {
+					assert.Equal(t, statuses, readStatuses(t, filepath.Join(dir, name)), name)
+				}
+			})
+		}
+	})
+
+	t.Run("does not overwrite existing rotated files", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "out.1.har"), []byte("old"), 0o600))
+
+		harWriter := har.NewWriter(filepath.Join(dir, "out.har"), har.WithMaxEntries(1))
+		harWriter.AddEntry(entryWithStatus(200))
+		harWriter.AddEntry(entryWithStatus(201))
+
+		require.NoError(t, harWriter.Close())
+
+		data, err := os.ReadFile(filepath.Join(dir, "out.1.har"))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+		assert.Equal(t, []int{200}, readStatuses(t, filepath.Join(dir, "out.2.har")))
+		assert.Equal(t, []int{201}, readStatuses(t, filepath.Join(dir, "out.har")))
+	})
+
+	t.Run("counts dropped entries", func(t *testing.T) {
+		const total = 10_000
+
+		path := filepath.Join(t.TempDir(), "out.ndjson")
+		stats := &har.Stats{}
+		harWriter := har.NewWriter(path, har.WithFormat(config.HARFormatNDJSON), har.WithStats(stats))
+
+		for range total {
+			harWriter.AddEntry(har.Entry{})
+		}
+
+		require.NoError(t, harWriter.Close())
+
+		assert.Equal(t, uint64(total), uint64(len(readStatuses(t, path)))+stats.Dropped())
+	})
+}
+
+func entryWithStatus(status int) har.Entry {
+	return har.Entry{
+		Request:  har.Request{Method: "GET", URL: "http://example.com/"},
+		Response: har.Response{Status: status},
+	}
+}
+
+func readStatuses(t *testing.T, path string) []int {
+	t.Helper()
+
+	archive, err := har.ReadFile(afero.NewOsFs(), path)
+	require.NoError(t, err)
+
+	statuses := make([]int, 0, len(archive.Log.Entries))
+	for _, entry := range archive.Log.Entries {
+		statuses = append(statuses, entry.Response.Status)
+	}
+
+	return statuses
 }
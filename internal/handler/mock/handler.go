@@ -6,21 +6,25 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/helpers"
-	"github.com/evg4b/uncors/internal/infra"
 	"github.com/evg4b/uncors/pkg/urlt"
 	"github.com/go-http-utils/headers"
 	"github.com/spf13/afero"
 )
 
 type Handler struct {
-	response *config.Response
-	fs       afero.Fs
-	after    func(duration time.Duration) <-chan time.Time
+	response  *config.Response
+	fs        afero.Fs
+	after     func(duration time.Duration) <-chan time.Time
+	now       func() time.Time
+	templates *responseTemplates
 }
 
 const contentTypeSniffLen = 512
@@ -28,7 +32,13 @@ const contentTypeSniffLen = 512
 var ErrResponseIsNotDefined = errors.New("response is not defined")
 
 func NewMockHandler(options ...HandlerOption) *Handler {
-	return helpers.ApplyOptions(&Handler{}, options)
+	handler := helpers.ApplyOptions(&Handler{now: time.Now}, options)
+
+	if handler.response != nil && handler.response.Template {
+		handler.templates = handler.parseTemplates()
+	}
+
+	return handler
 }
 
 func (h *Handler) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request) error {
@@ -52,29 +62,58 @@ func (h *Handler) writeResponse(writer contracts.ResponseWriter, request *contra
 	header := writer.Header()
 	response := h.response
 
-	origin := request.Header.Get("Origin")
-	infra.WriteCorsHeaders(header, origin)
+	cors.WriteHeaders(header, request)
+
+	var (
+		data *templateData
+		err  error
+	)
+
+	if response.Template {
+		if h.templates.err != nil {
+			return h.templates.err
+		}
+
+		data, err = newTemplateData(request)
+		if err != nil {
+			return err
+		}
+	}
 
 	for key, value := range response.Headers {
+		if data != nil {
+			value, err = render(h.templates.headers[key], data)
+			if err != nil {
+				return err
+			}
+		}
+
 		header.Set(key, value)
 	}
 
 	switch {
+	case response.IsFile() && data != nil:
+		return h.serveFileTemplate(writer, request, data)
 	case response.IsFile():
 		return h.serveFileContent(writer, request)
+	case response.IsRaw() && data != nil:
+		content, err := render(h.templates.raw, data)
+		if err != nil {
+			return err
+		}
+
+		return h.serveRawContent(writer, content)
 	case response.IsRaw():
-		return h.serveRawContent(writer)
+		return h.serveRawContent(writer, response.Raw)
 	default:
 		return ErrResponseIsNotDefined
 	}
 }
 
-func (h *Handler) serveRawContent(writer http.ResponseWriter) error {
-	response := h.response
-
+func (h *Handler) serveRawContent(writer http.ResponseWriter, content string) error {
 	header := writer.Header()
 	if len(header.Get(headers.ContentType)) == 0 {
-		sniff := response.Raw
+		sniff := content
 		if len(sniff) > contentTypeSniffLen {
 			sniff = sniff[:contentTypeSniffLen]
 		}
@@ -83,8 +122,8 @@ func (h *Handler) serveRawContent(writer http.ResponseWriter) error {
 		header.Set(headers.ContentType, contentType)
 	}
 
-	writer.WriteHeader(helpers.NormaliseStatusCode(response.Code))
-	_, err := fmt.Fprint(writer, response.Raw)
+	writer.WriteHeader(helpers.NormaliseStatusCode(h.response.Code))
+	_, err := fmt.Fprint(writer, content)
 
 	return err
 }
@@ -107,6 +146,26 @@ func (h *Handler) serveFileContent(writer http.ResponseWriter, request *http.Req
 	return nil
 }
 
+// serveFileTemplate renders the file as a template on every request. The
+// result has no modification time since it depends on the request.
+func (h *Handler) serveFileTemplate(writer http.ResponseWriter, request *http.Request, data *templateData) error {
+	fileName := h.response.File
+
+	tmpl, err := h.fileTemplate()
+	if err != nil {
+		return err
+	}
+
+	rendered, err := render(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	http.ServeContent(writer, request, filepath.Base(fileName), time.Time{}, strings.NewReader(rendered))
+
+	return nil
+}
+
 func (h *Handler) waitDelay(writer contracts.ResponseWriter, request *contracts.Request) bool {
 	if h.response.Delay <= 0 {
 		return false
@@ -26,3 +26,10 @@ func WithAfter(after func(duration time.Duration) <-chan time.Time) HandlerOptio
 		h.after = after
 	}
 }
+
+// WithNow overrides the time source used by template helpers.
+func WithNow(now func() time.Time) HandlerOption {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
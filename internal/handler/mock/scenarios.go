@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/evg4b/uncors/internal/config"
+)
+
+// Scenarios keeps the current state of every named mock scenario. Scenarios
+// that were never switched are in config.DefaultScenarioState.
+type Scenarios struct {
+	mutex  sync.RWMutex
+	states map[string]string
+}
+
+func NewScenarios() *Scenarios {
+	return &Scenarios{states: map[string]string{}}
+}
+
+func (s *Scenarios) State(name string) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if state, ok := s.states[name]; ok {
+		return state
+	}
+
+	return config.DefaultScenarioState
+}
+
+func (s *Scenarios) Set(name, state string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.states[name] = state
+}
+
+// Reset returns all scenarios to their initial state.
+func (s *Scenarios) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clear(s.states)
+}
@@ -0,0 +1,35 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarios(t *testing.T) {
+	t.Run("starts in default state", func(t *testing.T) {
+		scenarios := mock.NewScenarios()
+
+		assert.Equal(t, config.DefaultScenarioState, scenarios.State("order"))
+	})
+
+	t.Run("keeps state per scenario", func(t *testing.T) {
+		scenarios := mock.NewScenarios()
+
+		scenarios.Set("order", "shipped")
+
+		assert.Equal(t, "shipped", scenarios.State("order"))
+		assert.Equal(t, config.DefaultScenarioState, scenarios.State("payment"))
+	})
+
+	t.Run("reset returns to default state", func(t *testing.T) {
+		scenarios := mock.NewScenarios()
+
+		scenarios.Set("order", "shipped")
+		scenarios.Reset()
+
+		assert.Equal(t, config.DefaultScenarioState, scenarios.State("order"))
+	})
+}
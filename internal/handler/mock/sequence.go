@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/evg4b/uncors/internal/contracts"
+)
+
+// SequenceHandler delegates each request to the next handler of the list.
+// After the last one it either starts over or keeps using the last handler.
+type SequenceHandler struct {
+	handlers []contracts.Handler
+	loop     bool
+
+	mutex sync.Mutex
+	next  int
+}
+
+func NewSequenceHandler(handlers []contracts.Handler, loop bool) *SequenceHandler {
+	return &SequenceHandler{
+		handlers: handlers,
+		loop:     loop,
+	}
+}
+
+func (h *SequenceHandler) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request) error {
+	return h.step().ServeHTTP(writer, request)
+}
+
+func (h *SequenceHandler) step() contracts.Handler {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	handler := h.handlers[h.next]
+
+	switch {
+	case h.next < len(h.handlers)-1:
+		h.next++
+	case h.loop:
+		h.next = 0
+	}
+
+	return handler
+}
@@ -0,0 +1,97 @@
+package mock_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceHandler(t *testing.T) {
+	newHandlers := func(bodies ...string) []contracts.Handler {
+		handlers := make([]contracts.Handler, 0, len(bodies))
+		for _, body := range bodies {
+			handlers = append(handlers, infra.HandlerFunc(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+				_, err := fmt.Fprint(writer, body)
+
+				return err
+			}))
+		}
+
+		return handlers
+	}
+
+	serve := func(t *testing.T, handler contracts.Handler) string {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+		return testutils.ReadBody(t, recorder)
+	}
+
+	tests := []struct {
+		name     string
+		loop     bool
+		expected []string
+	}{
+		{
+			name:     "repeats last handler",
+			expected: []string{"1", "2", "3", "3", "3"},
+		},
+		{
+			name:     "starts over when looped",
+			loop:     true,
+			expected: []string{"1", "2", "3", "1", "2"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := mock.NewSequenceHandler(newHandlers("1", "2", "3"), testCase.loop)
+
+			actual := make([]string, 0, len(testCase.expected))
+			for range testCase.expected {
+				actual = append(actual, serve(t, handler))
+			}
+
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+
+	t.Run("serves each step once under concurrent requests", func(t *testing.T) {
+		const count = 50
+
+		handler := mock.NewSequenceHandler(newHandlers("1", "2"), true)
+
+		var (
+			mutex  sync.Mutex
+			counts = map[string]int{}
+			group  sync.WaitGroup
+		)
+
+		for range count {
+			group.Go(func() {
+				body := serve(t, handler)
+
+				mutex.Lock()
+				counts[body]++
+				mutex.Unlock()
+			})
+		}
+
+		group.Wait()
+
+		assert.Equal(t, map[string]int{"1": count / 2, "2": count / 2}, counts)
+	})
+}
@@ -0,0 +1,159 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/gorilla/mux"
+	"github.com/spf13/afero"
+)
+
+// templateData is the request information available in response templates.
+type templateData struct {
+	Method  string
+	URL     string
+	Host    string
+	Path    string
+	Params  map[string]string
+	Query   url.Values
+	Headers http.Header
+	Body    any
+	RawBody string
+}
+
+func newTemplateData(request *contracts.Request) (*templateData, error) {
+	data := &templateData{
+		Method:  request.Method,
+		URL:     request.URL.String(),
+		Host:    request.Host,
+		Path:    request.URL.Path,
+		Params:  mux.Vars(request),
+		Query:   request.URL.Query(),
+		Headers: request.Header,
+	}
+
+	if data.Params == nil {
+		data.Params = map[string]string{}
+	}
+
+	if request.Body == nil || request.Body == http.NoBody {
+		return data, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	data.RawBody = string(body)
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var parsed any
+	if decoder.Decode(&parsed) == nil {
+		data.Body = parsed
+	}
+
+	return data, nil
+}
+
+// responseTemplates holds the parsed templates of a response. The raw and
+// header templates are parsed when the handler is built, the file template
+// on the first request and again only when the file changes.
+type responseTemplates struct {
+	raw     *template.Template
+	headers map[string]*template.Template
+	err     error
+
+	mutex sync.Mutex
+	file  fileTemplate
+}
+
+type fileTemplate struct {
+	modTime  time.Time
+	size     int64
+	template *template.Template
+}
+
+func (h *Handler) parseTemplates() *responseTemplates {
+	templates := &responseTemplates{headers: make(map[string]*template.Template, len(h.response.Headers))}
+
+	for key, value := range h.response.Headers {
+		templates.headers[key], templates.err = h.parse("headers."+key, value)
+		if templates.err != nil {
+			return templates
+		}
+	}
+
+	if h.response.IsRaw() {
+		templates.raw, templates.err = h.parse("raw", h.response.Raw)
+	}
+
+	return templates
+}
+
+// fileTemplate returns the template of the response file, parsing it again
+// when its modification time or size changed.
+func (h *Handler) fileTemplate() (*template.Template, error) {
+	fileName := h.response.File
+
+	stat, err := h.fs.Stat(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fileName, err)
+	}
+
+	h.templates.mutex.Lock()
+	defer h.templates.mutex.Unlock()
+
+	cached := h.templates.file
+	if cached.template != nil && cached.modTime.Equal(stat.ModTime()) && cached.size == stat.Size() {
+		return cached.template, nil
+	}
+
+	content, err := afero.ReadFile(h.fs, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fileName, err)
+	}
+
+	tmpl, err := h.parse(filepath.Base(fileName), string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	h.templates.file = fileTemplate{modTime: stat.ModTime(), size: stat.Size(), template: tmpl}
+
+	return tmpl, nil
+}
+
+func (h *Handler) parse(name, text string) (*template.Template, error) {
+	tmpl, err := helpers.ParseTemplate(name, text, h.now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, data *templateData) (string, error) {
+	var builder strings.Builder
+
+	err := tmpl.Execute(&builder, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+
+	return builder.String(), nil
+}
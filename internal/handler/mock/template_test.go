@@ -0,0 +1,228 @@
+package mock_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/gorilla/mux"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerTemplates(t *testing.T) {
+	const userFile = "user.json"
+
+	fileSystem := testutils.FsFromMap(t, map[string]string{
+		userFile: `{"id": "{{ .Params.id }}", "name": "{{ .Query.Get "name" }}"}`,
+	})
+
+	now := time.Date(2026, time.March, 14, 15, 9, 26, 0, time.UTC)
+
+	serve := func(t *testing.T, response config.Response, request *http.Request) *httptest.ResponseRecorder {
+		t.Helper()
+
+		handler := mock.NewMockHandler(
+			mock.WithResponse(&response),
+			mock.WithFileSystem(fileSystem),
+			mock.WithNow(func() time.Time { return now }),
+		)
+
+		recorder := httptest.NewRecorder()
+		require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+		return recorder
+	}
+
+	newRequest := func(t *testing.T, method, url, body string) *http.Request {
+		t.Helper()
+
+		request := httptest.NewRequestWithContext(t.Context(), method, url, strings.NewReader(body))
+
+		return mux.SetURLVars(request, map[string]string{"id": "42"})
+	}
+
+	t.Run("renders raw content", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			raw      string
+			method   string
+			url      string
+			body     string
+			expected string
+		}{
+			{
+				name:     "path params",
+				raw:      `{"id": "{{ .Params.id }}"}`,
+				url:      "/users/42",
+				expected: `{"id": "42"}`,
+			},
+			{
+				name:     "query params",
+				raw:      `{{ .Query.Get "page" }}/{{ .Query.Get "size" | default "10" }}`,
+				url:      "/users?page=2",
+				expected: "2/10",
+			},
+			{
+				name:     "request data",
+				raw:      "{{ .Method }} {{ .Path }}",
+				method:   http.MethodDelete,
+				url:      "/users/42",
+				expected: "DELETE /users/42",
+			},
+			{
+				name:     "json body fields",
+				raw:      `{{ .Body.user.name }} ({{ .Body.user.age }})`,
+				method:   http.MethodPost,
+				url:      "/users",
+				body:     `{"user": {"name": "Jane", "age": 30}}`,
+				expected: "Jane (30)",
+			},
+			{
+				name:     "json encoded body",
+				raw:      `{"created": {{ toJSON .Body }}}`,
+				method:   http.MethodPost,
+				url:      "/users",
+				body:     `{"name": "Jane"}`,
+				expected: `{"created": {"name":"Jane"}}`,
+			},
+			{
+				name:     "raw body",
+				raw:      "echo: {{ .RawBody }}",
+				method:   http.MethodPost,
+				url:      "/echo",
+				body:     "plain text",
+				expected: "echo: plain text",
+			},
+			{
+				name:     "timestamps",
+				raw:      `{{ now.Format "2006-01-02" }} {{ timestamp }}`,
+				url:      "/time",
+				expected: "2026-03-14 1773500966",
+			},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				method := testCase.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				recorder := serve(t, config.Response{
+					Code:     http.StatusOK,
+					Raw:      testCase.raw,
+					Template: true,
+				}, newRequest(t, method, testCase.url, testCase.body))
+
+				assert.Equal(t, testCase.expected, testutils.ReadBody(t, recorder))
+			})
+		}
+	})
+
+	t.Run("renders headers", func(t *testing.T) {
+		recorder := serve(t, config.Response{
+			Code:     http.StatusCreated,
+			Raw:      "ok",
+			Headers:  map[string]string{headers.Location: "/users/{{ .Params.id }}"},
+			Template: true,
+		}, newRequest(t, http.MethodPost, "/users", ""))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "/users/42", recorder.Header().Get(headers.Location))
+	})
+
+	t.Run("renders file content", func(t *testing.T) {
+		recorder := serve(t, config.Response{
+			Code:     http.StatusOK,
+			File:     userFile,
+			Template: true,
+		}, newRequest(t, http.MethodGet, "/users/42?name=Jane", ""))
+
+		assert.JSONEq(t, `{"id": "42", "name": "Jane"}`, testutils.ReadBody(t, recorder))
+		assert.Equal(t, "application/json", recorder.Header().Get(headers.ContentType))
+		assert.Empty(t, recorder.Header().Get(headers.LastModified))
+	})
+
+	t.Run("keeps request body readable", func(t *testing.T) {
+		request := newRequest(t, http.MethodPost, "/users", "payload")
+
+		serve(t, config.Response{Code: http.StatusOK, Raw: "{{ .RawBody }}", Template: true}, request)
+
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+	})
+
+	t.Run("does not render without template flag", func(t *testing.T) {
+		recorder := serve(t, config.Response{
+			Code: http.StatusOK,
+			Raw:  "{{ .Params.id }}",
+		}, newRequest(t, http.MethodGet, "/users/42", ""))
+
+		assert.Equal(t, "{{ .Params.id }}", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("returns error for invalid template", func(t *testing.T) {
+		handler := mock.NewMockHandler(
+			mock.WithResponse(&config.Response{Code: http.StatusOK, Raw: "{{ .Body.name.first }}", Template: true}),
+			mock.WithFileSystem(fileSystem),
+		)
+
+		recorder := httptest.NewRecorder()
+		request := newRequest(t, http.MethodPost, "/users", `{"name": "Jane"}`)
+
+		err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+		require.ErrorContains(t, err, "failed to render template raw")
+	})
+
+	t.Run("returns error for template that can not be parsed", func(t *testing.T) {
+		handler := mock.NewMockHandler(
+			mock.WithResponse(&config.Response{Code: http.StatusOK, Raw: "{{ .Method", Template: true}),
+			mock.WithFileSystem(fileSystem),
+		)
+
+		recorder := httptest.NewRecorder()
+		request := newRequest(t, http.MethodGet, "/users", "")
+
+		err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+		require.ErrorContains(t, err, "failed to parse template raw")
+	})
+
+	t.Run("parses file template again when file changes", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"greeting.txt": "Hello, {{ .Params.id }}",
+		})
+
+		handler := mock.NewMockHandler(
+			mock.WithResponse(&config.Response{Code: http.StatusOK, File: "greeting.txt", Template: true}),
+			mock.WithFileSystem(fs),
+		)
+
+		serveFile := func() string {
+			recorder := httptest.NewRecorder()
+			request := newRequest(t, http.MethodGet, "/users/42", "")
+			require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+			return testutils.ReadBody(t, recorder)
+		}
+
+		assert.Equal(t, "Hello, 42", serveFile())
+		assert.Equal(t, "Hello, 42", serveFile())
+
+		require.NoError(t, afero.WriteFile(fs, "greeting.txt", []byte("Bye, {{ .Params.id }}!"), 0o644))
+
+		assert.Equal(t, "Bye, 42!", serveFile())
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"strings"
 
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/helpers"
-	"github.com/evg4b/uncors/internal/infra"
 )
 
 type Middleware struct {
@@ -19,7 +19,7 @@ func NewMiddleware(options ...MiddlewareOption) *Middleware {
 }
 
 func (m *Middleware) ServeHTTP(resp contracts.ResponseWriter, req *contracts.Request, next contracts.Next) error {
-	if strings.EqualFold(req.Method, http.MethodOptions) {
+	if strings.EqualFold(req.Method, http.MethodOptions) && !cors.IsPassthrough(req) {
 		m.handle(resp, req)
 
 		return nil
@@ -29,7 +29,7 @@ func (m *Middleware) ServeHTTP(resp contracts.ResponseWriter, req *contracts.Req
 }
 
 func (m *Middleware) handle(resp http.ResponseWriter, req *http.Request) {
-	infra.WriteCorsHeadersForOptions(resp.Header(), req.Header)
+	cors.WritePreflightHeaders(resp.Header(), req)
 
 	for key, value := range m.headers {
 		resp.Header().Set(key, value)
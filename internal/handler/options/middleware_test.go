@@ -1,10 +1,13 @@
 package options_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/handler/options"
 	"github.com/evg4b/uncors/internal/infra"
 	"github.com/evg4b/uncors/internal/server"
@@ -323,4 +326,24 @@ func TestMiddleware(t *testing.T) {
 			})
 		}
 	})
+	t.Run("for OPTIONS request with passthrough cors should call next", func(t *testing.T) {
+		mockedNextHandler := mocks.NewHandlerMock(t)
+
+		middleware := options.NewMiddleware()
+
+		recorder := httptest.NewRecorder()
+		response := server.NewResponseRecorder(recorder)
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodOptions, "/", nil)
+		request = request.WithContext(context.WithValue(request.Context(), cors.ConfigKey, &config.CORSConfig{
+			Passthrough: true,
+		}))
+
+		mockedNextHandler.ServeHTTPMock.Expect(response, request).Return(nil)
+
+		err := infra.Mddleware(middleware, mockedNextHandler).
+			ServeHTTP(response, request)
+		require.NoError(t, err)
+
+		assert.Empty(t, recorder.Header())
+	})
 }
@@ -0,0 +1,239 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/urlreplacer"
+	"github.com/go-http-utils/headers"
+)
+
+type bodyRewriteKeyType string
+
+const BodyRewriteKey bodyRewriteKeyType = "__uncors_body_rewrite"
+
+// BodyRewriteMiddleware attaches the body rewrite settings of a mapping to
+// the request so that the proxy handler rewrites target URLs in bodies.
+type BodyRewriteMiddleware struct {
+	config *config.BodyRewriteConfig
+}
+
+func NewBodyRewriteMiddleware(options ...BodyRewriteOption) *BodyRewriteMiddleware {
+	middleware := helpers.ApplyOptions(&BodyRewriteMiddleware{}, options)
+
+	helpers.AssertIsDefined(middleware.config, "Body rewrite config is not defined")
+
+	return middleware
+}
+
+func (m *BodyRewriteMiddleware) ServeHTTP(
+	writer contracts.ResponseWriter,
+	request *contracts.Request,
+	next contracts.Next,
+) error {
+	return next(writer, request.WithContext(
+		context.WithValue(request.Context(), BodyRewriteKey, m.config),
+	))
+}
+
+type BodyRewriteOption = func(*BodyRewriteMiddleware)
+
+func WithBodyRewriteConfig(cfg *config.BodyRewriteConfig) BodyRewriteOption {
+	return func(m *BodyRewriteMiddleware) {
+		m.config = cfg
+	}
+}
+
+// rewritableEncodings are the content codings the proxy can decode and encode
+// again. Other codings, such as zstd, are removed from Accept-Encoding while
+// body rewriting is enabled, so the target does not use them. Responses the
+// target still sends with them are passed through without rewriting.
+var rewritableEncodings = map[string]bool{
+	"gzip":     true,
+	"x-gzip":   true,
+	"deflate":  true,
+	"br":       true,
+	"identity": true,
+}
+
+func getBodyRewrite(request *http.Request) *config.BodyRewriteConfig {
+	cfg, ok := request.Context().Value(BodyRewriteKey).(*config.BodyRewriteConfig)
+	if !ok || cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	return cfg
+}
+
+// filterAcceptEncoding keeps only the codings the proxy can rewrite. An empty
+// result removes the header, the HTTP client then negotiates gzip itself and
+// decodes the body transparently.
+func filterAcceptEncoding(header http.Header) {
+	values := header.Values(headers.AcceptEncoding)
+	if len(values) == 0 {
+		return
+	}
+
+	var accepted []string
+
+	for _, value := range values {
+		for item := range strings.SplitSeq(value, ",") {
+			item = strings.TrimSpace(item)
+			coding, _, _ := strings.Cut(item, ";")
+
+			if rewritableEncodings[strings.ToLower(strings.TrimSpace(coding))] {
+				accepted = append(accepted, item)
+			}
+		}
+	}
+
+	if len(accepted) == 0 {
+		header.Del(headers.AcceptEncoding)
+
+		return
+	}
+
+	header.Set(headers.AcceptEncoding, strings.Join(accepted, ", "))
+}
+
+func canRewriteBody(cfg *config.BodyRewriteConfig, request *http.Request, response *http.Response) bool {
+	if request.Method == http.MethodHead || response.StatusCode == http.StatusNoContent ||
+		response.StatusCode == http.StatusNotModified {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get(headers.ContentType))
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+
+	for _, pattern := range cfg.Types() {
+		if ok, _ := doublestar.Match(strings.ToLower(pattern), mediaType); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contentEncoding returns the normalized content coding of the response.
+func contentEncoding(response *http.Response) string {
+	return strings.ToLower(strings.TrimSpace(response.Header.Get(headers.ContentEncoding)))
+}
+
+// canDecodeBody reports whether the proxy can decode the content coding of
+// the response.
+func canDecodeBody(response *http.Response) bool {
+	encoding := contentEncoding(response)
+
+	return encoding == "" || rewritableEncodings[encoding]
+}
+
+// rewriteBody reads the whole body and replaces target URLs with source URLs.
+// The body is encoded again with its original content coding. Bodies that
+// can not be decoded are returned unchanged. The result reports whether the
+// body was changed.
+func rewriteBody(response *http.Response, replacer *urlreplacer.Replacer) ([]byte, bool, error) {
+	raw, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	encoding := contentEncoding(response)
+
+	decoded, err := decodeBody(raw, encoding)
+	if err != nil {
+		return raw, false, nil //nolint:nilerr
+	}
+
+	rewritten := replacer.ReplaceAllIn(decoded)
+	if bytes.Equal(rewritten, decoded) {
+		return raw, false, nil
+	}
+
+	encoded, err := encodeBody(rewritten, encoding)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return encoded, true, nil
+}
+
+// weakenETag marks the entity tag as weak, since the rewritten body is no
+// longer byte-for-byte the one the target tagged.
+func weakenETag(header http.Header) {
+	etag := header.Get(headers.ETag)
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set(headers.ETag, "W/"+etag)
+	}
+}
+
+func decodeBody(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		defer reader.Close()
+
+		return io.ReadAll(reader)
+	case "deflate":
+		// Servers send both zlib-wrapped and raw DEFLATE as "deflate".
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		}
+
+		defer reader.Close()
+
+		return io.ReadAll(reader)
+	case "br":
+		return io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+	default:
+		return data, nil
+	}
+}
+
+func encodeBody(data []byte, encoding string) ([]byte, error) {
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+	)
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	case "br":
+		writer = brotli.NewWriter(&buffer)
+	default:
+		return data, nil
+	}
+
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,222 @@
+package proxy_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/proxy"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/internal/tui"
+	"github.com/evg4b/uncors/internal/urlreplacer"
+	"github.com/evg4b/uncors/testing/hosts"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+	)
+
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	case "br":
+		writer = brotli.NewWriter(&buffer)
+	default:
+		return []byte(data)
+	}
+
+	_, err := io.WriteString(writer, data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func decode(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+
+	var (
+		reader io.ReadCloser
+		err    error
+	)
+
+	switch encoding {
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	case "br":
+		reader = io.NopCloser(brotli.NewReader(bytes.NewReader(data)))
+	default:
+		return string(data)
+	}
+
+	require.NoError(t, err)
+
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(decoded)
+}
+
+func TestBodyRewrite(t *testing.T) {
+	var (
+		contentType     string
+		contentEncoding string
+		etag            string
+		acceptEncoding  string
+	)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get(headers.AcceptEncoding)
+
+		body := `{"self":"http://` + r.Host + `/users","docs":"https://docs.example.com"}`
+
+		w.Header().Set(headers.ContentType, contentType)
+		if contentEncoding != "" {
+			w.Header().Set(headers.ContentEncoding, contentEncoding)
+		}
+
+		if etag != "" {
+			w.Header().Set(headers.ETag, etag)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(encode(t, contentEncoding, body))
+	}))
+	defer target.Close()
+
+	targetHost := strings.TrimPrefix(target.URL, "http://")
+	original := `{"self":"http://` + targetHost + `/users","docs":"https://docs.example.com"}`
+	rewritten := `{"self":"http://premium.local.com/users","docs":"https://docs.example.com"}`
+
+	output := &strings.Builder{}
+	handler := proxy.NewProxyHandler(
+		proxy.WithHTTPClient(&http.Client{Transport: &http.Transport{}}),
+		proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(config.Mappings{
+			{From: hosts.Parse("http://premium.local.com"), To: hosts.Parse(target.URL)},
+		})),
+		proxy.WithOutput(tui.NewCliOutput(output)),
+	)
+
+	serve := func(t *testing.T, cfg *config.BodyRewriteConfig, method string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), method, "http://premium.local.com/users", nil)
+		require.NoError(t, err)
+
+		req.Host = premiumLocalHost
+		req.Header.Set(headers.AcceptEncoding, "gzip, deflate, br, zstd")
+		helpers.NormaliseRequest(req)
+
+		recorder := httptest.NewRecorder()
+		middleware := proxy.NewBodyRewriteMiddleware(proxy.WithBodyRewriteConfig(cfg))
+		err = infra.Mddleware(middleware, handler).ServeHTTP(server.NewResponseRecorder(recorder), req)
+		require.NoError(t, err)
+
+		return recorder
+	}
+
+	t.Run("rewrites encoded bodies", func(t *testing.T) {
+		for _, encoding := range []string{"", "gzip", "deflate", "br"} {
+			t.Run("encoding "+encoding, func(t *testing.T) {
+				contentType, contentEncoding = "application/json; charset=utf-8", encoding
+
+				recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodGet)
+
+				assert.Equal(t, "gzip, deflate, br", acceptEncoding)
+				assert.Equal(t, encoding, recorder.Header().Get(headers.ContentEncoding))
+				assert.Equal(t, strconv.Itoa(recorder.Body.Len()), recorder.Header().Get(headers.ContentLength))
+				assert.Equal(t, rewritten, decode(t, encoding, recorder.Body.Bytes()))
+			})
+		}
+	})
+
+	t.Run("weakens the ETag of rewritten bodies", func(t *testing.T) {
+		contentType, contentEncoding, etag = "application/json", "", `"v1"`
+		defer func() { etag = "" }()
+
+		recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodGet)
+
+		assert.Equal(t, rewritten, recorder.Body.String())
+		assert.Equal(t, `W/"v1"`, recorder.Header().Get(headers.ETag))
+	})
+
+	t.Run("keeps the ETag of unchanged bodies", func(t *testing.T) {
+		contentType, contentEncoding, etag = "application/octet-stream", "", `"v1"`
+		defer func() { etag = "" }()
+
+		recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodGet)
+
+		assert.Equal(t, original, recorder.Body.String())
+		assert.Equal(t, `"v1"`, recorder.Header().Get(headers.ETag))
+	})
+
+	t.Run("passes through bodies with unsupported encodings", func(t *testing.T) {
+		contentType, contentEncoding = "application/json", "zstd"
+		output.Reset()
+
+		recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodGet)
+
+		assert.Equal(t, "zstd", recorder.Header().Get(headers.ContentEncoding))
+		assert.Equal(t, original, recorder.Body.String())
+		assert.Contains(t, output.String(), "Body of http://premium.local.com/users is not rewritten: "+
+			"zstd encoding is not supported")
+	})
+
+	t.Run("keeps other content types", func(t *testing.T) {
+		contentType, contentEncoding = "application/octet-stream", ""
+
+		recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodGet)
+
+		assert.Equal(t, original, recorder.Body.String())
+	})
+
+	t.Run("uses configured content types", func(t *testing.T) {
+		contentType, contentEncoding = "application/vnd.custom", ""
+
+		recorder := serve(t, &config.BodyRewriteConfig{
+			Enabled:      true,
+			ContentTypes: []string{"application/vnd.*"},
+		}, http.MethodGet)
+
+		assert.Equal(t, rewritten, recorder.Body.String())
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		contentType, contentEncoding = "application/json", ""
+
+		recorder := serve(t, &config.BodyRewriteConfig{}, http.MethodGet)
+
+		assert.Equal(t, "gzip, deflate, br, zstd", acceptEncoding)
+		assert.Equal(t, original, recorder.Body.String())
+	})
+
+	t.Run("skips HEAD requests", func(t *testing.T) {
+		contentType, contentEncoding = "application/json", ""
+
+		recorder := serve(t, &config.BodyRewriteConfig{Enabled: true}, http.MethodHead)
+
+		assert.Empty(t, recorder.Header().Get(headers.ContentLength))
+		assert.Empty(t, recorder.Body.String())
+	})
+}
@@ -3,25 +3,29 @@ package proxy
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/handler/rewrite"
 	"github.com/evg4b/uncors/internal/helpers"
-	"github.com/evg4b/uncors/internal/infra"
 	"github.com/evg4b/uncors/internal/urlreplacer"
 	"github.com/evg4b/uncors/pkg/urlt"
 	"github.com/go-http-utils/headers"
 )
 
 type Handler struct {
-	replacers urlreplacer.ReplacerFactory
-	http      contracts.HTTPClient
-	output    contracts.Output
+	replacers       urlreplacer.ReplacerFactory
+	http            contracts.HTTPClient
+	dialer          Dialer
+	output          contracts.Output
+	webSocketPrefix string
 }
 
 func NewProxyHandler(options ...HandlerOption) *Handler {
-	middleware := helpers.ApplyOptions(&Handler{}, options)
+	middleware := helpers.ApplyOptions(&Handler{dialer: &net.Dialer{}}, options)
 
 	helpers.AssertIsDefined(middleware.replacers, "ProxyHandler: ReplacerFactory is not configured")
 	helpers.AssertIsDefined(middleware.output, "ProxyHandler: Output is not configured")
@@ -45,17 +49,24 @@ func (h *Handler) ServeHTTP(response contracts.ResponseWriter, request *contract
 	return nil
 }
 
-func (h *Handler) handle(resp http.ResponseWriter, req *http.Request) error {
+func (h *Handler) handle(resp contracts.ResponseWriter, req *http.Request) error {
 	targetReplacer, sourceReplacer, err := h.createReplacers(req)
 	if err != nil {
 		return err
 	}
 
+	if isWebSocketRequest(req) {
+		return h.handleWebSocket(resp, req, targetReplacer, sourceReplacer)
+	}
+
 	originalRequest, err := h.makeOriginalRequest(req, targetReplacer)
 	if err != nil {
 		return fmt.Errorf("failed to create request to original source: %w", err)
 	}
 
+	originalRequest, tracer := traceUpstream(originalRequest)
+	defer tracer.finish()
+
 	originalResponse, err := h.executeQuery(originalRequest)
 	if err != nil {
 		return err
@@ -96,10 +107,76 @@ func (h *Handler) makeOriginalRequest(
 
 	copyCookiesToTarget(req, replacer, originalRequest)
 
+	if getBodyRewrite(req) != nil {
+		filterAcceptEncoding(originalRequest.Header)
+	}
+
 	return originalRequest, nil
 }
 
 func (h *Handler) makeUncorsResponse(
+	original *http.Response,
+	target contracts.ResponseWriter,
+	replacer *urlreplacer.Replacer,
+	req *http.Request,
+) error {
+	err := h.writeResponseHeaders(original, target, replacer, req)
+	if err != nil {
+		return err
+	}
+
+	if cfg := getBodyRewrite(req); cfg != nil && canRewriteBody(cfg, req, original) {
+		if canDecodeBody(original) {
+			return h.writeRewrittenBody(original, target, replacer)
+		}
+
+		h.output.Warnf("Body of %s is not rewritten: %s encoding is not supported", req.URL, contentEncoding(original))
+	}
+
+	target.WriteHeader(original.StatusCode)
+
+	if isStreamingResponse(original) {
+		err = streamBody(target, original.Body)
+	} else {
+		_, err = io.Copy(target, original.Body)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to copy body to response: %w", err)
+	}
+
+	return nil
+}
+
+// writeRewrittenBody buffers the body to replace target URLs and sets the
+// Content-Length of the rewritten body. The ETag of a changed body is made
+// weak.
+func (h *Handler) writeRewrittenBody(
+	original *http.Response,
+	target contracts.ResponseWriter,
+	replacer *urlreplacer.Replacer,
+) error {
+	body, changed, err := rewriteBody(original, replacer)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite response body: %w", err)
+	}
+
+	if changed {
+		weakenETag(target.Header())
+	}
+
+	target.Header().Set(headers.ContentLength, strconv.Itoa(len(body)))
+	target.WriteHeader(original.StatusCode)
+
+	_, err = target.Write(body)
+	if err != nil {
+		return fmt.Errorf("failed to copy body to response: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) writeResponseHeaders(
 	original *http.Response,
 	target http.ResponseWriter,
 	replacer *urlreplacer.Replacer,
@@ -116,15 +193,7 @@ func (h *Handler) makeUncorsResponse(
 		return err
 	}
 
-	origin := req.Header.Get(headers.Origin)
-	infra.WriteCorsHeaders(target.Header(), origin)
-
-	target.WriteHeader(original.StatusCode)
-
-	_, err = io.Copy(target, original.Body)
-	if err != nil {
-		return fmt.Errorf("failed to copy body to response: %w", err)
-	}
+	cors.WriteHeaders(target.Header(), req)
 
 	return nil
 }
@@ -185,3 +254,15 @@ func WithOutput(output contracts.Output) HandlerOption {
 		m.output = output
 	}
 }
+
+func WithDialer(dialer Dialer) HandlerOption {
+	return func(m *Handler) {
+		m.dialer = dialer
+	}
+}
+
+func WithWebSocketPrefix(prefix string) HandlerOption {
+	return func(m *Handler) {
+		m.webSocketPrefix = prefix
+	}
+}
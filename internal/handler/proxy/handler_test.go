@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/handler/proxy"
 	"github.com/evg4b/uncors/internal/handler/rewrite"
 	"github.com/evg4b/uncors/internal/helpers"
@@ -434,3 +435,61 @@ func TestProxyHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestProxyHandlerUpstreamTrace(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+
+	handler := proxy.NewProxyHandler(
+		proxy.WithHTTPClient(&http.Client{Transport: &http.Transport{}}),
+		proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(config.Mappings{
+			{From: hosts.Parse("http://premium.local.com"), To: hosts.Parse(target.URL)},
+		})),
+		proxy.WithOutput(mocks.NoopOutput()),
+	)
+
+	t.Run("fills the trace from the request context", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://premium.local.com/api?page=2", nil)
+		require.NoError(t, err)
+
+		req.Host = premiumLocalHost
+		req.Header.Set("X-Custom", "value")
+		helpers.NormaliseRequest(req)
+
+		trace := &contracts.UpstreamTrace{}
+		req = req.WithContext(context.WithValue(req.Context(), contracts.UpstreamKey, trace))
+
+		err = handler.ServeHTTP(server.NewResponseRecorder(httptest.NewRecorder()), req)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodGet, trace.Method)
+		assert.Equal(t, target.URL+"/api?page=2", trace.URL)
+		assert.Equal(t, strings.TrimPrefix(target.URL, "http://"), trace.Header.Get("Host"))
+		assert.Equal(t, "value", trace.Header.Get("X-Custom"))
+		assert.Equal(t, "127.0.0.1", trace.ServerIPAddress)
+		assert.NotEmpty(t, trace.LocalAddress)
+
+		assert.False(t, trace.ConnectStart.IsZero())
+		assert.False(t, trace.ConnectDone.Before(trace.ConnectStart))
+		assert.False(t, trace.WroteRequest.Before(trace.GotConn))
+		assert.False(t, trace.FirstByte.Before(trace.WroteRequest))
+		assert.False(t, trace.Done.Before(trace.FirstByte))
+	})
+
+	t.Run("skips tracing without a trace in the context", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://premium.local.com/api", nil)
+		require.NoError(t, err)
+
+		req.Host = premiumLocalHost
+		helpers.NormaliseRequest(req)
+
+		recorder := httptest.NewRecorder()
+		err = handler.ServeHTTP(server.NewResponseRecorder(recorder), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ok", recorder.Body.String())
+	})
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/go-http-utils/headers"
+)
+
+const streamBufferSize = 32 * 1024
+
+// isStreamingResponse reports whether the upstream body should be delivered
+// to the client as it arrives: Server-Sent Events and responses without a
+// known length (chunked long-poll streams).
+func isStreamingResponse(response *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get(headers.ContentType))
+	if mediaType == "text/event-stream" {
+		return true
+	}
+
+	return response.ContentLength == -1
+}
+
+// streamBody copies body into target flushing after every chunk read from
+// the upstream so that clients do not receive events in bursts.
+func streamBody(target contracts.ResponseWriter, body io.Reader) error {
+	target.Flush()
+
+	buffer := make([]byte, streamBufferSize)
+
+	for {
+		read, readErr := body.Read(buffer)
+		if read > 0 {
+			_, err := target.Write(buffer[:read])
+			if err != nil {
+				return err
+			}
+
+			target.Flush()
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package proxy_test
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/proxy"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/internal/urlreplacer"
+	"github.com/evg4b/uncors/testing/hosts"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamLocalHost = "stream.local.com"
+	streamTimeout   = 5 * time.Second
+)
+
+func TestProxyHandlerStreaming(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "server-sent events", contentType: "text/event-stream; charset=utf-8"},
+		{name: "chunked response", contentType: "application/x-ndjson"},
+	}
+
+	for _, testCase := range tests {
+		t.Run("should deliver chunks as they arrive for "+testCase.name, func(t *testing.T) {
+			release := make(chan struct{})
+
+			backend := testutils.NewServer(t, http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				writer.Header().Set(headers.ContentType, testCase.contentType)
+				writer.WriteHeader(http.StatusOK)
+
+				fmt.Fprint(writer, "data: first\n\n")
+				http.NewResponseController(writer).Flush() //nolint:errcheck
+
+				select {
+				case <-release:
+				case <-time.After(2 * streamTimeout):
+				}
+
+				fmt.Fprint(writer, "data: second\n\n")
+			}))
+			defer backend.Close()
+
+			handler := proxy.NewProxyHandler(
+				proxy.WithHTTPClient(http.DefaultClient),
+				proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(config.Mappings{
+					{From: hosts.Parse("http://" + streamLocalHost), To: hosts.Parse(backend.URL)},
+				})),
+				proxy.WithOutput(mocks.NoopOutput()),
+			)
+
+			proxyServer := testutils.NewServer(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				helpers.NormaliseRequest(request)
+
+				recorder := server.NewResponseRecorder(writer)
+				recorder.EnableBodyCapture()
+
+				_ = handler.ServeHTTP(recorder, request)
+			}))
+			t.Cleanup(func() {
+				_ = proxyServer.Close()
+			})
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, proxyServer.URL+"/events", nil)
+			require.NoError(t, err)
+			req.Host = streamLocalHost
+
+			client := &http.Client{Timeout: streamTimeout}
+
+			response, err := client.Do(req)
+			require.NoError(t, err)
+
+			defer response.Body.Close()
+
+			lines := make(chan string)
+
+			go func() {
+				scanner := bufio.NewScanner(response.Body)
+				for scanner.Scan() {
+					if scanner.Text() != "" {
+						lines <- scanner.Text()
+					}
+				}
+
+				close(lines)
+			}()
+
+			for index, expected := range []string{"data: first", "data: second"} {
+				select {
+				case line := <-lines:
+					assert.Equal(t, expected, line)
+				case <-time.After(streamTimeout):
+					t.Fatalf("chunk %d was not delivered before upstream finished", index)
+				}
+
+				if index == 0 {
+					close(release)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/evg4b/uncors/internal/contracts"
+)
+
+// upstreamTracer records the phases of an upstream request. Transport
+// callbacks can run on other goroutines, e.g. for parallel dial attempts, so
+// the trace is collected under a mutex and copied to the request context only
+// when the proxy is done.
+type upstreamTracer struct {
+	mutex  sync.Mutex
+	trace  contracts.UpstreamTrace
+	target *contracts.UpstreamTrace
+}
+
+// traceUpstream attaches an httptrace.ClientTrace to the upstream request when
+// the inbound request carries a contracts.UpstreamTrace. It returns nil when
+// nobody asked for the trace.
+func traceUpstream(req *http.Request) (*http.Request, *upstreamTracer) {
+	target, ok := req.Context().Value(contracts.UpstreamKey).(*contracts.UpstreamTrace)
+	if !ok || target == nil {
+		return req, nil
+	}
+
+	tracer := &upstreamTracer{
+		target: target,
+		trace: contracts.UpstreamTrace{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: http.Header{},
+		},
+	}
+
+	ctx := httptrace.WithClientTrace(req.Context(), tracer.clientTrace())
+
+	return req.WithContext(ctx), tracer
+}
+
+func (t *upstreamTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mark(&t.trace.Start)
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.trace.DNSStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mark(&t.trace.DNSDone)
+		},
+		ConnectStart: func(string, string) {
+			t.update(func(trace *contracts.UpstreamTrace) {
+				// Parallel dial attempts report their own start, only the
+				// first one counts.
+				if trace.ConnectStart.IsZero() {
+					trace.ConnectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.mark(&t.trace.ConnectDone)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.trace.TLSStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.trace.TLSDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.update(func(trace *contracts.UpstreamTrace) {
+				trace.GotConn = time.Now()
+				trace.ConnectionReused = info.Reused
+
+				if info.Conn == nil {
+					return
+				}
+
+				if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+					trace.ServerIPAddress = addr.IP.String()
+				}
+
+				trace.LocalAddress = info.Conn.LocalAddr().String()
+			})
+		},
+		WroteHeaderField: func(key string, values []string) {
+			t.update(func(trace *contracts.UpstreamTrace) {
+				trace.Header[http.CanonicalHeaderKey(key)] = append(trace.Header[http.CanonicalHeaderKey(key)], values...)
+			})
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.trace.WroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.mark(&t.trace.FirstByte)
+		},
+	}
+}
+
+func (t *upstreamTracer) mark(field *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	*field = time.Now()
+}
+
+func (t *upstreamTracer) update(apply func(trace *contracts.UpstreamTrace)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	apply(&t.trace)
+}
+
+// finish marks the end of the response body and publishes the trace.
+func (t *upstreamTracer) finish() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.trace.Done = time.Now()
+	*t.target = t.trace
+	t.target.Header = t.trace.Header.Clone()
+}
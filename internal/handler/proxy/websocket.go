@@ -0,0 +1,217 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/urlreplacer"
+	"github.com/go-http-utils/headers"
+)
+
+var ErrWebSocketNotSupported = errors.New("response writer does not support websocket upgrade")
+
+// Dialer opens raw connections to the upstream for protocol upgrades which
+// can not be served through contracts.HTTPClient.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+func isWebSocketRequest(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get(headers.Upgrade), "websocket") &&
+		headerHasToken(req.Header, "Connection", "upgrade")
+}
+
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for part := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) handleWebSocket(
+	resp contracts.ResponseWriter,
+	req *http.Request,
+	targetReplacer *urlreplacer.Replacer,
+	sourceReplacer *urlreplacer.Replacer,
+) error {
+	tunnel := infra.HandlerFunc(func(writer contracts.ResponseWriter, request *contracts.Request) error {
+		return h.tunnelWebSocket(writer, request, targetReplacer, sourceReplacer)
+	})
+
+	if h.webSocketPrefix == "" {
+		return tunnel.ServeHTTP(resp, req)
+	}
+
+	return infra.WithPrefix(h.webSocketPrefix, tunnel).ServeHTTP(resp, req)
+}
+
+func (h *Handler) tunnelWebSocket(
+	resp contracts.ResponseWriter,
+	req *http.Request,
+	targetReplacer *urlreplacer.Replacer,
+	sourceReplacer *urlreplacer.Replacer,
+) error {
+	hijacker, ok := resp.(http.Hijacker)
+	if !ok {
+		return ErrWebSocketNotSupported
+	}
+
+	originalRequest, err := h.makeOriginalRequest(req, targetReplacer)
+	if err != nil {
+		return fmt.Errorf("failed to create request to original source: %w", err)
+	}
+
+	upstream, err := h.dialUpstream(req.Context(), originalRequest)
+	if err != nil {
+		return err
+	}
+
+	defer upstream.Close()
+
+	err = originalRequest.Write(upstream)
+	if err != nil {
+		return fmt.Errorf("failed to send websocket handshake: %w", err)
+	}
+
+	upstreamReader := bufio.NewReader(upstream)
+
+	originalResponse, err := http.ReadResponse(upstreamReader, originalRequest)
+	if err != nil {
+		return fmt.Errorf("failed to read websocket handshake: %w", err)
+	}
+
+	defer helpers.CloseSafe(originalResponse.Body)
+
+	if originalResponse.StatusCode != http.StatusSwitchingProtocols {
+		return h.makeUncorsResponse(originalResponse, resp, sourceReplacer, req)
+	}
+
+	err = h.writeResponseHeaders(originalResponse, resp, sourceReplacer, req)
+	if err != nil {
+		return err
+	}
+
+	client, clientBuffer, err := hijacker.Hijack()
+	if err != nil {
+		return fmt.Errorf("failed to hijack client connection: %w", err)
+	}
+
+	defer client.Close()
+
+	err = writeSwitchingProtocols(clientBuffer.Writer, resp.Header())
+	if err != nil {
+		// The connection is already hijacked, so there is no way to report
+		// the error to the client.
+		h.output.Errorf("Failed to complete websocket handshake: %v", err)
+
+		return nil
+	}
+
+	stop := context.AfterFunc(req.Context(), func() {
+		_ = client.Close()
+		_ = upstream.Close()
+	})
+	defer stop()
+
+	pump(client, clientBuffer.Reader, upstream, upstreamReader)
+
+	return nil
+}
+
+func (h *Handler) dialUpstream(ctx context.Context, request *http.Request) (net.Conn, error) {
+	secure := strings.EqualFold(request.URL.Scheme, "https")
+
+	address := request.URL.Host
+	if request.URL.Port() == "" {
+		port := "80"
+		if secure {
+			port = "443"
+		}
+
+		address = net.JoinHostPort(request.URL.Hostname(), port)
+	}
+
+	conn, err := h.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	if !secure {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName: request.URL.Hostname(),
+		MinVersion: tls.VersionTLS12,
+	})
+
+	err = tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to establish tls connection to %s: %w", address, err)
+	}
+
+	return tlsConn, nil
+}
+
+func writeSwitchingProtocols(writer *bufio.Writer, header http.Header) error {
+	_, err := fmt.Fprintf(writer, "HTTP/1.1 %d %s\r\n",
+		http.StatusSwitchingProtocols,
+		http.StatusText(http.StatusSwitchingProtocols),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = header.Write(writer)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.WriteString("\r\n")
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// pump copies frames in both directions until either side closes its
+// connection. Readers are passed separately because both sides may already
+// hold buffered bytes read during the handshake.
+func pump(client net.Conn, clientReader io.Reader, upstream net.Conn, upstreamReader io.Reader) {
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(upstream, clientReader)
+		done <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(client, upstreamReader)
+		done <- struct{}{}
+	}()
+
+	<-done
+
+	_ = client.Close()
+	_ = upstream.Close()
+
+	<-done
+}
@@ -0,0 +1,219 @@
+package proxy_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/proxy"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/internal/urlreplacer"
+	"github.com/evg4b/uncors/testing/hosts"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	wsLocalHost = "ws.local.com"
+	wsTimeout   = 5 * time.Second
+)
+
+// echoUpgradeHandler accepts any upgrade request and echoes raw bytes back.
+func echoUpgradeHandler(t *testing.T, received chan<- http.Header) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received <- request.Header.Clone()
+
+		conn, buf, err := http.NewResponseController(writer).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		defer conn.Close()
+
+		fmt.Fprint(buf, "HTTP/1.1 101 Switching Protocols\r\n")
+		fmt.Fprint(buf, "Upgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+
+		if !assert.NoError(t, buf.Flush()) {
+			return
+		}
+
+		_, _ = io.Copy(conn, buf)
+	})
+}
+
+func newWebSocketProxy(t *testing.T, backendURL string, tracker func(string)) *testutils.TestServer {
+	t.Helper()
+
+	handler := proxy.NewProxyHandler(
+		proxy.WithHTTPClient(http.DefaultClient),
+		proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(config.Mappings{
+			{From: hosts.Parse("http://" + wsLocalHost), To: hosts.Parse(backendURL)},
+		})),
+		proxy.WithOutput(mocks.NoopOutput()),
+		proxy.WithWebSocketPrefix("WS"),
+	)
+
+	proxyServer := testutils.NewServer(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		helpers.NormaliseRequest(request)
+
+		if tracker != nil {
+			request = request.WithContext(context.WithValue(request.Context(), contracts.PrefixUpdaterKey, tracker))
+		}
+
+		_ = handler.ServeHTTP(server.NewResponseRecorder(writer), request)
+	}))
+	t.Cleanup(func() {
+		_ = proxyServer.Close()
+	})
+
+	return proxyServer
+}
+
+func dialWebSocket(t *testing.T, proxyURL string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(t.Context(), "tcp", strings.TrimPrefix(proxyURL, "http://"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(wsTimeout)))
+
+	fmt.Fprintf(conn, "GET /socket HTTP/1.1\r\n")
+	fmt.Fprintf(conn, "Host: %s\r\n", wsLocalHost)
+	fmt.Fprintf(conn, "Origin: http://%s\r\n", wsLocalHost)
+	fmt.Fprint(conn, "Connection: Upgrade\r\nUpgrade: websocket\r\n")
+	fmt.Fprint(conn, "Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+
+	response, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+
+	return conn, reader, response
+}
+
+func TestProxyHandlerWebSocket(t *testing.T) {
+	t.Run("should tunnel frames in both directions", func(t *testing.T) {
+		received := make(chan http.Header, 1)
+		backend := testutils.NewServer(t, echoUpgradeHandler(t, received))
+		defer backend.Close()
+
+		proxyServer := newWebSocketProxy(t, backend.URL, nil)
+
+		conn, reader, response := dialWebSocket(t, proxyServer.URL)
+
+		assert.Equal(t, http.StatusSwitchingProtocols, response.StatusCode)
+		assert.Equal(t, "websocket", response.Header.Get(headers.Upgrade))
+
+		for _, message := range []string{"ping", "hello world"} {
+			_, err := conn.Write([]byte(message))
+			require.NoError(t, err)
+
+			buffer := make([]byte, len(message))
+			_, err = io.ReadFull(reader, buffer)
+			require.NoError(t, err)
+
+			assert.Equal(t, message, string(buffer))
+		}
+	})
+
+	t.Run("should rewrite handshake headers for target", func(t *testing.T) {
+		received := make(chan http.Header, 1)
+		backend := testutils.NewServer(t, echoUpgradeHandler(t, received))
+		defer backend.Close()
+
+		proxyServer := newWebSocketProxy(t, backend.URL, nil)
+
+		dialWebSocket(t, proxyServer.URL)
+
+		select {
+		case header := <-received:
+			assert.Equal(t, backend.URL, header.Get(headers.Origin))
+			assert.Equal(t, "websocket", header.Get(headers.Upgrade))
+			assert.Equal(t, "dGhlIHNhbXBsZSBub25jZQ==", header.Get("Sec-WebSocket-Key"))
+		case <-time.After(wsTimeout):
+			t.Fatal("backend did not receive handshake")
+		}
+	})
+
+	t.Run("should report websocket prefix", func(t *testing.T) {
+		received := make(chan http.Header, 1)
+		backend := testutils.NewServer(t, echoUpgradeHandler(t, received))
+		defer backend.Close()
+
+		prefixes := make(chan string, 1)
+		proxyServer := newWebSocketProxy(t, backend.URL, func(prefix string) {
+			prefixes <- prefix
+		})
+
+		dialWebSocket(t, proxyServer.URL)
+
+		select {
+		case prefix := <-prefixes:
+			assert.Equal(t, "WS", prefix)
+		case <-time.After(wsTimeout):
+			t.Fatal("prefix was not reported")
+		}
+	})
+
+	t.Run("should forward rejected handshake as regular response", func(t *testing.T) {
+		backend := testutils.NewServer(t, http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(writer, "forbidden")
+		}))
+		defer backend.Close()
+
+		proxyServer := newWebSocketProxy(t, backend.URL, nil)
+
+		_, _, response := dialWebSocket(t, proxyServer.URL)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "forbidden", string(body))
+	})
+
+	t.Run("should return error when writer can not be hijacked", func(t *testing.T) {
+		received := make(chan http.Header, 1)
+		backend := testutils.NewServer(t, echoUpgradeHandler(t, received))
+		defer backend.Close()
+
+		handler := proxy.NewProxyHandler(
+			proxy.WithHTTPClient(http.DefaultClient),
+			proxy.WithURLReplacerFactory(urlreplacer.NewURLReplacerFactory(config.Mappings{
+				{From: hosts.Parse("http://" + wsLocalHost), To: hosts.Parse(backend.URL)},
+			})),
+			proxy.WithOutput(mocks.NoopOutput()),
+		)
+
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://"+wsLocalHost+"/socket", nil)
+		req.Header.Set("Connection", "keep-alive, Upgrade")
+		req.Header.Set(headers.Upgrade, "websocket")
+		helpers.NormaliseRequest(req)
+
+		err := handler.ServeHTTP(server.NewResponseRecorder(httptest.NewRecorder()), req)
+
+		require.ErrorIs(t, err, server.ErrHijackNotSupported)
+	})
+}
@@ -0,0 +1,235 @@
+package record
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/pkg/urlt"
+	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
+)
+
+// skippedHeaders are not saved because they describe the upstream
+// connection or are added again by uncors when the mock is served.
+var skippedHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Date":              true,
+	"Keep-Alive":        true,
+	"Set-Cookie":        true,
+	"Transfer-Encoding": true,
+}
+
+// Mock is a generated mock definition. It is a subset of config.Mock that is
+// written without empty fields.
+type Mock struct {
+	Path     string            `yaml:"path"`
+	Method   string            `yaml:"method"`
+	Queries  map[string]string `yaml:"queries,omitempty"`
+	Body     []BodyMatcher     `yaml:"body,omitempty"`
+	Response Response          `yaml:"response"`
+}
+
+type BodyMatcher struct {
+	Equals string `yaml:"equals"`
+}
+
+type Response struct {
+	Code    int               `yaml:"code"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	File    string            `yaml:"file"`
+}
+
+// Collection turns responses into mocks deduplicated by request key. The
+// response bodies are written to the responses folder of dir. It is not safe
+// for concurrent use.
+type Collection struct {
+	fs  afero.Fs
+	dir string
+
+	mocks []Mock
+	keys  map[string]int
+}
+
+func NewCollection(fs afero.Fs, dir string) *Collection {
+	return &Collection{
+		fs:   fs,
+		dir:  dir,
+		keys: map[string]int{},
+	}
+}
+
+// Add saves the response body and adds a mock for the request. A mock with
+// the same key is replaced. Compressed bodies are saved decompressed when the
+// encoding is supported.
+func (c *Collection) Add(request *http.Request, body []byte, capture contracts.ResponseCapture) error {
+	capture = decodeCapture(capture)
+
+	mock := Mock{
+		Path:    request.URL.Path,
+		Method:  request.Method,
+		Queries: firstValues(urlt.URL_Query(request.URL)),
+		Response: Response{
+			Code:    capture.StatusCode,
+			Headers: responseHeaders(capture.Header),
+		},
+	}
+
+	if len(body) > 0 {
+		mock.Body = []BodyMatcher{{Equals: string(body)}}
+	}
+
+	key := mockKey(&mock)
+	mock.Response.File = filepath.ToSlash(filepath.Join(c.dir, responsesDir, fileName(&mock, key, capture.Header)))
+
+	err := c.fs.MkdirAll(filepath.Join(c.dir, responsesDir), dirMode)
+	if err != nil {
+		return err
+	}
+
+	err = afero.WriteFile(c.fs, filepath.FromSlash(mock.Response.File), capture.Body, fileMode)
+	if err != nil {
+		return err
+	}
+
+	c.put(mock)
+
+	return nil
+}
+
+// decodeCapture decompresses the captured body, so the saved file is readable
+// and the mock is not served with a Content-Encoding it no longer has.
+func decodeCapture(capture contracts.ResponseCapture) contracts.ResponseCapture {
+	encoding := capture.Header.Get(headers.ContentEncoding)
+	if encoding == "" {
+		return capture
+	}
+
+	decoded, ok := har.DecodeBody(capture.Body, encoding)
+	if !ok {
+		return capture
+	}
+
+	capture.Body = decoded
+	capture.Header = capture.Header.Clone()
+	capture.Header.Del(headers.ContentEncoding)
+
+	return capture
+}
+
+// Mocks returns the collected mocks in the order they were first added.
+func (c *Collection) Mocks() []Mock {
+	return slices.Clone(c.mocks)
+}
+
+func (c *Collection) put(mock Mock) {
+	key := mockKey(&mock)
+
+	if position, ok := c.keys[key]; ok {
+		c.mocks[position] = mock
+
+		return
+	}
+
+	c.keys[key] = len(c.mocks)
+	c.mocks = append(c.mocks, mock)
+}
+
+// mockKey identifies a request by method, path, query and body.
+func mockKey(mock *Mock) string {
+	var builder strings.Builder
+
+	builder.WriteString(mock.Method + " " + mock.Path)
+
+	for _, key := range slices.Sorted(maps.Keys(mock.Queries)) {
+		builder.WriteString("\n" + key + "=" + mock.Queries[key])
+	}
+
+	for _, body := range mock.Body {
+		builder.WriteString("\n\n" + body.Equals)
+	}
+
+	hash := sha256.Sum256([]byte(builder.String()))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// fileName builds a readable unique file name like get-api-users-1a2b3c4d.json.
+func fileName(mock *Mock, key string, header http.Header) string {
+	slug := strings.Map(func(char rune) rune {
+		if char >= 'a' && char <= 'z' || char >= '0' && char <= '9' {
+			return char
+		}
+
+		return '-'
+	}, strings.ToLower(strings.Trim(mock.Path, "/")))
+
+	parts := []string{strings.ToLower(mock.Method)}
+	if slug != "" {
+		parts = append(parts, slug)
+	}
+
+	parts = append(parts, key[:hashLength])
+
+	return strings.Join(parts, "-") + extension(mock.Path, header)
+}
+
+func extension(requestPath string, header http.Header) string {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err == nil {
+		if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+			if ext := path.Ext(requestPath); slices.Contains(extensions, ext) {
+				return ext
+			}
+
+			return extensions[0]
+		}
+	}
+
+	if ext := path.Ext(requestPath); ext != "" {
+		return ext
+	}
+
+	return defaultExt
+}
+
+func responseHeaders(header http.Header) map[string]string {
+	result := map[string]string{}
+
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if skippedHeaders[canonical] || strings.HasPrefix(canonical, "Access-Control-") {
+			continue
+		}
+
+		result[canonical] = strings.Join(values, ", ")
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+func firstValues(values map[string][]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for key, items := range values {
+		result[key] = items[0]
+	}
+
+	return result
+}
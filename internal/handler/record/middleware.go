@@ -0,0 +1,66 @@
+package record
+
+import (
+	"log"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+)
+
+// Middleware records responses of requests whose path matches one of the
+// configured globs. All requests are recorded when no globs are set.
+type Middleware struct {
+	recorder *Recorder
+	paths    []string
+}
+
+func NewMiddleware(options ...MiddlewareOption) *Middleware {
+	middleware := helpers.ApplyOptions(&Middleware{}, options)
+
+	helpers.AssertIsDefined(middleware.recorder, "Recorder is not configured")
+
+	return middleware
+}
+
+func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request, next contracts.Next) error {
+	if !m.matches(request) {
+		return next(writer, request)
+	}
+
+	// Bodies above the limit are not matched, the mock is recorded without
+	// a body condition.
+	body, _, err := helpers.PeekBody(request)
+	if err != nil {
+		return err
+	}
+
+	writer.EnableBodyCapture()
+
+	err = next(writer, request)
+
+	capture := writer.Captured()
+	if err != nil || capture.Truncated || capture.StatusCode == 0 {
+		return err
+	}
+
+	if recordErr := m.recorder.Record(request, body, capture); recordErr != nil {
+		log.Printf("record: failed to save response for %s %s: %v", request.Method, request.URL.Path, recordErr)
+	}
+
+	return nil
+}
+
+func (m *Middleware) matches(request *contracts.Request) bool {
+	if len(m.paths) == 0 {
+		return true
+	}
+
+	for _, pattern := range m.paths {
+		if ok, _ := doublestar.PathMatch(pattern, request.URL.Path); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,242 @@
+package record_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/record"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	recordDir = "recordings"
+	mocksFile = "recordings/mocks.yaml"
+)
+
+func upstream(body string) contracts.Handler {
+	return infra.HandlerFunc(func(writer contracts.ResponseWriter, request *contracts.Request) error {
+		requestBody, _ := io.ReadAll(request.Body)
+
+		writer.Header().Set(headers.ContentType, "application/json")
+		writer.Header().Set(headers.ContentLength, "42")
+		writer.Header().Set(headers.AccessControlAllowOrigin, "*")
+		writer.Header().Set("X-Upstream", "yes")
+		writer.WriteHeader(http.StatusOK)
+		fmt.Fprint(writer, body+string(requestBody))
+
+		return nil
+	})
+}
+
+func serve(t *testing.T, handler contracts.Handler, method, url, body string) string {
+	t.Helper()
+
+	var requestBody io.Reader = http.NoBody
+	if body != "" {
+		requestBody = strings.NewReader(body)
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequestWithContext(t.Context(), method, url, requestBody)
+	require.NoError(t, handler.ServeHTTP(server.NewResponseRecorder(recorder), request))
+
+	return testutils.ReadBody(t, recorder)
+}
+
+func newHandler(t *testing.T, fs afero.Fs, body string, options ...record.MiddlewareOption) contracts.Handler {
+	t.Helper()
+
+	recorder, err := record.NewRecorder(fs, recordDir)
+	require.NoError(t, err)
+
+	middleware := record.NewMiddleware(append([]record.MiddlewareOption{record.WithRecorder(recorder)}, options...)...)
+
+	return infra.Mddleware(middleware, upstream(body))
+}
+
+func readFile(t *testing.T, fs afero.Fs, path string) string {
+	t.Helper()
+
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("saves response and mock definition", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		handler := newHandler(t, fs, `{"id":1}`)
+
+		body := serve(t, handler, http.MethodGet, "http://localhost/api/users?page=2", "")
+
+		assert.Equal(t, `{"id":1}`, body)
+		assert.Equal(t, `mocks:
+  - path: /api/users
+    method: GET
+    queries:
+      page: "2"
+    response:
+      code: 200
+      headers:
+        Content-Type: application/json
+        X-Upstream: "yes"
+      file: recordings/responses/get-api-users-137410fd.json
+`, readFile(t, fs, mocksFile))
+		assert.JSONEq(t, `{"id":1}`, readFile(t, fs, "recordings/responses/get-api-users-137410fd.json"))
+	})
+
+	t.Run("matches request body", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		handler := newHandler(t, fs, "echo ")
+
+		body := serve(t, handler, http.MethodPost, "http://localhost/login", "user=john")
+
+		assert.Equal(t, "echo user=john", body)
+		assert.Contains(t, readFile(t, fs, mocksFile), `    body:
+      - equals: user=john
+`)
+	})
+
+	t.Run("does not match bodies larger than the limit", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		handler := newHandler(t, fs, "")
+		large := strings.Repeat("x", helpers.MaxMatchedBodySize+1)
+
+		body := serve(t, handler, http.MethodPost, "http://localhost/upload", large)
+
+		assert.Equal(t, large, body)
+		assert.NotContains(t, readFile(t, fs, mocksFile), "body:")
+	})
+
+	t.Run("deduplicates by request key", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		serve(t, newHandler(t, fs, "first"), http.MethodGet, "http://localhost/api/users", "")
+		serve(t, newHandler(t, fs, "second"), http.MethodGet, "http://localhost/api/users", "")
+		serve(t, newHandler(t, fs, "other"), http.MethodGet, "http://localhost/api/users?page=2", "")
+
+		mocks, err := (&config.RecordConfig{Dir: recordDir}).LoadMocks(fs)
+		require.NoError(t, err)
+		require.Len(t, mocks, 2)
+
+		assert.Empty(t, mocks[0].Matcher.Queries)
+		assert.Equal(t, "second", readFile(t, fs, mocks[0].Response.File))
+		assert.Equal(t, config.ValueMatchers{"page": {Equals: "2"}}, mocks[1].Matcher.Queries)
+		assert.Equal(t, "other", readFile(t, fs, mocks[1].Response.File))
+	})
+
+	t.Run("records only matching paths", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		handler := newHandler(t, fs, "ok", record.WithPaths([]string{"/api/**"}))
+
+		serve(t, handler, http.MethodGet, "http://localhost/assets/app.js", "")
+
+		exists, err := afero.Exists(fs, mocksFile)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		serve(t, handler, http.MethodGet, "http://localhost/api/v1/users", "")
+
+		assert.Contains(t, readFile(t, fs, mocksFile), "path: /api/v1/users")
+	})
+
+	t.Run("saves gzipped responses decompressed", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		var compressed bytes.Buffer
+
+		gzipWriter := gzip.NewWriter(&compressed)
+		_, err := gzipWriter.Write([]byte(`{"id":1}`))
+		require.NoError(t, err)
+		require.NoError(t, gzipWriter.Close())
+
+		recorder, err := record.NewRecorder(fs, recordDir)
+		require.NoError(t, err)
+
+		handler := infra.Mddleware(
+			record.NewMiddleware(record.WithRecorder(recorder)),
+			infra.HandlerFunc(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+				writer.Header().Set(headers.ContentType, "application/json")
+				writer.Header().Set(headers.ContentEncoding, "gzip")
+				writer.WriteHeader(http.StatusOK)
+				_, err := writer.Write(compressed.Bytes())
+
+				return err
+			}),
+		)
+
+		serve(t, handler, http.MethodGet, "http://localhost/api/users", "")
+
+		mocks, err := (&config.RecordConfig{Dir: recordDir}).LoadMocks(fs)
+		require.NoError(t, err)
+		require.Len(t, mocks, 1)
+
+		assert.Equal(t, map[string]string{headers.ContentType: "application/json"}, mocks[0].Response.Headers)
+		assert.JSONEq(t, `{"id":1}`, readFile(t, fs, mocks[0].Response.File))
+	})
+
+	t.Run("recorded mocks are valid configuration", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		handler := newHandler(t, fs, `{"id":1}`)
+
+		serve(t, handler, http.MethodPut, "http://localhost/api/users/1?force=true", `{"name":"John"}`)
+
+		mocks, err := (&config.RecordConfig{Dir: recordDir}).LoadMocks(fs)
+		require.NoError(t, err)
+		require.Len(t, mocks, 1)
+
+		require.NoError(t, mocks[0].Validate("mocks[0]", fs))
+		assert.Equal(t, config.RequestMatcher{
+			Path:    "/api/users/1",
+			Method:  http.MethodPut,
+			Queries: config.ValueMatchers{"force": {Equals: "true"}},
+			Body:    config.BodyMatchers{{ValueMatcher: config.ValueMatcher{Equals: `{"name":"John"}`}}},
+		}, mocks[0].Matcher)
+	})
+}
+
+func TestNewRecorder(t *testing.T) {
+	t.Run("fails on broken mocks file", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{mocksFile: "mocks: {"})
+
+		_, err := record.NewRecorder(fs, recordDir)
+
+		require.Error(t, err)
+	})
+
+	t.Run("keeps previously recorded mocks", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			mocksFile: `mocks:
+  - path: /api/old
+    method: GET
+    response:
+      code: 200
+      file: recordings/responses/old.json
+`,
+		})
+
+		serve(t, newHandler(t, fs, "new"), http.MethodGet, "http://localhost/api/new", "")
+
+		mocks, err := (&config.RecordConfig{Dir: recordDir}).LoadMocks(fs)
+		require.NoError(t, err)
+		require.Len(t, mocks, 2)
+		assert.Equal(t, "/api/old", mocks[0].Matcher.Path)
+		assert.Equal(t, "/api/new", mocks[1].Matcher.Path)
+	})
+}
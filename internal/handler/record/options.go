@@ -0,0 +1,16 @@
+package record
+
+type MiddlewareOption = func(*Middleware)
+
+func WithRecorder(recorder *Recorder) MiddlewareOption {
+	return func(m *Middleware) {
+		m.recorder = recorder
+	}
+}
+
+// WithPaths limits recording to requests whose path matches one of the globs.
+func WithPaths(paths []string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.paths = paths
+	}
+}
@@ -0,0 +1,98 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	responsesDir = "responses"
+	dirMode      = 0o755
+	fileMode     = 0o644
+	hashLength   = 8
+	defaultExt   = ".bin"
+	yamlIndent   = 2
+)
+
+type mocksFile struct {
+	Mocks []Mock `yaml:"mocks"`
+}
+
+// Recorder saves responses as files and keeps the mock definitions file in
+// sync. Requests with the same key replace the previously recorded response.
+type Recorder struct {
+	mutex      sync.Mutex
+	collection *Collection
+}
+
+// NewRecorder creates a recorder that writes to dir. Mocks recorded earlier
+// are loaded so new recordings are added to them.
+func NewRecorder(fs afero.Fs, dir string) (*Recorder, error) {
+	recorder := &Recorder{collection: NewCollection(fs, dir)}
+
+	file, err := fs.Open(recorder.mocksFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return recorder, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var existing mocksFile
+
+	err = yaml.NewDecoder(file).Decode(&existing)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read recorded mocks '%s': %w", recorder.mocksFile(), err)
+	}
+
+	for _, mock := range existing.Mocks {
+		recorder.collection.put(mock)
+	}
+
+	return recorder, nil
+}
+
+// Record saves the response for the request.
+func (r *Recorder) Record(request *http.Request, body []byte, capture contracts.ResponseCapture) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.collection.Add(request, body, capture)
+	if err != nil {
+		return err
+	}
+
+	return r.writeMocks()
+}
+
+func (r *Recorder) writeMocks() error {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(yamlIndent)
+
+	err := encoder.Encode(mocksFile{Mocks: r.collection.Mocks()})
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(r.collection.fs, r.mocksFile(), buffer.Bytes(), fileMode)
+}
+
+func (r *Recorder) mocksFile() string {
+	return filepath.Join(r.collection.dir, config.RecordedMocksFile)
+}
@@ -0,0 +1,235 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/go-http-utils/headers"
+)
+
+var ErrNoRecordedResponse = errors.New("no recorded response")
+
+// skippedRequestHeaders are never compared because clients and proxies
+// change them freely.
+var skippedRequestHeaders = map[string]bool{
+	"Accept-Encoding":   true,
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+}
+
+// skippedResponseHeaders describe the recorded connection and are not
+// replayed.
+var skippedResponseHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+}
+
+type entry struct {
+	method string
+	url    *url.URL
+	header http.Header
+	body   string
+
+	response *har.Response
+	served   bool
+}
+
+// Middleware serves responses recorded in a HAR file. Requests recorded
+// several times get the recorded responses in order, the last one is repeated
+// afterwards. Requests without a recorded response are handled according to
+// the miss behavior.
+type Middleware struct {
+	entries          []*entry
+	ignoreQueryOrder bool
+	matchHeaders     bool
+	ignoreHeaders    []string
+	matchBody        bool
+	onMiss           string
+	prefix           string
+
+	mutex sync.Mutex
+}
+
+func NewMiddleware(options ...MiddlewareOption) *Middleware {
+	return helpers.ApplyOptions(&Middleware{onMiss: config.HARReplayOnMissNotFound}, options)
+}
+
+func (m *Middleware) ServeHTTP(writer contracts.ResponseWriter, request *contracts.Request, next contracts.Next) error {
+	body, ok, err := m.readBody(request)
+	if err != nil {
+		return err
+	}
+
+	var matched *entry
+	if ok {
+		matched = m.find(request, body)
+	}
+
+	if matched != nil {
+		return infra.WithPrefix(m.prefix, infra.HandlerFunc(
+			func(writer contracts.ResponseWriter, request *contracts.Request) error {
+				return writeResponse(writer, request, matched.response)
+			},
+		)).ServeHTTP(writer, request)
+	}
+
+	switch m.onMiss {
+	case config.HARReplayOnMissProxy:
+		return next(writer, request)
+	case config.HARReplayOnMissError:
+		return fmt.Errorf("%w for %s %s", ErrNoRecordedResponse, request.Method, request.URL.RequestURI())
+	default:
+		return infra.WithPrefix(m.prefix, infra.HandlerFunc(writeNotFound)).ServeHTTP(writer, request)
+	}
+}
+
+func (m *Middleware) find(request *contracts.Request, body string) *entry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var last *entry
+
+	for _, candidate := range m.entries {
+		if !m.matches(candidate, request, body) {
+			continue
+		}
+
+		if !candidate.served {
+			candidate.served = true
+
+			return candidate
+		}
+
+		last = candidate
+	}
+
+	return last
+}
+
+func (m *Middleware) matches(candidate *entry, request *contracts.Request, body string) bool {
+	if candidate.method != request.Method || candidate.url.Path != request.URL.Path {
+		return false
+	}
+
+	if !m.queryMatches(candidate.url, request.URL) {
+		return false
+	}
+
+	if m.matchBody && candidate.body != body {
+		return false
+	}
+
+	return !m.matchHeaders || m.headersMatch(candidate.header, request.Header)
+}
+
+func (m *Middleware) queryMatches(recorded, actual *url.URL) bool {
+	if !m.ignoreQueryOrder {
+		return recorded.RawQuery == actual.RawQuery
+	}
+
+	recordedQuery, actualQuery := recorded.Query(), actual.Query()
+	if len(recordedQuery) != len(actualQuery) {
+		return false
+	}
+
+	for key, values := range recordedQuery {
+		if !slices.Equal(values, actualQuery[key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// headersMatch checks that every recorded header has the same value in the
+// request. Headers missing in the HAR file are not compared.
+func (m *Middleware) headersMatch(recorded, actual http.Header) bool {
+	for name, values := range recorded {
+		if skippedRequestHeaders[name] || slices.ContainsFunc(m.ignoreHeaders, func(ignored string) bool {
+			return strings.EqualFold(ignored, name)
+		}) {
+			continue
+		}
+
+		if strings.Join(values, ", ") != strings.Join(actual.Values(name), ", ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readBody reads the body when it is matched. Bodies larger than
+// helpers.MaxMatchedBodySize are not compared and match no entry.
+func (m *Middleware) readBody(request *contracts.Request) (string, bool, error) {
+	if !m.matchBody {
+		return "", true, nil
+	}
+
+	body, ok, err := helpers.PeekBody(request)
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(body), ok, nil
+}
+
+func writeResponse(writer contracts.ResponseWriter, request *contracts.Request, response *har.Response) error {
+	header := writer.Header()
+
+	for _, item := range response.Headers {
+		name := http.CanonicalHeaderKey(item.Name)
+		if strings.HasPrefix(name, ":") || skippedResponseHeaders[name] || strings.HasPrefix(name, "Access-Control-") {
+			continue
+		}
+
+		header.Add(name, item.Value)
+	}
+
+	if header.Get(headers.ContentType) == "" && response.Content.MimeType != "" {
+		header.Set(headers.ContentType, response.Content.MimeType)
+	}
+
+	body, decoded, err := har.DecodeContent(response.Content, header.Get(headers.ContentEncoding))
+	if err != nil {
+		return err
+	}
+
+	if decoded {
+		header.Del(headers.ContentEncoding)
+	}
+
+	cors.WriteHeaders(header, request)
+	writer.WriteHeader(response.Status)
+
+	_, err = writer.Write(body)
+
+	return err
+}
+
+func writeNotFound(writer contracts.ResponseWriter, request *contracts.Request) error {
+	header := writer.Header()
+	header.Set(headers.ContentType, "text/plain; charset=utf-8")
+	cors.WriteHeaders(header, request)
+	writer.WriteHeader(http.StatusNotFound)
+
+	_, err := fmt.Fprintf(writer, "%s for %s %s\n", ErrNoRecordedResponse, request.Method, request.URL.RequestURI())
+
+	return err
+}
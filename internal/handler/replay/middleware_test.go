@@ -0,0 +1,277 @@
+package replay_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/har"
+	"github.com/evg4b/uncors/internal/handler/replay"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const upstreamBody = "upstream"
+
+var upstream = infra.HandlerFunc(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+	writer.WriteHeader(http.StatusOK)
+	_, err := io.WriteString(writer, upstreamBody)
+
+	return err
+})
+
+func entry(method, url, body string) har.Entry {
+	return har.Entry{
+		Request: har.Request{
+			Method: method,
+			URL:    url,
+			Headers: []har.NameValue{
+				{Name: ":authority", Value: "api.example.com"},
+				{Name: "X-Api-Version", Value: "2"},
+				{Name: "User-Agent", Value: "recorder"},
+			},
+		},
+		Response: har.Response{
+			Status: http.StatusOK,
+			Headers: []har.NameValue{
+				{Name: "content-type", Value: "application/json"},
+				{Name: "content-length", Value: "999"},
+				{Name: "access-control-allow-origin", Value: "https://example.com"},
+				{Name: "x-recorded", Value: "yes"},
+			},
+			Content: har.Content{MimeType: "application/json", Text: body},
+		},
+	}
+}
+
+func serve(
+	t *testing.T,
+	middleware *replay.Middleware,
+	method, url, body string,
+	requestHeaders map[string]string,
+) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+
+	var requestBody io.Reader = http.NoBody
+	if body != "" {
+		requestBody = strings.NewReader(body)
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequestWithContext(t.Context(), method, url, requestBody)
+
+	for name, value := range requestHeaders {
+		request.Header.Set(name, value)
+	}
+
+	err := infra.Mddleware(middleware, upstream).ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+	return recorder, err
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("serves recorded response", func(t *testing.T) {
+		middleware := replay.NewMiddleware(replay.WithEntries([]har.Entry{
+			entry(http.MethodGet, "https://api.example.com/api/users?page=2", `[{"id":1}]`),
+		}))
+
+		recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/api/users?page=2", "", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `[{"id":1}]`, testutils.ReadBody(t, recorder))
+		assert.Equal(t, "application/json", recorder.Header().Get(headers.ContentType))
+		assert.Equal(t, "yes", recorder.Header().Get("X-Recorded"))
+		assert.Empty(t, recorder.Header().Get(headers.ContentLength))
+		assert.Equal(t, "*", recorder.Header().Get(headers.AccessControlAllowOrigin))
+	})
+
+	t.Run("decodes compressed base64 bodies", func(t *testing.T) {
+		var compressed bytes.Buffer
+
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		recorded := entry(http.MethodGet, "https://api.example.com/hello", "")
+		recorded.Response.Headers = append(recorded.Response.Headers, har.NameValue{Name: "Content-Encoding", Value: "gzip"})
+		recorded.Response.Content.Text = base64.StdEncoding.EncodeToString(compressed.Bytes())
+		recorded.Response.Content.Encoding = "base64"
+
+		middleware := replay.NewMiddleware(replay.WithEntries([]har.Entry{recorded}))
+
+		recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/hello", "", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello", testutils.ReadBody(t, recorder))
+		assert.Empty(t, recorder.Header().Get(headers.ContentEncoding))
+	})
+
+	t.Run("serves repeated requests in recorded order", func(t *testing.T) {
+		middleware := replay.NewMiddleware(replay.WithEntries([]har.Entry{
+			entry(http.MethodGet, "https://api.example.com/status", "pending"),
+			entry(http.MethodGet, "https://api.example.com/status", "done"),
+		}))
+
+		expected := []string{"pending", "done", "done"}
+		for _, body := range expected {
+			recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/status", "", nil)
+			require.NoError(t, err)
+			assert.Equal(t, body, testutils.ReadBody(t, recorder))
+		}
+	})
+
+	t.Run("query order", func(t *testing.T) {
+		entries := []har.Entry{entry(http.MethodGet, "https://api.example.com/search?a=1&b=2", "found")}
+
+		strict := replay.NewMiddleware(replay.WithEntries(entries))
+		recorder, err := serve(t, strict, http.MethodGet, "http://localhost/search?b=2&a=1", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+		relaxed := replay.NewMiddleware(replay.WithEntries(entries), replay.WithIgnoreQueryOrder(true))
+		recorder, err = serve(t, relaxed, http.MethodGet, "http://localhost/search?b=2&a=1", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "found", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		entries := []har.Entry{entry(http.MethodGet, "https://api.example.com/users", "users")}
+
+		tests := []struct {
+			name     string
+			options  []replay.MiddlewareOption
+			headers  map[string]string
+			expected int
+		}{
+			{
+				name:     "ignored by default",
+				headers:  map[string]string{"X-Api-Version": "1"},
+				expected: http.StatusOK,
+			},
+			{
+				name:     "mismatch",
+				options:  []replay.MiddlewareOption{replay.WithMatchHeaders(true)},
+				headers:  map[string]string{"X-Api-Version": "1", "User-Agent": "recorder"},
+				expected: http.StatusNotFound,
+			},
+			{
+				name: "ignored header",
+				options: []replay.MiddlewareOption{
+					replay.WithMatchHeaders(true),
+					replay.WithIgnoreHeaders([]string{"user-agent"}),
+				},
+				headers:  map[string]string{"X-Api-Version": "2", "User-Agent": "browser"},
+				expected: http.StatusOK,
+			},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				options := append([]replay.MiddlewareOption{replay.WithEntries(entries)}, testCase.options...)
+
+				recorder, err := serve(t, replay.NewMiddleware(options...), http.MethodGet, "http://localhost/users", "", testCase.headers)
+
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, recorder.Code)
+			})
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		first := entry(http.MethodPost, "https://api.example.com/login", "admin")
+		first.Request.PostData = &har.PostData{Text: "user=admin"}
+		second := entry(http.MethodPost, "https://api.example.com/login", "guest")
+		second.Request.PostData = &har.PostData{Text: "user=guest"}
+
+		middleware := replay.NewMiddleware(
+			replay.WithEntries([]har.Entry{first, second}),
+			replay.WithMatchBody(true),
+		)
+
+		recorder, err := serve(t, middleware, http.MethodPost, "http://localhost/login", "user=guest", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "guest", testutils.ReadBody(t, recorder))
+
+		recorder, err = serve(t, middleware, http.MethodPost, "http://localhost/login", "user=root", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("does not match bodies larger than the limit", func(t *testing.T) {
+		large := strings.Repeat("x", helpers.MaxMatchedBodySize+1)
+		upload := entry(http.MethodPost, "https://api.example.com/upload", "uploaded")
+		upload.Request.PostData = &har.PostData{Text: large}
+
+		middleware := replay.NewMiddleware(
+			replay.WithEntries([]har.Entry{upload}),
+			replay.WithMatchBody(true),
+		)
+
+		recorder, err := serve(t, middleware, http.MethodPost, "http://localhost/upload", large, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		entries := []har.Entry{entry(http.MethodGet, "https://api.example.com/users", "users")}
+
+		t.Run("not found", func(t *testing.T) {
+			middleware := replay.NewMiddleware(replay.WithEntries(entries))
+
+			recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/other", "", nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.Equal(t, "no recorded response for GET /other\n", testutils.ReadBody(t, recorder))
+		})
+
+		t.Run("proxy", func(t *testing.T) {
+			middleware := replay.NewMiddleware(
+				replay.WithEntries(entries),
+				replay.WithOnMiss(config.HARReplayOnMissProxy),
+			)
+
+			recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/other", "", nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, upstreamBody, testutils.ReadBody(t, recorder))
+		})
+
+		t.Run("error", func(t *testing.T) {
+			middleware := replay.NewMiddleware(
+				replay.WithEntries(entries),
+				replay.WithOnMiss(config.HARReplayOnMissError),
+			)
+
+			_, err := serve(t, middleware, http.MethodGet, "http://localhost/other", "", nil)
+
+			require.ErrorIs(t, err, replay.ErrNoRecordedResponse)
+		})
+	})
+
+	t.Run("skips entries without response", func(t *testing.T) {
+		failed := entry(http.MethodGet, "https://api.example.com/users", "")
+		failed.Response.Status = 0
+
+		middleware := replay.NewMiddleware(replay.WithEntries([]har.Entry{failed}))
+
+		recorder, err := serve(t, middleware, http.MethodGet, "http://localhost/users", "", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
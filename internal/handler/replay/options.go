@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/handler/har"
+)
+
+type MiddlewareOption = func(*Middleware)
+
+// WithEntries sets the recorded entries to serve. Entries that never got a
+// response or have an invalid URL are skipped.
+func WithEntries(entries []har.Entry) MiddlewareOption {
+	return func(m *Middleware) {
+		m.entries = make([]*entry, 0, len(entries))
+
+		for _, item := range entries {
+			parsed, err := url.Parse(item.Request.URL)
+			if err != nil || item.Response.Status == 0 {
+				continue
+			}
+
+			header := http.Header{}
+			for _, value := range item.Request.Headers {
+				if !strings.HasPrefix(value.Name, ":") {
+					header.Add(value.Name, value.Value)
+				}
+			}
+
+			body := ""
+			if item.Request.PostData != nil {
+				body = item.Request.PostData.Text
+			}
+
+			m.entries = append(m.entries, &entry{
+				method:   strings.ToUpper(item.Request.Method),
+				url:      parsed,
+				header:   header,
+				body:     body,
+				response: &item.Response,
+			})
+		}
+	}
+}
+
+// WithIgnoreQueryOrder compares query parameters regardless of their order.
+func WithIgnoreQueryOrder(ignore bool) MiddlewareOption {
+	return func(m *Middleware) {
+		m.ignoreQueryOrder = ignore
+	}
+}
+
+// WithMatchHeaders requires the recorded request headers to be present.
+func WithMatchHeaders(match bool) MiddlewareOption {
+	return func(m *Middleware) {
+		m.matchHeaders = match
+	}
+}
+
+// WithIgnoreHeaders excludes headers from comparison.
+func WithIgnoreHeaders(names []string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.ignoreHeaders = names
+	}
+}
+
+// WithMatchBody requires the request body to equal the recorded one.
+func WithMatchBody(match bool) MiddlewareOption {
+	return func(m *Middleware) {
+		m.matchBody = match
+	}
+}
+
+// WithOnMiss sets what happens when no recorded entry matches.
+func WithOnMiss(onMiss string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.onMiss = onMiss
+	}
+}
+
+// WithPrefix sets the log prefix of replayed responses.
+func WithPrefix(prefix string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.prefix = prefix
+	}
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/gorilla/mux"
+)
+
+// mappingRoutes keeps what is needed to explain how the host of a request was
+// matched against a mapping.
+type mappingRoutes struct {
+	host  string
+	match func(*http.Request) bool
+}
+
+type routeTraceKey struct{}
+
+// routeTrace collects the results of the checks evaluated while a request is
+// routed, so the explanation reuses them instead of checking routes again.
+type routeTrace struct {
+	output  contracts.Output
+	title   string
+	lines   []string
+	last    *routeEntry
+	mapping *mappingRoutes
+	mapped  bool
+	written bool
+}
+
+func (r *Router) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if r.debugOutput != nil {
+		trace := &routeTrace{
+			output: r.debugOutput,
+			title:  fmt.Sprintf("route resolution for %s %s", request.Method, request.URL),
+		}
+		request = request.WithContext(context.WithValue(request.Context(), routeTraceKey{}, trace))
+	}
+
+	r.Router.ServeHTTP(writer, request)
+}
+
+func traceFrom(request *http.Request) *routeTrace {
+	trace, _ := request.Context().Value(routeTraceKey{}).(*routeTrace)
+
+	return trace
+}
+
+// tracedMapping matches the host of the mapping and records the result. The
+// routes of a subrouter inherit the matchers of the mapping route, so the
+// result is reused while the routes of the mapping are checked.
+func tracedMapping(mapping *mappingRoutes) mux.MatcherFunc {
+	return func(request *http.Request, _ *mux.RouteMatch) bool {
+		trace := traceFrom(request)
+		if trace == nil {
+			return true
+		}
+
+		if trace.mapping == mapping {
+			return trace.mapped
+		}
+
+		trace.mapping = mapping
+		trace.mapped = mapping.match(request)
+
+		if trace.mapped {
+			trace.lines = append(trace.lines, fmt.Sprintf("  mapping %s:", mapping.host))
+		} else {
+			trace.lines = append(trace.lines, fmt.Sprintf("  mapping %s: host does not match", mapping.host))
+		}
+
+		return trace.mapped
+	}
+}
+
+// traced matches the checks of the route and records why the route was
+// skipped or that it matched.
+func traced(entry *routeEntry) mux.MatcherFunc {
+	return func(request *http.Request, _ *mux.RouteMatch) bool {
+		trace := traceFrom(request)
+		if trace == nil {
+			return true
+		}
+
+		reasons := entry.failures(request)
+
+		line := "    matched " + entry.String()
+		if len(reasons) > 0 {
+			line = fmt.Sprintf("    skipped %s: %s", entry, strings.Join(reasons, ", "))
+		}
+
+		// Static and rewrite routes are registered as several mux routes, so
+		// the same entry can be checked more than once in a row.
+		if trace.last == entry {
+			trace.lines[len(trace.lines)-1] = line
+		} else {
+			trace.lines = append(trace.lines, line)
+		}
+
+		trace.last = entry
+
+		return len(reasons) == 0
+	}
+}
+
+// explained writes the explanation of the routing before the handler serves
+// the request.
+func explained(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if trace := traceFrom(request); trace != nil {
+			trace.write()
+		}
+
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+func (t *routeTrace) write() {
+	if t.written {
+		return
+	}
+
+	t.written = true
+
+	lines := append([]string{t.title}, t.lines...)
+
+	switch {
+	case !t.mapped:
+		lines = append(lines, "  no mapping matched")
+	case !strings.HasPrefix(lines[len(lines)-1], "    matched "):
+		lines = append(lines, "    no route matched, using the default handler")
+	}
+
+	t.output.Debug(strings.Join(lines, "\n"))
+}
@@ -7,5 +7,5 @@ import (
 
 type (
 	// CacheMiddlewareFactory creates a cache middleware for the given cache configuration.
-	CacheMiddlewareFactory = func(globs config.CacheGlobs) contracts.Middleware
+	CacheMiddlewareFactory = func(rules config.CacheRules) (contracts.Middleware, error)
 )
@@ -0,0 +1,213 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/gorilla/mux"
+)
+
+// valueCondition is a compiled config.ValueMatcher.
+type valueCondition struct {
+	config.ValueMatcher
+
+	regex   *regexp.Regexp
+	glob    *regexp.Regexp
+	invalid bool
+}
+
+func newValueCondition(matcher config.ValueMatcher) valueCondition {
+	condition := valueCondition{ValueMatcher: matcher}
+
+	var err error
+
+	if matcher.Regex != "" {
+		condition.regex, err = regexp.Compile(matcher.Regex)
+		condition.invalid = err != nil
+	}
+
+	if matcher.Glob != "" {
+		condition.glob = globToRegexp(matcher.Glob)
+	}
+
+	return condition
+}
+
+// match reports whether any of the values satisfies the condition. An empty
+// list means the value is missing from the request.
+func (c valueCondition) match(values []string) bool {
+	if c.Absent {
+		return len(values) == 0
+	}
+
+	return !c.invalid && slices.ContainsFunc(values, c.matchValue)
+}
+
+func (c valueCondition) matchValue(value string) bool {
+	return (c.Equals == "" || value == c.Equals) &&
+		(c.Contains == "" || strings.Contains(value, c.Contains)) &&
+		(c.regex == nil || c.regex.MatchString(value)) &&
+		(c.glob == nil || c.glob.MatchString(value))
+}
+
+type bodyCondition struct {
+	valueCondition
+
+	field []string
+}
+
+// requestConditions holds the parts of a config.RequestMatcher that
+// gorilla/mux can not express natively.
+type requestConditions struct {
+	queries map[string]valueCondition
+	headers map[string]valueCondition
+	body    []bodyCondition
+}
+
+func newRequestConditions(matcher config.RequestMatcher) *requestConditions {
+	conditions := &requestConditions{
+		queries: map[string]valueCondition{},
+		headers: map[string]valueCondition{},
+	}
+
+	for key, value := range matcher.Queries {
+		if !value.IsExact() {
+			conditions.queries[key] = newValueCondition(value)
+		}
+	}
+
+	for key, value := range matcher.Headers {
+		if !value.IsExact() {
+			conditions.headers[key] = newValueCondition(value)
+		}
+	}
+
+	for _, body := range matcher.Body {
+		condition := bodyCondition{valueCondition: newValueCondition(body.ValueMatcher)}
+		if body.Field != "" {
+			condition.field = strings.Split(body.Field, ".")
+		}
+
+		conditions.body = append(conditions.body, condition)
+	}
+
+	if len(conditions.queries) == 0 && len(conditions.headers) == 0 && len(conditions.body) == 0 {
+		return nil
+	}
+
+	return conditions
+}
+
+func (c *requestConditions) Match(request *http.Request, _ *mux.RouteMatch) bool {
+	query := request.URL.Query()
+	for key, condition := range c.queries {
+		if !condition.match(query[key]) {
+			return false
+		}
+	}
+
+	for key, condition := range c.headers {
+		if !condition.match(request.Header.Values(key)) {
+			return false
+		}
+	}
+
+	if len(c.body) == 0 {
+		return true
+	}
+
+	return c.matchBody(request)
+}
+
+func (c *requestConditions) matchBody(request *http.Request) bool {
+	body, ok, err := helpers.PeekBody(request)
+	if err != nil || !ok {
+		return false
+	}
+
+	var (
+		document any
+		parsed   bool
+	)
+
+	for _, condition := range c.body {
+		if condition.field == nil {
+			if !condition.match([]string{string(body)}) {
+				return false
+			}
+
+			continue
+		}
+
+		if !parsed {
+			document = parseJSON(body)
+			parsed = true
+		}
+
+		var values []string
+		if value, ok := helpers.LookupJSONField(document, condition.field); ok {
+			values = []string{stringifyJSON(value)}
+		}
+
+		if !condition.match(values) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseJSON returns the decoded body or nil when it is not valid JSON.
+func parseJSON(body []byte) any {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if decoder.Decode(&document) != nil {
+		return nil
+	}
+
+	return document
+}
+
+func stringifyJSON(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case json.Number:
+		return typed.String()
+	default:
+		data, _ := json.Marshal(typed)
+
+		return string(data)
+	}
+}
+
+// globToRegexp converts a glob where * matches any sequence of characters
+// and ? matches a single character into an anchored regular expression.
+func globToRegexp(glob string) *regexp.Regexp {
+	var builder strings.Builder
+
+	builder.WriteString("^")
+
+	for _, char := range glob {
+		switch char {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	builder.WriteString("$")
+
+	return regexp.MustCompile(builder.String())
+}
@@ -2,19 +2,24 @@ package router
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/evg4b/uncors/internal/handler/script"
 	"github.com/evg4b/uncors/internal/infra"
 
 	"github.com/gorilla/mux"
+	"github.com/spf13/afero"
 )
 
 var errHostNotMapped = errors.New("host not mapped")
 
 func setDefaultHandler(router *mux.Router, handler contracts.Handler) {
-	httpHandler := infra.CastToHTTPHandler(handler)
+	httpHandler := explained(infra.CastToHTTPHandler(handler))
 	router.NotFoundHandler = httpHandler
 	router.MethodNotAllowedHandler = httpHandler
 }
@@ -23,23 +28,36 @@ type DI interface {
 	StaticMiddleware(path string, dir config.StaticDirectory) contracts.Middleware
 	RewriteMiddleware(rewriting *config.RewritingOption) contracts.Middleware
 	HARMiddleware(harConfig *config.HARConfig) contracts.Middleware
-	ScriptHandler(scriptConfig *config.Script) contracts.Handler
+	RecordMiddleware(recordConfig *config.RecordConfig) (contracts.Middleware, error)
+	HARReplayMiddleware(replayConfig *config.HARReplayConfig) (contracts.Middleware, error)
+	ScriptHandler(scriptConfig *config.Script, runtime *script.Runtime) contracts.Handler
+	ScriptMiddleware(scriptConfig *config.ScriptMiddleware, runtime *script.Runtime) contracts.Middleware
 	OptionsMiddleware(cfg config.OptionsHandling) contracts.Middleware
+	CORSMiddleware(cfg *config.CORSConfig) contracts.Middleware
+	BodyRewriteMiddleware(cfg *config.BodyRewriteConfig) contracts.Middleware
 	MockHandler(response *config.Response) contracts.Handler
+	ScriptModules(dirs config.LuaPath) *script.Modules
 }
 
 type Router struct {
 	*mux.Router
 
 	defaultHandler contracts.Handler
+	httpClient     contracts.HTTPClient
 	container      DI
+	scenarios      *mock.Scenarios
+	scriptStores   *script.Stores
+	luaPath        config.LuaPath
+	debugOutput    contracts.Output
 
 	cacheMiddlewareFactory CacheMiddlewareFactory
 }
 
 func NewRouter(mappings config.Mappings, options ...Option) (*Router, error) {
 	instance := Router{
-		Router: mux.NewRouter(),
+		Router:       mux.NewRouter(),
+		scenarios:    mock.NewScenarios(),
+		scriptStores: script.NewStores(afero.NewMemMapFs()),
 	}
 
 	for _, option := range options {
@@ -47,7 +65,10 @@ func NewRouter(mappings config.Mappings, options ...Option) (*Router, error) {
 	}
 
 	for _, mapping := range mappings {
-		instance.registerMapping(mapping)
+		err := instance.registerMapping(mapping)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	setDefaultHandler(instance.Router, infra.HandlerFunc(func(_ contracts.ResponseWriter, _ *http.Request) error {
@@ -59,51 +80,259 @@ func NewRouter(mappings config.Mappings, options ...Option) (*Router, error) {
 	return &instance, nil
 }
 
-func (r *Router) registerMapping(mapping config.Mapping) {
-	router := r.Router.Host(mapping.From.Hostname).
+func (r *Router) registerMapping(mapping config.Mapping) error {
+	runtime, err := r.scriptRuntime(mapping)
+	if err != nil {
+		return err
+	}
+
+	mappingRoute := r.Router.NewRoute()
+	if r.debugOutput != nil {
+		mappingRoute.MatcherFunc(tracedMapping(&mappingRoutes{
+			host:  mapping.From.Hostname,
+			match: routeMatcher(mux.NewRouter().Host(mapping.From.Hostname)),
+		}))
+	}
+
+	router := mappingRoute.Host(mapping.From.Hostname).
 		Subrouter()
 
-	defaultHandler := r.prepareDefaultHandler(mapping)
+	mappingHandler, err := r.prepareDefaultHandler(mapping, runtime)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s: %w", mapping.From.String(), err)
+	}
+
+	corsMiddleware := r.container.CORSMiddleware(&mapping.CORS)
+	defaultHandler := infra.Mddleware(corsMiddleware, mappingHandler)
+
+	routes := make([]routeEntry, 0,
+		len(mapping.Statics)+len(mapping.Mocks)+len(mapping.Scripts)+len(mapping.Rewrites))
 
 	for _, staticDir := range mapping.Statics {
-		middleware := r.container.StaticMiddleware(staticDir.Path, staticDir)
-		registerPrefixHandler(router, staticDir.Path, infra.Mddleware(middleware, defaultHandler))
+		routes = append(routes, routeEntry{
+			kind:     staticRoute,
+			priority: staticDir.Priority,
+			pathOnly: true,
+			name:     staticDir.String(),
+			checks:   prefixChecks(staticDir.Path),
+			register: func(entry *routeEntry) {
+				middleware := r.container.StaticMiddleware(staticDir.Path, staticDir)
+				handler := infra.Mddleware(middleware, defaultHandler)
+				registerPrefixHandler(router, staticDir.Path, handler, r.entryMatchers(entry)...)
+			},
+		})
 	}
 
-	registerMatchedRoutes(mapping.Mocks,
-		func(m *config.Mock) *config.RequestMatcher { return &m.Matcher },
-		func(def *config.Mock) {
-			registerRoute(createRoute(router, def.Matcher), r.container.MockHandler(&def.Response))
+	for i := range mapping.Mocks {
+		def := &mapping.Mocks[i]
+		checks := matcherChecks(def.Matcher)
+
+		if def.Scenario.State != "" {
+			checks = append(checks, routeCheck{
+				reason: fmt.Sprintf("scenario %q is not in state %q", def.Scenario.Name, def.Scenario.State),
+				match: func(_ *http.Request) bool {
+					return r.scenarios.State(def.Scenario.Name) == def.Scenario.State
+				},
+			})
+		}
+
+		routes = append(routes, routeEntry{
+			kind:     mockRoute,
+			priority: def.Priority,
+			pathOnly: def.Matcher.IsPathOnly(),
+			name:     def.String(),
+			checks:   checks,
+			register: func(entry *routeEntry) {
+				route := r.entryRoute(router, entry, def.Matcher)
+				handler := r.mockHandler(def)
+
+				if def.Scenario.IsDefined() {
+					handler = r.bindScenario(route, def.Scenario, handler)
+				}
+
+				registerRoute(route, infra.Mddleware(corsMiddleware, handler))
+			},
 		})
+	}
 
-	registerMatchedRoutes(mapping.Scripts,
-		func(s *config.Script) *config.RequestMatcher { return &s.Matcher },
-		func(def *config.Script) {
-			registerRoute(createRoute(router, def.Matcher), r.container.ScriptHandler(def))
+	for i := range mapping.Scripts {
+		def := &mapping.Scripts[i]
+
+		routes = append(routes, routeEntry{
+			kind:     scriptRoute,
+			priority: def.Priority,
+			pathOnly: def.Matcher.IsPathOnly(),
+			name:     def.String(),
+			checks:   matcherChecks(def.Matcher),
+			register: func(entry *routeEntry) {
+				handler := infra.Mddleware(corsMiddleware, r.container.ScriptHandler(def, runtime))
+				registerRoute(r.entryRoute(router, entry, def.Matcher), handler)
+			},
 		})
+	}
 
 	for _, rewrite := range mapping.Rewrites {
-		wrappedHandler := infra.Mddleware(r.container.RewriteMiddleware(&rewrite), defaultHandler)
+		routes = append(routes, routeEntry{
+			kind:     rewriteRoute,
+			priority: rewrite.Priority,
+			pathOnly: true,
+			name:     rewrite.String(),
+			checks:   prefixChecks(rewrite.From),
+			register: func(entry *routeEntry) {
+				wrappedHandler := infra.Mddleware(r.container.RewriteMiddleware(&rewrite), defaultHandler)
+				registerPathHandler(router, rewrite.From, wrappedHandler, r.entryMatchers(entry)...)
+			},
+		})
+	}
 
-		registerPathHandler(router, rewrite.From, wrappedHandler)
+	sortRoutes(routes)
+
+	for i := range routes {
+		routes[i].register(&routes[i])
 	}
 
 	setDefaultHandler(router, defaultHandler)
+
+	return nil
+}
+
+// entryRoute creates the route of a mock or a script. With the debug output
+// the checks of the entry replace the conditions of the route, so every check
+// is evaluated once and explained. The path is still set to extract the path
+// variables.
+func (r *Router) entryRoute(router *mux.Router, entry *routeEntry, matcher config.RequestMatcher) *mux.Route {
+	if r.debugOutput == nil {
+		return createRoute(router, matcher)
+	}
+
+	route := router.NewRoute().MatcherFunc(traced(entry))
+	if len(matcher.Path) > 0 {
+		route.Path(matcher.Path)
+	}
+
+	return route
 }
 
-func (r *Router) prepareDefaultHandler(mapping config.Mapping) contracts.Handler {
+// entryMatchers returns the matchers that explain the static or rewrite route
+// when the debug output is enabled.
+func (r *Router) entryMatchers(entry *routeEntry) []mux.MatcherFunc {
+	if r.debugOutput == nil {
+		return nil
+	}
+
+	return []mux.MatcherFunc{traced(entry)}
+}
+
+// scriptRuntime creates the runtime shared by all scripts of the mapping.
+func (r *Router) scriptRuntime(mapping config.Mapping) (*script.Runtime, error) {
+	store, err := r.scriptStores.For(mapping.From.String(), mapping.ScriptStore.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare script store for %s: %w", mapping.From.String(), err)
+	}
+
+	runtime := &script.Runtime{HTTPClient: r.httpClient, Store: store}
+
+	if dirs := slices.Concat(mapping.LuaPath, r.luaPath); len(dirs) > 0 {
+		runtime.Modules = r.container.ScriptModules(dirs)
+	}
+
+	return runtime, nil
+}
+
+func (r *Router) mockHandler(def *config.Mock) contracts.Handler {
+	if !def.Sequence.IsDefined() {
+		return r.container.MockHandler(&def.Response)
+	}
+
+	handlers := make([]contracts.Handler, 0, len(def.Sequence.Responses))
+	for i := range def.Sequence.Responses {
+		handlers = append(handlers, r.container.MockHandler(&def.Sequence.Responses[i]))
+	}
+
+	return mock.NewSequenceHandler(handlers, def.Sequence.Loop)
+}
+
+// bindScenario makes the route match only while the scenario is in the
+// required state and switches the scenario after the handler responds.
+func (r *Router) bindScenario(route *mux.Route, scenario config.MockScenario, handler contracts.Handler) contracts.Handler {
+	if scenario.State != "" {
+		route.MatcherFunc(func(_ *http.Request, _ *mux.RouteMatch) bool {
+			return r.scenarios.State(scenario.Name) == scenario.State
+		})
+	}
+
+	if scenario.NewState == "" {
+		return handler
+	}
+
+	return infra.HandlerFunc(func(writer contracts.ResponseWriter, request *contracts.Request) error {
+		err := handler.ServeHTTP(writer, request)
+		r.scenarios.Set(scenario.Name, scenario.NewState)
+
+		return err
+	})
+}
+
+func (r *Router) prepareDefaultHandler(mapping config.Mapping, runtime *script.Runtime) (contracts.Handler, error) {
 	defaultHandler := r.defaultHandler
+	if mapping.BodyRewrite.Enabled {
+		defaultHandler = infra.Mddleware(r.container.BodyRewriteMiddleware(&mapping.BodyRewrite), defaultHandler)
+	}
+
+	if mapping.HARReplay.Enabled() {
+		replayMiddleware, err := r.container.HARReplayMiddleware(&mapping.HARReplay)
+		if err != nil {
+			return nil, err
+		}
+
+		defaultHandler = infra.Mddleware(replayMiddleware, defaultHandler)
+	}
+
+	if mapping.Record.Recording() {
+		recordMiddleware, err := r.container.RecordMiddleware(&mapping.Record)
+		if err != nil {
+			return nil, err
+		}
+
+		defaultHandler = infra.Mddleware(recordMiddleware, defaultHandler)
+	}
+
+	defaultHandler = r.wrapScriptMiddlewares(mapping.Middlewares, runtime, defaultHandler)
+
 	if !mapping.OptionsHandling.Disabled {
 		defaultHandler = infra.Mddleware(r.container.OptionsMiddleware(mapping.OptionsHandling), defaultHandler)
 	}
 
 	if len(mapping.Cache) > 0 {
-		defaultHandler = infra.Mddleware(r.cacheMiddlewareFactory(mapping.Cache), defaultHandler)
+		cacheMiddleware, err := r.cacheMiddlewareFactory(mapping.Cache)
+		if err != nil {
+			return nil, err
+		}
+
+		defaultHandler = infra.Mddleware(cacheMiddleware, defaultHandler)
 	}
 
 	if mapping.HAR.Enabled() {
 		defaultHandler = infra.Mddleware(r.container.HARMiddleware(&mapping.HAR), defaultHandler)
 	}
 
-	return defaultHandler
+	return defaultHandler, nil
+}
+
+// wrapScriptMiddlewares wraps the handler so that the script middlewares run
+// in configuration order for the requests they match.
+func (r *Router) wrapScriptMiddlewares(
+	middlewares config.ScriptMiddlewares,
+	runtime *script.Runtime,
+	handler contracts.Handler,
+) contracts.Handler {
+	scratch := mux.NewRouter()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		def := &middlewares[i]
+		middleware := r.container.ScriptMiddleware(def, runtime)
+		handler = matchedMiddleware(createRoute(scratch, def.Matcher), middleware, handler)
+	}
+
+	return handler
 }
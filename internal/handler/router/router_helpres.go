@@ -22,35 +22,66 @@ func createRoute(router *mux.Router, matcher config.RequestMatcher) *mux.Route {
 	}
 
 	for key, value := range matcher.Queries {
-		route.Queries(key, value)
+		if value.IsExact() {
+			route.Queries(key, value.Equals)
+		}
 	}
 
 	for key, value := range matcher.Headers {
-		route.Headers(key, value)
+		if value.IsExact() {
+			route.Headers(key, value.Equals)
+		}
+	}
+
+	if conditions := newRequestConditions(matcher); conditions != nil {
+		route.MatcherFunc(conditions.Match)
 	}
 
 	return route
 }
 
-func registerPathHandler(router *mux.Router, path string, handler contracts.Handler) {
+func registerPathHandler(router *mux.Router, path string, handler contracts.Handler, matchers ...mux.MatcherFunc) {
 	clearPath, fullPath := normalizePath(path)
 
-	registerRoute(router.NewRoute().Path(clearPath), handler)
-	registerRoute(router.NewRoute().PathPrefix(fullPath), handler)
+	registerRoute(newRoute(router, matchers).Path(clearPath), handler)
+	registerRoute(newRoute(router, matchers).PathPrefix(fullPath), handler)
 }
 
-func registerPrefixHandler(router *mux.Router, prefix string, handler contracts.Handler) {
+func registerPrefixHandler(router *mux.Router, prefix string, handler contracts.Handler, matchers ...mux.MatcherFunc) {
 	clearPrefix, fullPrefix := normalizePath(prefix)
 
-	router.NewRoute().
+	newRoute(router, matchers).
 		Path(clearPrefix).
-		Handler(http.RedirectHandler(fullPrefix, http.StatusTemporaryRedirect))
+		Handler(explained(http.RedirectHandler(fullPrefix, http.StatusTemporaryRedirect)))
 
-	registerRoute(router.NewRoute().PathPrefix(fullPrefix), handler)
+	registerRoute(newRoute(router, matchers).PathPrefix(fullPrefix), handler)
 }
 
 func registerRoute(route *mux.Route, handler contracts.Handler) {
-	route.Handler(infra.CastToHTTPHandler(handler))
+	route.Handler(explained(infra.CastToHTTPHandler(handler)))
+}
+
+// newRoute creates a route that is checked by the matchers first.
+func newRoute(router *mux.Router, matchers []mux.MatcherFunc) *mux.Route {
+	route := router.NewRoute()
+	for _, matcher := range matchers {
+		route.MatcherFunc(matcher)
+	}
+
+	return route
+}
+
+// matchedMiddleware applies the middleware only to requests that match the
+// route, with the path variables of the route available through mux.Vars.
+func matchedMiddleware(route *mux.Route, middleware contracts.Middleware, handler contracts.Handler) contracts.Handler {
+	return infra.HandlerFunc(func(writer contracts.ResponseWriter, request *contracts.Request) error {
+		var match mux.RouteMatch
+		if !route.Match(request, &match) {
+			return handler.ServeHTTP(writer, request)
+		}
+
+		return middleware.ServeHTTP(writer, mux.SetURLVars(request, match.Vars), handler.ServeHTTP)
+	})
 }
 
 func normalizePath(path string) (string, string) {
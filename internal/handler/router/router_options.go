@@ -1,7 +1,10 @@
 package router
 
 import (
+	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/mock"
+	"github.com/evg4b/uncors/internal/handler/script"
 )
 
 type Option = func(*Router)
@@ -23,3 +26,44 @@ func ForRouterWithDefaultHandler(handler contracts.Handler) Option {
 		r.defaultHandler = handler
 	}
 }
+
+// WithHTTPClient sets the client scripts use to call other services. It should
+// be the client of the proxy handler, so scripts use the same proxy settings.
+func WithHTTPClient(client contracts.HTTPClient) Option {
+	return func(r *Router) {
+		r.httpClient = client
+	}
+}
+
+// WithScenarios sets the store of mock scenario states shared by all routers
+// of the running configuration.
+func WithScenarios(scenarios *mock.Scenarios) Option {
+	return func(r *Router) {
+		r.scenarios = scenarios
+	}
+}
+
+// WithScriptStores sets the registry of script stores, so the data of the
+// scripts survives until the configuration is reloaded.
+func WithScriptStores(stores *script.Stores) Option {
+	return func(r *Router) {
+		r.scriptStores = stores
+	}
+}
+
+// WithLuaPath sets the global lua-path directories. Scripts search them after
+// the lua-path directories of their mapping.
+func WithLuaPath(luaPath config.LuaPath) Option {
+	return func(r *Router) {
+		r.luaPath = luaPath
+	}
+}
+
+// WithDebugOutput enables explaining how every request is routed. The
+// explanation is written to the debug level of output before the request is
+// handled.
+func WithDebugOutput(output contracts.Output) Option {
+	return func(r *Router) {
+		r.debugOutput = output
+	}
+}
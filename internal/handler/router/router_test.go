@@ -1,6 +1,7 @@
 package router_test
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/di"
 	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/mock"
 	"github.com/evg4b/uncors/internal/handler/proxy"
 	"github.com/evg4b/uncors/internal/handler/router"
 	"github.com/evg4b/uncors/internal/helpers"
@@ -49,12 +51,14 @@ var (
 	userIDHeader = "User-Id"
 )
 
+var errCacheUnavailable = errors.New("cache unavailable")
+
 func cacheFactory() router.CacheMiddlewareFactory {
-	return func(globs config.CacheGlobs) contracts.Middleware {
+	return func(rules config.CacheRules) (contracts.Middleware, error) {
 		return cache.NewMiddleware(
-			cache.WithGlobs(globs),
+			cache.WithRules(rules),
 			cache.WithCacheStorage(cache.NewRistrettoCache(100, time.Minute)),
-		)
+		), nil
 	}
 }
 
@@ -375,6 +379,177 @@ func TestRouterScriptsAndRewrites(t *testing.T) {
 		assert.Equal(t, "from-script", testutils.ReadBody(t, recorder))
 	})
 
+	t.Run("script middlewares wrap the proxy for matching requests", func(t *testing.T) {
+		container := di.NewContainer()
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From: hosts.Parse("{host}"),
+				To:   hosts.Parse("{host}"),
+				Middlewares: config.ScriptMiddlewares{
+					{
+						Matcher: config.RequestMatcher{Path: "/api/{id}"},
+						Script: `
+							function on_request(request)
+								request.headers["X-Id"] = request.path_params.id
+							end
+							function on_response(response)
+								response.headers["X-Patched"] = "yes"
+							end
+						`,
+					},
+				},
+			},
+		}
+
+		factory := urlreplacer.NewURLReplacerFactory(mappings)
+		httpMock := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(req.Header.Get("X-Id"))),
+			}, nil
+		})
+
+		routerInstance, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, factory, httpMock)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+		)
+		require.NoError(t, err)
+
+		t.Run("matching request", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/api/42", nil)
+
+			serveHTTP(t, routerInstance, recorder, request)
+
+			assert.Equal(t, "42", testutils.ReadBody(t, recorder))
+			assert.Equal(t, "yes", recorder.Header().Get("X-Patched"))
+		})
+
+		t.Run("other request", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/other", nil)
+
+			serveHTTP(t, routerInstance, recorder, request)
+
+			assert.Empty(t, testutils.ReadBody(t, recorder))
+			assert.Empty(t, recorder.Header().Get("X-Patched"))
+		})
+	})
+
+	t.Run("scripts of a mapping share the script store", func(t *testing.T) {
+		container := di.NewContainer()
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From: hosts.Parse("{host}"),
+				To:   hosts.Parse("{host}"),
+				Scripts: config.Scripts{
+					{
+						Matcher: config.RequestMatcher{Path: "/api/todos", Method: http.MethodPost},
+						Script:  `require("store").set("todo:" .. request.body, request.body)`,
+					},
+					{
+						Matcher: config.RequestMatcher{Path: "/api/todos", Method: http.MethodGet},
+						Script:  `response:WriteString(table.concat(require("store").list("todo:"), ","))`,
+					},
+				},
+			},
+		}
+
+		routerInstance, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+			router.WithScriptStores(container.ScriptStores()),
+		)
+		require.NoError(t, err)
+
+		for _, todo := range []string{"walk", "buy"} {
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodPost,
+				"http://localhost/api/todos", strings.NewReader(todo))
+			serveHTTP(t, routerInstance, httptest.NewRecorder(), request)
+		}
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/api/todos", nil)
+
+		serveHTTP(t, routerInstance, recorder, request)
+
+		assert.Equal(t, "buy,walk", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("scripts require modules from mapping and global lua-path", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/mapping/format.lua": `return { wrap = function(value) return "[" .. value .. "]" end }`,
+			"/global/format.lua":  `return { wrap = function(value) return value end }`,
+			"/global/names.lua":   `return { first = "John" }`,
+		})
+		container := di.NewContainer(di.WithFs(fs))
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From:    hosts.Parse("{host}"),
+				To:      hosts.Parse("{host}"),
+				LuaPath: config.LuaPath{"/mapping"},
+				Scripts: config.Scripts{
+					{
+						Matcher: config.RequestMatcher{Path: "/api/name"},
+						Script:  `response:WriteString(require("format").wrap(require("names").first))`,
+					},
+				},
+			},
+		}
+
+		routerInstance, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+			router.WithLuaPath(config.LuaPath{"/global"}),
+		)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/api/name", nil)
+
+		serveHTTP(t, routerInstance, recorder, request)
+
+		assert.Equal(t, "[John]", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("invalid script store file fails the router", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{"/store.json": `[`})
+		container := di.NewContainer(di.WithFs(fs))
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From:        hosts.Parse("{host}"),
+				To:          hosts.Parse("{host}"),
+				ScriptStore: config.ScriptStoreConfig{File: "/store.json"},
+			},
+		}
+
+		_, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+			router.WithScriptStores(container.ScriptStores()),
+		)
+
+		require.ErrorContains(t, err, "failed to parse script store /store.json")
+	})
+
 	t.Run("rewrites are served via path handler", func(t *testing.T) {
 		container := di.NewContainer()
 		defer testutils.Close(t, container)
@@ -437,7 +612,7 @@ func TestRouterScriptsAndRewrites(t *testing.T) {
 			{
 				From:  hosts.Parse("{host}"),
 				To:    hosts.Parse("{host}"),
-				Cache: config.CacheGlobs{"*.json"},
+				Cache: config.CacheRules{{Path: "*.json"}},
 				Mocks: config.Mocks{
 					{
 						Matcher: config.RequestMatcher{Path: "/data.json"},
@@ -453,10 +628,10 @@ func TestRouterScriptsAndRewrites(t *testing.T) {
 		routerInstance, err := router.NewRouter(
 			mappings,
 			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
-			router.ForRouterWithCacheMiddlewareFactory(func(globs config.CacheGlobs) contracts.Middleware {
+			router.ForRouterWithCacheMiddlewareFactory(func(rules config.CacheRules) (contracts.Middleware, error) {
 				callCount++
 
-				return cacheFactory()(globs)
+				return cacheFactory()(rules)
 			}),
 			router.WithDiContainer(container),
 		)
@@ -471,6 +646,30 @@ func TestRouterScriptsAndRewrites(t *testing.T) {
 		assert.Equal(t, 1, callCount, "cache middleware factory should be called once for the mapping")
 	})
 
+	t.Run("cache middleware error fails the router", func(t *testing.T) {
+		container := di.NewContainer()
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From:  hosts.Parse("{host}"),
+				To:    hosts.Parse("{host}"),
+				Cache: config.CacheRules{{Path: "*.json"}},
+			},
+		}
+
+		_, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+			router.ForRouterWithCacheMiddlewareFactory(func(config.CacheRules) (contracts.Middleware, error) {
+				return nil, errCacheUnavailable
+			}),
+			router.WithDiContainer(container),
+		)
+
+		require.ErrorIs(t, err, errCacheUnavailable)
+	})
+
 	t.Run("HAR config enables HAR middleware", func(t *testing.T) {
 		harFile := filepath.Join(t.TempDir(), "test.har")
 
@@ -507,6 +706,103 @@ func TestRouterScriptsAndRewrites(t *testing.T) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 		assert.Equal(t, mock1Body, testutils.ReadBody(t, recorder))
 	})
+
+	t.Run("CORS policy is applied to mapping handlers", func(t *testing.T) {
+		const allowedOrigin = "https://app.example.com"
+
+		container := di.NewContainer()
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From: hosts.Parse("{host}"),
+				To:   hosts.Parse("{host}"),
+				CORS: config.CORSConfig{AllowedOrigins: []string{allowedOrigin}},
+				Mocks: config.Mocks{
+					{
+						Matcher:  config.RequestMatcher{Path: "/api"},
+						Response: config.Response{Code: http.StatusOK, Raw: mock1Body},
+					},
+				},
+			},
+		}
+
+		routerInstance, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+		)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name     string
+			method   string
+			origin   string
+			expected string
+		}{
+			{name: "mock with allowed origin", method: http.MethodGet, origin: allowedOrigin, expected: allowedOrigin},
+			{name: "mock with other origin", method: http.MethodGet, origin: "https://other.com", expected: ""},
+			{name: "preflight with allowed origin", method: http.MethodOptions, origin: allowedOrigin, expected: allowedOrigin},
+			{name: "preflight with other origin", method: http.MethodOptions, origin: "https://other.com", expected: ""},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				request := httptest.NewRequestWithContext(t.Context(), testCase.method, "http://localhost/api", nil)
+				request.Header.Set(headers.Origin, testCase.origin)
+
+				serveHTTP(t, routerInstance, recorder, request)
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, testCase.expected, recorder.Header().Get(headers.AccessControlAllowOrigin))
+			})
+		}
+	})
+
+	t.Run("CORS passthrough forwards preflight to proxy", func(t *testing.T) {
+		container := di.NewContainer()
+		defer testutils.Close(t, container)
+
+		mappings := config.Mappings{
+			{
+				From: hosts.Parse("{host}"),
+				To:   hosts.Parse("{host}"),
+				CORS: config.CORSConfig{Passthrough: true},
+			},
+		}
+
+		factory := urlreplacer.NewURLReplacerFactory(mappings)
+		httpMock := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusForbidden,
+				Header: http.Header{
+					headers.AccessControlAllowOrigin: {"https://prod.example.com"},
+				},
+				Body: io.NopCloser(strings.NewReader("")),
+			}, nil
+		})
+
+		routerInstance, err := router.NewRouter(
+			mappings,
+			router.ForRouterWithDefaultHandler(proxyFactory(t, factory, httpMock)),
+			router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+			router.WithDiContainer(container),
+		)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodOptions, "http://localhost/api", nil)
+		request.Header.Set(headers.Origin, "http://localhost")
+
+		serveHTTP(t, routerInstance, recorder, request)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Equal(t, "https://prod.example.com", recorder.Header().Get(headers.AccessControlAllowOrigin))
+		assert.Empty(t, recorder.Header().Get(headers.AccessControlAllowCredentials))
+	})
 }
 
 func TestRouterMockMiddleware(t *testing.T) {
@@ -783,8 +1079,8 @@ func TestRouterMockMiddleware(t *testing.T) {
 					{
 						Matcher: config.RequestMatcher{
 							Path: userPath,
-							Queries: map[string]string{
-								"id": "17",
+							Queries: config.ValueMatchers{
+								"id": {Equals: "17"},
 							},
 						},
 						Response: config.Response{
@@ -795,9 +1091,9 @@ func TestRouterMockMiddleware(t *testing.T) {
 					{
 						Matcher: config.RequestMatcher{
 							Path: userPath,
-							Queries: map[string]string{
-								"id":    "99",
-								"token": "000000000000000000000000000000",
+							Queries: config.ValueMatchers{
+								"id":    {Equals: "99"},
+								"token": {Equals: "000000000000000000000000000000"},
 							},
 						},
 						Response: config.Response{
@@ -889,8 +1185,8 @@ func TestRouterMockMiddleware(t *testing.T) {
 					{
 						Matcher: config.RequestMatcher{
 							Path: userPath,
-							Headers: map[string]string{
-								headers.XCSRFToken: "de4e27987d054577b0edc0e828851724",
+							Headers: config.ValueMatchers{
+								headers.XCSRFToken: {Equals: "de4e27987d054577b0edc0e828851724"},
 							},
 						},
 						Response: config.Response{
@@ -901,9 +1197,9 @@ func TestRouterMockMiddleware(t *testing.T) {
 					{
 						Matcher: config.RequestMatcher{
 							Path: userPath,
-							Headers: map[string]string{
-								userIDHeader:       "99",
-								headers.XCSRFToken: "000000000000000000000000000000",
+							Headers: config.ValueMatchers{
+								userIDHeader:       {Equals: "99"},
+								headers.XCSRFToken: {Equals: "000000000000000000000000000000"},
 							},
 						},
 						Response: config.Response{
@@ -999,3 +1295,508 @@ func TestRouterMockMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestRouterMockSequencesAndScenarios(t *testing.T) {
+	const ordersPath = "http://localhost/api/orders/1"
+
+	newRouter := func(t *testing.T, mocksConfig config.Mocks, options ...router.Option) http.Handler {
+		t.Helper()
+
+		container := di.NewContainer()
+		t.Cleanup(func() { testutils.Close(t, container) })
+
+		routerInstance, err := router.NewRouter(
+			config.Mappings{
+				{
+					From:  hosts.Parse("{host}"),
+					To:    hosts.Parse("{host}"),
+					Mocks: mocksConfig,
+				},
+			},
+			append([]router.Option{
+				router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+				router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+				router.WithDiContainer(container),
+			}, options...)...,
+		)
+		require.NoError(t, err)
+
+		return routerInstance
+	}
+
+	serve := func(t *testing.T, handler http.Handler, method, url string) (int, string) {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), method, url, nil)
+		serveHTTP(t, handler, recorder, request)
+
+		return recorder.Code, testutils.ReadBody(t, recorder)
+	}
+
+	pending := config.Response{Code: http.StatusAccepted, Raw: "pending"}
+	done := config.Response{Code: http.StatusOK, Raw: "done"}
+
+	t.Run("sequence repeats last response", func(t *testing.T) {
+		handler := newRouter(t, config.Mocks{
+			{
+				Matcher:  config.RequestMatcher{Path: "/api/orders/{id}"},
+				Sequence: config.MockSequence{Responses: []config.Response{pending, pending, done}},
+			},
+		})
+
+		expected := []string{"pending", "pending", "done", "done"}
+		for _, body := range expected {
+			_, actual := serve(t, handler, http.MethodGet, ordersPath)
+			assert.Equal(t, body, actual)
+		}
+	})
+
+	t.Run("looped sequence starts over", func(t *testing.T) {
+		handler := newRouter(t, config.Mocks{
+			{
+				Matcher:  config.RequestMatcher{Path: "/api/orders/{id}"},
+				Sequence: config.MockSequence{Responses: []config.Response{pending, done}, Loop: true},
+			},
+		})
+
+		expected := []int{http.StatusAccepted, http.StatusOK, http.StatusAccepted, http.StatusOK}
+		for _, code := range expected {
+			actual, _ := serve(t, handler, http.MethodGet, ordersPath)
+			assert.Equal(t, code, actual)
+		}
+	})
+
+	scenarioMocks := config.Mocks{
+		{
+			Matcher:  config.RequestMatcher{Path: "/api/orders/{id}", Method: http.MethodGet},
+			Response: config.Response{Code: http.StatusOK, Raw: "shipped"},
+			Scenario: config.MockScenario{Name: "order", State: "shipped"},
+		},
+		{
+			Matcher:  config.RequestMatcher{Path: "/api/orders/{id}", Method: http.MethodGet},
+			Response: config.Response{Code: http.StatusOK, Raw: "pending"},
+		},
+		{
+			Matcher:  config.RequestMatcher{Path: "/api/orders/{id}/ship", Method: http.MethodPost},
+			Response: config.Response{Code: http.StatusNoContent, Raw: " "},
+			Scenario: config.MockScenario{Name: "order", NewState: "shipped"},
+		},
+	}
+
+	t.Run("scenario switches mocks by state", func(t *testing.T) {
+		handler := newRouter(t, scenarioMocks)
+
+		_, body := serve(t, handler, http.MethodGet, ordersPath)
+		assert.Equal(t, "pending", body)
+
+		code, _ := serve(t, handler, http.MethodPost, ordersPath+"/ship")
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, body = serve(t, handler, http.MethodGet, ordersPath)
+		assert.Equal(t, "shipped", body)
+	})
+
+	t.Run("scenario state is shared and can be reset", func(t *testing.T) {
+		scenarios := mock.NewScenarios()
+
+		first := newRouter(t, scenarioMocks, router.WithScenarios(scenarios))
+		serve(t, first, http.MethodPost, ordersPath+"/ship")
+
+		second := newRouter(t, scenarioMocks, router.WithScenarios(scenarios))
+		_, body := serve(t, second, http.MethodGet, ordersPath)
+		assert.Equal(t, "shipped", body)
+
+		scenarios.Reset()
+
+		_, body = serve(t, second, http.MethodGet, ordersPath)
+		assert.Equal(t, "pending", body)
+	})
+}
+
+func TestRouterRequestConditions(t *testing.T) {
+	container := di.NewContainer()
+	defer testutils.Close(t, container)
+
+	respond := func(body string) config.Response {
+		return config.Response{Code: http.StatusOK, Raw: body}
+	}
+
+	routerInstance, err := router.NewRouter(
+		config.Mappings{
+			{
+				From: hosts.Parse("{host}"),
+				To:   hosts.Parse("{host}"),
+				Mocks: config.Mocks{
+					{
+						Matcher: config.RequestMatcher{
+							Path:   "/login",
+							Method: http.MethodPost,
+							Body: config.BodyMatchers{
+								{Field: "username", ValueMatcher: config.ValueMatcher{Equals: "locked"}},
+							},
+						},
+						Response: respond("locked"),
+					},
+					{
+						Matcher: config.RequestMatcher{
+							Path:   "/login",
+							Method: http.MethodPost,
+							Body: config.BodyMatchers{
+								{Field: "devices.0.type", ValueMatcher: config.ValueMatcher{Regex: "^(ios|android)$"}},
+								{Field: "password", ValueMatcher: config.ValueMatcher{Absent: true}},
+							},
+						},
+						Response: respond("mobile token"),
+					},
+					{
+						Matcher: config.RequestMatcher{
+							Path:   "/login",
+							Method: http.MethodPost,
+							Body: config.BodyMatchers{
+								{ValueMatcher: config.ValueMatcher{Contains: "remember"}},
+							},
+						},
+						Response: config.Response{Code: http.StatusOK, Raw: "echo {{ .RawBody }}", Template: true},
+					},
+					{
+						Matcher:  config.RequestMatcher{Path: "/login", Method: http.MethodPost},
+						Response: respond("ok"),
+					},
+					{
+						Matcher: config.RequestMatcher{
+							Path:    "/profile",
+							Headers: config.ValueMatchers{"User-Agent": {Glob: "*Mobile*"}},
+						},
+						Response: respond("mobile profile"),
+					},
+					{
+						Matcher: config.RequestMatcher{
+							Path:    "/profile",
+							Headers: config.ValueMatchers{"X-Debug": {Absent: true}},
+						},
+						Response: respond("profile"),
+					},
+					{
+						Matcher: config.RequestMatcher{
+							Path:    "/profile",
+							Queries: config.ValueMatchers{"fields": {Contains: "email"}},
+						},
+						Response: respond("debug profile with email"),
+					},
+					{
+						Matcher:  config.RequestMatcher{Path: "/profile"},
+						Response: respond("debug profile"),
+					},
+				},
+			},
+		},
+		router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+		router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+		router.WithDiContainer(container),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		headers  map[string]string
+		body     string
+		expected string
+	}{
+		{
+			name:     "body field equals",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     `{"username": "locked", "password": "secret"}`,
+			expected: "locked",
+		},
+		{
+			name:     "other body field value",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     `{"username": "john", "password": "secret"}`,
+			expected: "ok",
+		},
+		{
+			name:     "nested array field regex with absent field",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     `{"devices": [{"type": "ios"}]}`,
+			expected: "mobile token",
+		},
+		{
+			name:     "nested array field regex with present field",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     `{"devices": [{"type": "ios"}], "password": "secret"}`,
+			expected: "ok",
+		},
+		{
+			name:     "raw body contains and body stays readable",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     "user=john&remember=on",
+			expected: "echo user=john&remember=on",
+		},
+		{
+			name:     "body over the size limit does not match body conditions",
+			method:   http.MethodPost,
+			url:      "http://localhost/login",
+			body:     "remember=on&data=" + strings.Repeat("x", 1<<20),
+			expected: "ok",
+		},
+		{
+			name:     "header glob",
+			method:   http.MethodGet,
+			url:      "http://localhost/profile",
+			headers:  map[string]string{"User-Agent": "Mozilla/5.0 (iPhone) Mobile/15E148", "X-Debug": "1"},
+			expected: "mobile profile",
+		},
+		{
+			name:     "header absent",
+			method:   http.MethodGet,
+			url:      "http://localhost/profile",
+			expected: "profile",
+		},
+		{
+			name:     "query contains",
+			method:   http.MethodGet,
+			url:      "http://localhost/profile?fields=name,email",
+			headers:  map[string]string{"X-Debug": "1"},
+			expected: "debug profile with email",
+		},
+		{
+			name:     "fallback",
+			method:   http.MethodGet,
+			url:      "http://localhost/profile?fields=name",
+			headers:  map[string]string{"X-Debug": "1"},
+			expected: "debug profile",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequestWithContext(
+				t.Context(),
+				testCase.method,
+				testCase.url,
+				strings.NewReader(testCase.body),
+			)
+			for key, value := range testCase.headers {
+				request.Header.Set(key, value)
+			}
+
+			recorder := httptest.NewRecorder()
+
+			serveHTTP(t, routerInstance, recorder, request)
+
+			assert.Equal(t, testCase.expected, testutils.ReadBody(t, recorder))
+		})
+	}
+}
+
+func TestRouterPriority(t *testing.T) {
+	fs := testutils.FsFromMap(t, map[string]string{
+		"/assets/user": "static user",
+	})
+
+	container := di.NewContainer(di.WithFs(fs))
+	defer testutils.Close(t, container)
+
+	respond := func(body string) config.Response {
+		return config.Response{Code: http.StatusOK, Raw: body}
+	}
+
+	newRouter := func(t *testing.T, mapping config.Mapping, options ...router.Option) http.Handler {
+		t.Helper()
+
+		mapping.From = hosts.Localhost.HTTP()
+		mapping.To = hosts.Localhost.HTTPS()
+
+		routerInstance, err := router.NewRouter(
+			config.Mappings{mapping},
+			append([]router.Option{
+				router.ForRouterWithDefaultHandler(proxyFactory(t, nil, nil)),
+				router.ForRouterWithCacheMiddlewareFactory(cacheFactory()),
+				router.WithDiContainer(container),
+			}, options...)...,
+		)
+		require.NoError(t, err)
+
+		return routerInstance
+	}
+
+	serve := func(t *testing.T, handler http.Handler, method, url string) string {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), method, url, nil)
+		serveHTTP(t, handler, recorder, request)
+
+		return testutils.ReadBody(t, recorder)
+	}
+
+	t.Run("statics before mocks by default", func(t *testing.T) {
+		handler := newRouter(t, config.Mapping{
+			Statics: config.StaticDirectories{{Path: "/api", Dir: "/assets"}},
+			Mocks: config.Mocks{
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("any method")},
+				{Matcher: config.RequestMatcher{Path: userPath, Method: http.MethodPost}, Response: respond("post")},
+			},
+		})
+
+		assert.Equal(t, "static user", serve(t, handler, http.MethodGet, "http://localhost/api/user"))
+	})
+
+	t.Run("specific mock before path-only mock", func(t *testing.T) {
+		handler := newRouter(t, config.Mapping{
+			Mocks: config.Mocks{
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("any method")},
+				{Matcher: config.RequestMatcher{Path: userPath, Method: http.MethodPost}, Response: respond("post")},
+			},
+		})
+
+		assert.Equal(t, "post", serve(t, handler, http.MethodPost, "http://localhost/api/user"))
+	})
+
+	t.Run("priority overrides default order", func(t *testing.T) {
+		handler := newRouter(t, config.Mapping{
+			Statics: config.StaticDirectories{{Path: "/api", Dir: "/assets"}},
+			Mocks: config.Mocks{
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("any method"), Priority: 10},
+				{Matcher: config.RequestMatcher{Path: userPath, Method: http.MethodPost}, Response: respond("post")},
+			},
+		})
+
+		assert.Equal(t, "any method", serve(t, handler, http.MethodPost, "http://localhost/api/user"))
+	})
+
+	t.Run("higher priority wins between mocks and scripts", func(t *testing.T) {
+		handler := newRouter(t, config.Mapping{
+			Mocks: config.Mocks{
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("mock")},
+			},
+			Scripts: config.Scripts{
+				{Matcher: config.RequestMatcher{Path: userPath}, Script: `response:WriteString("script")`, Priority: 1},
+			},
+		})
+
+		assert.Equal(t, "script", serve(t, handler, http.MethodGet, "http://localhost/api/user"))
+	})
+
+	t.Run("debug output explains resolution", func(t *testing.T) {
+		output := mocks.NewOutputMock(t).DebugMock.Expect(strings.Join([]string{
+			"route resolution for GET http://localhost/api/user",
+			"  mapping localhost:",
+			`    skipped mock [POST 200] /api/user (priority 5): method is not POST, header "User-Id" does not match`,
+			"    skipped static /static => /assets: path is not under /static/",
+			"    matched mock [* 200] /api/user",
+		}, "\n")).Return()
+
+		handler := newRouter(t, config.Mapping{
+			Statics: config.StaticDirectories{{Path: "/static", Dir: "/assets"}},
+			Mocks: config.Mocks{
+				{
+					Matcher: config.RequestMatcher{
+						Path:    userPath,
+						Method:  http.MethodPost,
+						Headers: config.ValueMatchers{userIDHeader: {Equals: "1"}},
+					},
+					Response: respond("post"),
+					Priority: 5,
+				},
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("any method")},
+			},
+		}, router.WithDebugOutput(output))
+
+		assert.Equal(t, "any method", serve(t, handler, http.MethodGet, "http://localhost/api/user"))
+	})
+
+	t.Run("debug output explains fallback to the default handler", func(t *testing.T) {
+		output := mocks.NewOutputMock(t).DebugMock.Expect(strings.Join([]string{
+			"route resolution for GET http://localhost/api/other",
+			"  mapping localhost:",
+			"    skipped mock [* 200] /api/user: path does not match /api/user",
+			"    no route matched, using the default handler",
+		}, "\n")).Return()
+
+		handler := newRouter(t, config.Mapping{
+			Mocks: config.Mocks{
+				{Matcher: config.RequestMatcher{Path: userPath}, Response: respond("any method")},
+			},
+		}, router.WithDebugOutput(output), router.ForRouterWithDefaultHandler(
+			infra.HandlerFunc(func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+				_, err := io.WriteString(writer, "proxied")
+
+				return err
+			}),
+		))
+
+		assert.Equal(t, "proxied", serve(t, handler, http.MethodGet, "http://localhost/api/other"))
+	})
+
+	t.Run("debug output checks the body once per route", func(t *testing.T) {
+		output := mocks.NewOutputMock(t).DebugMock.Expect(strings.Join([]string{
+			"route resolution for POST http://localhost/api/user",
+			"  mapping localhost:",
+			"    skipped mock [POST 200] /api/user: body does not match",
+			"    matched mock [POST 200] /api/user",
+		}, "\n")).Return()
+
+		bodyMock := func(name string) config.Mock {
+			return config.Mock{
+				Matcher: config.RequestMatcher{
+					Path:   userPath,
+					Method: http.MethodPost,
+					Body:   config.BodyMatchers{{Field: "name", ValueMatcher: config.ValueMatcher{Equals: name}}},
+				},
+				Response: respond(name),
+			}
+		}
+
+		handler := newRouter(t, config.Mapping{
+			Mocks: config.Mocks{bodyMock("Jane"), bodyMock("John")},
+		}, router.WithDebugOutput(output))
+
+		body := &countingReader{Reader: strings.NewReader(`{"name": "John"}`)}
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "http://localhost/api/user", body)
+		serveHTTP(t, handler, recorder, request)
+
+		assert.Equal(t, "John", testutils.ReadBody(t, recorder))
+		assert.Equal(t, 2, body.reads)
+	})
+
+	t.Run("debug output reports unmatched host", func(t *testing.T) {
+		output := mocks.NewOutputMock(t).DebugMock.Expect(strings.Join([]string{
+			"route resolution for GET http://example.com/api",
+			"  mapping localhost: host does not match",
+			"  no mapping matched",
+		}, "\n")).Return()
+
+		handler := newRouter(t, config.Mapping{
+			Rewrites: config.RewriteOptions{{From: "/old", To: "/new"}},
+		}, router.WithDebugOutput(output))
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/api", nil)
+		serveHTTP(t, handler, httptest.NewRecorder(), request)
+	})
+}
+
+// countingReader counts how many times the request body is read to the end.
+type countingReader struct {
+	io.Reader
+
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.reads++
+	}
+
+	return n, err
+}
@@ -1,28 +1,162 @@
 package router
 
 import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
 	"github.com/evg4b/uncors/internal/config"
+	"github.com/gorilla/mux"
+)
+
+// routeKind defines the order of route types with the same priority.
+type routeKind int
+
+const (
+	staticRoute routeKind = iota
+	mockRoute
+	scriptRoute
+	rewriteRoute
 )
 
-// registerMatchedRoutes registers routes in two passes: specific matchers first, path-only matchers second.
-// This ensures specific routes take priority over catch-all path routes in gorilla/mux.
-func registerMatchedRoutes[T any](
-	items []T,
-	matcher func(*T) *config.RequestMatcher,
-	register func(*T),
-) {
-	var defaults []*T
-
-	for i := range items {
-		item := &items[i]
-		if !matcher(item).IsPathOnly() {
-			register(item)
-		} else {
-			defaults = append(defaults, item)
+func (k routeKind) String() string {
+	switch k {
+	case staticRoute:
+		return "static"
+	case mockRoute:
+		return "mock"
+	case scriptRoute:
+		return "script"
+	default:
+		return "rewrite"
+	}
+}
+
+// routeCheck is a single condition of a route and the reason reported
+// when a request does not satisfy it.
+type routeCheck struct {
+	reason string
+	match  func(*http.Request) bool
+}
+
+type routeEntry struct {
+	kind     routeKind
+	priority int
+	pathOnly bool
+	name     string
+	checks   []routeCheck
+	register func(entry *routeEntry)
+}
+
+func (e *routeEntry) String() string {
+	if e.priority != 0 {
+		return fmt.Sprintf("%s %s (priority %d)", e.kind, e.name, e.priority)
+	}
+
+	return fmt.Sprintf("%s %s", e.kind, e.name)
+}
+
+// failures returns the reasons why the request does not match the route.
+func (e *routeEntry) failures(request *http.Request) []string {
+	var reasons []string
+
+	for _, check := range e.checks {
+		if !check.match(request) {
+			reasons = append(reasons, check.reason)
 		}
 	}
 
-	for _, item := range defaults {
-		register(item)
+	return reasons
+}
+
+// sortRoutes orders routes the way they are resolved: higher priority first,
+// then statics, mocks, scripts and rewrites, then routes with method, query,
+// header or body conditions before path-only routes, then configuration order.
+func sortRoutes(routes []routeEntry) {
+	slices.SortStableFunc(routes, func(a, b routeEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.priority, a.priority),
+			cmp.Compare(a.kind, b.kind),
+			cmp.Compare(specificity(b.pathOnly), specificity(a.pathOnly)),
+		)
+	})
+}
+
+func specificity(pathOnly bool) int {
+	if pathOnly {
+		return 0
+	}
+
+	return 1
+}
+
+// matcherChecks splits the request matcher into separately evaluated checks
+// so the debug output can name every condition the request failed.
+func matcherChecks(matcher config.RequestMatcher) []routeCheck {
+	scratch := mux.NewRouter()
+
+	var checks []routeCheck
+
+	if matcher.Path != "" {
+		checks = append(checks, routeCheck{
+			reason: "path does not match " + matcher.Path,
+			match:  routeMatcher(createRoute(scratch, config.RequestMatcher{Path: matcher.Path})),
+		})
+	}
+
+	if matcher.Method != "" {
+		checks = append(checks, routeCheck{
+			reason: "method is not " + matcher.Method,
+			match:  routeMatcher(createRoute(scratch, config.RequestMatcher{Method: matcher.Method})),
+		})
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(matcher.Queries)) {
+		queries := config.ValueMatchers{key: matcher.Queries[key]}
+		checks = append(checks, routeCheck{
+			reason: fmt.Sprintf("query %q does not match", key),
+			match:  routeMatcher(createRoute(scratch, config.RequestMatcher{Queries: queries})),
+		})
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(matcher.Headers)) {
+		headers := config.ValueMatchers{key: matcher.Headers[key]}
+		checks = append(checks, routeCheck{
+			reason: fmt.Sprintf("header %q does not match", key),
+			match:  routeMatcher(createRoute(scratch, config.RequestMatcher{Headers: headers})),
+		})
+	}
+
+	if len(matcher.Body) > 0 {
+		checks = append(checks, routeCheck{
+			reason: "body does not match",
+			match:  routeMatcher(createRoute(scratch, config.RequestMatcher{Body: matcher.Body})),
+		})
+	}
+
+	return checks
+}
+
+// prefixChecks describes routes registered with registerPathHandler or
+// registerPrefixHandler.
+func prefixChecks(path string) []routeCheck {
+	scratch := mux.NewRouter()
+	clearPath, fullPath := normalizePath(path)
+	exact := routeMatcher(scratch.NewRoute().Path(clearPath))
+	prefix := routeMatcher(scratch.NewRoute().PathPrefix(fullPath))
+
+	return []routeCheck{{
+		reason: "path is not under " + fullPath,
+		match: func(request *http.Request) bool {
+			return exact(request) || prefix(request)
+		},
+	}}
+}
+
+func routeMatcher(route *mux.Route) func(*http.Request) bool {
+	return func(request *http.Request) bool {
+		return route.Match(request, &mux.RouteMatch{})
 	}
 }
@@ -2,4 +2,12 @@ package script
 
 import "errors"
 
-var ErrScriptFileNotFound = errors.New("script file not found")
+var (
+	ErrScriptFileNotFound = errors.New("script file not found")
+	ErrHTTPURLRequired    = errors.New("http request url is required")
+	ErrHTTPInvalidTimeout = errors.New("http timeout must be a positive number of seconds or a duration")
+	ErrStoreNotNumber     = errors.New("store value is not a number")
+
+	ErrScriptTimeout          = errors.New("script timed out")
+	ErrScriptInstructionLimit = errors.New("script exceeded the limit")
+)
@@ -8,14 +8,15 @@ import (
 
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/cors"
 	"github.com/evg4b/uncors/internal/helpers"
-	"github.com/evg4b/uncors/internal/infra"
 )
 
 type Handler struct {
-	script *config.Script
-	output contracts.Output
-	fs     afero.Fs
+	script  *config.Script
+	output  contracts.Output
+	fs      afero.Fs
+	runtime *Runtime
 }
 
 func NewHandler(options ...HandlerOption) *Handler {
@@ -34,11 +35,10 @@ func (h *Handler) ServeHTTP(writer contracts.ResponseWriter, request *contracts.
 }
 
 func (h *Handler) executeScript(writer contracts.ResponseWriter, request *contracts.Request) error {
-	luaState := newLuaState()
+	luaState := newLuaState(h.runtime, &h.script.Sandbox)
 	defer luaState.Close()
 
-	origin := request.Header.Get("Origin")
-	infra.WriteCorsHeaders(writer.Header(), origin)
+	cors.WriteHeaders(writer.Header(), request)
 
 	reqTable := createRequestTable(luaState, request)
 	respTable := createResponseTable(luaState, writer)
@@ -46,7 +46,9 @@ func (h *Handler) executeScript(writer contracts.ResponseWriter, request *contra
 	luaState.SetGlobal("request", reqTable)
 	luaState.SetGlobal("response", respTable)
 
-	err := h.runScript(luaState)
+	err := sandboxed(request.Context(), luaState, &h.script.Sandbox, func() error {
+		return h.runScript(luaState)
+	})
 	if err != nil {
 		return fmt.Errorf("script error: %w", err)
 	}
@@ -55,11 +57,16 @@ func (h *Handler) executeScript(writer contracts.ResponseWriter, request *contra
 }
 
 func (h *Handler) runScript(luaState *lua.LState) error {
-	if h.script.Script != "" {
-		return luaState.DoString(h.script.Script)
+	return runScript(luaState, h.fs, h.script.Script, h.script.File)
+}
+
+// runScript executes the inline script or, when it is empty, the script file.
+func runScript(luaState *lua.LState, fs afero.Fs, script, file string) error {
+	if script != "" {
+		return luaState.DoString(script)
 	}
 
-	scriptContent, err := afero.ReadFile(h.fs, h.script.File)
+	scriptContent, err := afero.ReadFile(fs, file)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrScriptFileNotFound, err.Error())
 	}
@@ -86,3 +93,9 @@ func WithFileSystem(fs afero.Fs) HandlerOption {
 		h.fs = fs
 	}
 }
+
+func WithRuntime(runtime *Runtime) HandlerOption {
+	return func(h *Handler) {
+		h.runtime = runtime
+	}
+}
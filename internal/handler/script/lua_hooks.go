@@ -0,0 +1,216 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-http-utils/headers"
+	lua "github.com/yuin/gopher-lua"
+	luajson "layeh.com/gopher-json"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/rewrite"
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/pkg/urlt"
+)
+
+// hook returns the global function with the given name or nil when the
+// script does not define it.
+func hook(luaState *lua.LState, name string) *lua.LFunction {
+	function, ok := luaState.GetGlobal(name).(*lua.LFunction)
+	if !ok {
+		return nil
+	}
+
+	return function
+}
+
+func callHook(luaState *lua.LState, function *lua.LFunction, args ...lua.LValue) error {
+	return luaState.CallByParam(lua.P{Fn: function, NRet: 0, Protect: true}, args...)
+}
+
+// setRequestTarget exposes the host the request is forwarded to when a
+// rewrite has already changed it.
+func setRequestTarget(reqTable *lua.LTable, request *contracts.Request) {
+	if host, err := rewrite.GetRewriteHost(request); err == nil && host != "" {
+		reqTable.RawSetString("target", lua.LString(host))
+	}
+}
+
+// applyRequestTable copies the changes made by on_request back to the request.
+func applyRequestTable(reqTable *lua.LTable, request *contracts.Request, body []byte) *contracts.Request {
+	if method := tableString(reqTable, "method"); method != "" {
+		request.Method = method
+	}
+
+	if path := tableString(reqTable, "path"); path != "" && path != request.URL.Path {
+		request.URL.Path = path
+		request.URL.RawPath = ""
+	}
+
+	applyQuery(reqTable, request)
+
+	request.Header = headerFromTable(reqTable.RawGetString("headers"))
+
+	if newBody, ok := reqTable.RawGetString("body").(lua.LString); ok && string(newBody) != string(body) {
+		body = []byte(newBody)
+		request.ContentLength = int64(len(body))
+
+		if request.Header.Get(headers.ContentLength) != "" {
+			request.Header.Set(headers.ContentLength, strconv.Itoa(len(body)))
+		}
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	target := tableString(reqTable, "target")
+	if current, _ := rewrite.GetRewriteHost(request); target != "" && target != current {
+		request = request.WithContext(context.WithValue(request.Context(), rewrite.RewriteHostKey, target))
+	}
+
+	return request
+}
+
+// applyQuery prefers a changed raw query string over changed query_params.
+// The query is rebuilt from query_params only when they differ from the
+// original ones, so the parameter order of untouched requests is kept.
+func applyQuery(reqTable *lua.LTable, request *contracts.Request) {
+	if query, ok := reqTable.RawGetString("query").(lua.LString); ok && string(query) != request.URL.RawQuery {
+		request.URL.RawQuery = string(query)
+
+		return
+	}
+
+	params := url.Values(valuesFromTable(reqTable.RawGetString("query_params")))
+	if encoded := params.Encode(); encoded != urlt.URL_Query(request.URL).Encode() {
+		request.URL.RawQuery = encoded
+	}
+}
+
+// createHookResponseTable exposes the buffered response to on_response. JSON
+// bodies are also decoded into the json field; the returned encoding of that
+// value is used to detect whether the script changed it.
+func createHookResponseTable(luaState *lua.LState, buffer *infra.BufferedWriter) (*lua.LTable, []byte) {
+	capture := buffer.Captured()
+	respTable := luaState.NewTable()
+
+	respTable.RawSetString("status", lua.LNumber(capture.StatusCode))
+	respTable.RawSetString("headers", createHeadersTable(luaState, capture.Header))
+	respTable.RawSetString("body", lua.LString(capture.Body))
+
+	if !isJSON(capture.Header) {
+		return respTable, nil
+	}
+
+	value, err := luajson.Decode(luaState, capture.Body)
+	if err != nil {
+		return respTable, nil
+	}
+
+	encoded, err := luajson.Encode(value)
+	if err != nil {
+		return respTable, nil
+	}
+
+	respTable.RawSetString("json", value)
+
+	return respTable, encoded
+}
+
+// applyResponseTable copies the changes made by on_response back to the
+// buffered response. A changed body wins over a changed json field.
+func applyResponseTable(respTable *lua.LTable, buffer *infra.BufferedWriter, jsonBody []byte) error {
+	if status, ok := respTable.RawGetString("status").(lua.LNumber); ok {
+		buffer.SetStatusCode(int(status))
+	}
+
+	buffer.SetHeader(headerFromTable(respTable.RawGetString("headers")))
+
+	body := buffer.Captured().Body
+
+	if newBody, ok := respTable.RawGetString("body").(lua.LString); ok && string(newBody) != string(body) {
+		buffer.SetBody([]byte(newBody))
+
+		return nil
+	}
+
+	value := respTable.RawGetString("json")
+	if value == lua.LNil {
+		return nil
+	}
+
+	encoded, err := luajson.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(encoded, jsonBody) {
+		buffer.SetBody(encoded)
+	}
+
+	return nil
+}
+
+func isJSON(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get(headers.ContentType))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func tableString(table *lua.LTable, key string) string {
+	if value, ok := table.RawGetString(key).(lua.LString); ok {
+		return string(value)
+	}
+
+	return ""
+}
+
+func headerFromTable(value lua.LValue) http.Header {
+	header := http.Header{}
+
+	for key, values := range valuesFromTable(value) {
+		for _, item := range values {
+			header.Add(key, item)
+		}
+	}
+
+	return header
+}
+
+// valuesFromTable reads a table in the format of createHeadersTable: every key
+// holds a single value or a list of values.
+func valuesFromTable(value lua.LValue) map[string][]string {
+	values := map[string][]string{}
+
+	table, ok := value.(*lua.LTable)
+	if !ok {
+		return values
+	}
+
+	table.ForEach(func(key, item lua.LValue) {
+		name, ok := key.(lua.LString)
+		if !ok {
+			return
+		}
+
+		switch typed := item.(type) {
+		case *lua.LTable:
+			typed.ForEach(func(_, element lua.LValue) {
+				values[string(name)] = append(values[string(name)], element.String())
+			})
+		case lua.LString, lua.LNumber, lua.LBool:
+			values[string(name)] = append(values[string(name)], typed.String())
+		}
+	})
+
+	return values
+}
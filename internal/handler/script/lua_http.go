@@ -0,0 +1,182 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// Argument positions of the http module functions.
+const (
+	httpArgURL         = 1
+	httpArgOptions     = 1
+	httpArgGetOptions  = 2
+	httpArgPostBody    = 2
+	httpArgPostOptions = 3
+)
+
+// httpModule is the Lua "http" module. Requests go through the HTTP client of
+// the proxy and are cancelled together with the request that runs the script
+// or when the script runs out of time.
+type httpModule struct {
+	client contracts.HTTPClient
+}
+
+func preloadHTTPModule(luaState *lua.LState, client contracts.HTTPClient) {
+	module := &httpModule{client: client}
+	luaState.PreloadModule("http", module.loader)
+}
+
+func (m *httpModule) loader(luaState *lua.LState) int {
+	luaState.Push(luaState.SetFuncs(luaState.NewTable(), map[string]lua.LGFunction{
+		"get":     m.get,
+		"post":    m.post,
+		"request": m.request,
+	}))
+
+	return luaReturnOne
+}
+
+// get implements http.get(url, [options]).
+func (m *httpModule) get(luaState *lua.LState) int {
+	options := httpOptions(luaState, httpArgGetOptions)
+	options.RawSetString("method", lua.LString(http.MethodGet))
+	options.RawSetString("url", lua.LString(luaState.CheckString(httpArgURL)))
+
+	return m.do(luaState, options)
+}
+
+// post implements http.post(url, body, [options]).
+func (m *httpModule) post(luaState *lua.LState) int {
+	options := httpOptions(luaState, httpArgPostOptions)
+	options.RawSetString("method", lua.LString(http.MethodPost))
+	options.RawSetString("url", lua.LString(luaState.CheckString(httpArgURL)))
+	options.RawSetString("body", lua.LString(luaState.OptString(httpArgPostBody, "")))
+
+	return m.do(luaState, options)
+}
+
+// request implements http.request(options).
+func (m *httpModule) request(luaState *lua.LState) int {
+	return m.do(luaState, luaState.CheckTable(httpArgOptions))
+}
+
+// httpOptions copies the optional options argument, so the shortcuts can set
+// the method and URL without changing the table of the script.
+func httpOptions(luaState *lua.LState, position int) *lua.LTable {
+	options := luaState.NewTable()
+
+	if given := luaState.OptTable(position, nil); given != nil {
+		given.ForEach(func(key, value lua.LValue) {
+			options.RawSet(key, value)
+		})
+	}
+
+	return options
+}
+
+// do sends the request described by the options table and returns the
+// response table, or nil and an error message when the request fails.
+func (m *httpModule) do(luaState *lua.LState, options *lua.LTable) int {
+	request, cancel, err := m.makeRequest(executionContext(luaState), options)
+	if err != nil {
+		luaState.RaiseError("%s", err.Error())
+
+		return 0
+	}
+
+	defer cancel()
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return pushHTTPError(luaState, err)
+	}
+
+	defer helpers.CloseSafe(response.Body)
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return pushHTTPError(luaState, err)
+	}
+
+	respTable := luaState.NewTable()
+	respTable.RawSetString("status", lua.LNumber(response.StatusCode))
+	respTable.RawSetString("headers", createHeadersTable(luaState, response.Header))
+	respTable.RawSetString("body", lua.LString(body))
+
+	luaState.Push(respTable)
+	luaState.Push(lua.LNil)
+
+	return luaReturnTwo
+}
+
+func (m *httpModule) makeRequest(ctx context.Context, options *lua.LTable) (*http.Request, context.CancelFunc, error) {
+	url := tableString(options, "url")
+	if url == "" {
+		return nil, nil, ErrHTTPURLRequired
+	}
+
+	method := strings.ToUpper(tableString(options, "method"))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	timeout, err := httpTimeout(options.RawGetString("timeout"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body io.Reader
+	if value, ok := options.RawGetString("body").(lua.LString); ok && value != "" {
+		body = strings.NewReader(string(value))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		cancel()
+
+		return nil, nil, fmt.Errorf("invalid http request: %w", err)
+	}
+
+	request.Header = headerFromTable(options.RawGetString("headers"))
+
+	return request, cancel, nil
+}
+
+// httpTimeout accepts a number of seconds or a duration string such as "500ms".
+func httpTimeout(value lua.LValue) (time.Duration, error) {
+	switch typed := value.(type) {
+	case *lua.LNilType:
+		return defaultHTTPTimeout, nil
+	case lua.LNumber:
+		if typed > 0 {
+			return time.Duration(float64(typed) * float64(time.Second)), nil
+		}
+	case lua.LString:
+		duration, err := time.ParseDuration(string(typed))
+		if err == nil && duration > 0 {
+			return duration, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrHTTPInvalidTimeout, value.String())
+}
+
+func pushHTTPError(luaState *lua.LState, err error) int {
+	luaState.Push(lua.LNil)
+	luaState.Push(lua.LString(err.Error()))
+
+	return luaReturnTwo
+}
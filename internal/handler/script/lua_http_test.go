@@ -0,0 +1,261 @@
+package script_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnectionRefused = errors.New("connection refused")
+
+func runHTTPScript(t *testing.T, client contracts.HTTPClient, source string) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+
+	handler := script.NewHandler(
+		script.WithOutput(mocks.NoopOutput()),
+		script.WithScript(&config.Script{Script: source}),
+		script.WithFileSystem(testutils.FsFromMap(t, map[string]string{})),
+		script.WithRuntime(&script.Runtime{HTTPClient: client}),
+	)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+	recorder := httptest.NewRecorder()
+
+	err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+	return recorder, err
+}
+
+func textResponse(request *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Request:    request,
+		StatusCode: status,
+		Header:     http.Header{headers.ContentType: {"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestHTTPModule(t *testing.T) {
+	t.Run("get sends headers and returns the response", func(t *testing.T) {
+		client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, "https://api.example.com/users?page=1", req.URL.String())
+			assert.Equal(t, "Bearer token", req.Header.Get(headers.Authorization))
+
+			return textResponse(req, http.StatusOK, "users"), nil
+		})
+
+		recorder, err := runHTTPScript(t, client, `
+			local http = require("http")
+			local resp, err = http.get("https://api.example.com/users?page=1", {
+				headers = { Authorization = "Bearer token" },
+			})
+			response:WriteHeader(resp.status)
+			response:WriteString(resp.headers["Content-Type"] .. ":" .. resp.body)
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "text/plain:users", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("post sends body", func(t *testing.T) {
+		client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.JSONEq(t, `{"name":"John"}`, string(body))
+			assert.Equal(t, "application/json", req.Header.Get(headers.ContentType))
+
+			return textResponse(req, http.StatusCreated, "created"), nil
+		})
+
+		recorder, err := runHTTPScript(t, client, `
+			local http = require("http")
+			local options = { headers = { ["Content-Type"] = "application/json" } }
+			local resp = http.post("https://api.example.com/users", '{"name":"John"}', options)
+			response:WriteHeader(resp.status)
+			response:WriteString(tostring(options.method))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "nil", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("request uses method and timeout", func(t *testing.T) {
+		client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			require.True(t, ok)
+
+			assert.Equal(t, http.MethodPut, req.Method)
+			assert.WithinDuration(t, time.Now().Add(1500*time.Millisecond), deadline, time.Second)
+
+			return textResponse(req, http.StatusNoContent, ""), nil
+		})
+
+		recorder, err := runHTTPScript(t, client, `
+			local http = require("http")
+			local resp = http.request({
+				method = "put",
+				url = "https://api.example.com/users/1",
+				body = "data",
+				timeout = "1500ms",
+			})
+			response:WriteHeader(200)
+			response:WriteString(tostring(resp.status))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "204", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("timeout in seconds", func(t *testing.T) {
+		client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+
+			return textResponse(req, http.StatusOK, ""), nil
+		})
+
+		_, err := runHTTPScript(t, client, `
+			require("http").get("https://api.example.com/", { timeout = 5 })
+		`)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error message when the request fails", func(t *testing.T) {
+		client := mocks.NewHTTPClientMock(t).DoMock.Return(nil, errConnectionRefused)
+
+		recorder, err := runHTTPScript(t, client, `
+			local resp, err = require("http").get("https://api.example.com/")
+			response:WriteHeader(502)
+			response:WriteString(tostring(resp) .. ": " .. err)
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+		assert.Equal(t, "nil: connection refused", testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("is bound to the request context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+
+		var requestErr error
+
+		client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+			cancel()
+			<-req.Context().Done()
+			requestErr = req.Context().Err()
+
+			return nil, requestErr
+		})
+
+		handler := script.NewHandler(
+			script.WithOutput(mocks.NoopOutput()),
+			script.WithScript(&config.Script{Script: `
+				local _, err = require("http").get("https://api.example.com/")
+				response:WriteString(err)
+			`}),
+			script.WithRuntime(&script.Runtime{HTTPClient: client}),
+		)
+
+		request := httptest.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/", nil)
+		recorder := httptest.NewRecorder()
+
+		err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+		require.ErrorIs(t, requestErr, context.Canceled)
+		require.ErrorContains(t, err, context.Canceled.Error())
+	})
+
+	t.Run("invalid arguments raise script errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			source   string
+			expected string
+		}{
+			{
+				name:     "missing url",
+				source:   `require("http").request({ method = "GET" })`,
+				expected: script.ErrHTTPURLRequired.Error(),
+			},
+			{
+				name:     "invalid timeout",
+				source:   `require("http").get("https://api.example.com/", { timeout = "soon" })`,
+				expected: script.ErrHTTPInvalidTimeout.Error(),
+			},
+			{
+				name:     "negative timeout",
+				source:   `require("http").get("https://api.example.com/", { timeout = -1 })`,
+				expected: script.ErrHTTPInvalidTimeout.Error(),
+			},
+			{
+				name:     "invalid method",
+				source:   `require("http").request({ url = "https://api.example.com/", method = "BAD METHOD" })`,
+				expected: "invalid http request",
+			},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				client := mocks.NewHTTPClientMock(t)
+
+				_, err := runHTTPScript(t, client, testCase.source)
+
+				require.ErrorContains(t, err, testCase.expected)
+			})
+		}
+	})
+
+	t.Run("is not available without a client", func(t *testing.T) {
+		_, err := runHTTPScript(t, nil, `require("http")`)
+
+		require.ErrorContains(t, err, "module http not found")
+	})
+}
+
+func TestMiddleware_HTTPModule(t *testing.T) {
+	client := mocks.NewHTTPClientMock(t).DoMock.Set(func(req *http.Request) (*http.Response, error) {
+		return textResponse(req, http.StatusOK, `"beta"`), nil
+	})
+
+	middleware := script.NewMiddleware(
+		script.WithMiddlewareOutput(mocks.NoopOutput()),
+		script.WithMiddlewareScript(&config.ScriptMiddleware{Script: `
+			local http = require("http")
+			local json = require("json")
+
+			function on_response(response)
+				local resp = http.get("https://flags.example.com/channel")
+				response.json.channel = json.decode(resp.body)
+			end
+		`}),
+		script.WithMiddlewareRuntime(&script.Runtime{HTTPClient: client}),
+	)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+	recorder, err := serveMiddleware(t, middleware, request, writeJSON(`{"user":"John"}`))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"John","channel":"beta"}`, testutils.ReadBody(t, recorder))
+}
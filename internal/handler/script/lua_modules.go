@@ -0,0 +1,153 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+const (
+	// Position of the gopher-lua loader that reads modules from package.path in
+	// the working directory of the process. The first loader is package.preload.
+	fileLoaderPosition = 2
+	// A module is name.lua or name/init.lua in every directory.
+	patternsPerDir = 2
+)
+
+// Modules finds the Lua modules scripts load with require in the lua-path
+// directories. Compiled modules are cached and compiled again when their
+// file changes, so edits are picked up by the next request.
+type Modules struct {
+	fs    afero.Fs
+	dirs  []string
+	mutex sync.Mutex
+	cache map[string]compiledModule
+}
+
+type compiledModule struct {
+	modTime time.Time
+	size    int64
+	proto   *lua.FunctionProto
+}
+
+func NewModules(fs afero.Fs, dirs []string) *Modules {
+	return &Modules{
+		fs:    fs,
+		dirs:  dirs,
+		cache: map[string]compiledModule{},
+	}
+}
+
+// patterns returns the file patterns in the format of package.path.
+func (m *Modules) patterns() []string {
+	patterns := make([]string, 0, len(m.dirs)*patternsPerDir)
+
+	for _, dir := range m.dirs {
+		patterns = append(patterns, filepath.Join(dir, "?.lua"), filepath.Join(dir, "?", "init.lua"))
+	}
+
+	return patterns
+}
+
+// find returns the file of the module or the list of checked files.
+func (m *Modules) find(name string) (string, os.FileInfo, []string) {
+	name = strings.ReplaceAll(name, ".", string(filepath.Separator))
+	checked := make([]string, 0, len(m.dirs)*patternsPerDir)
+
+	for _, pattern := range m.patterns() {
+		file := strings.ReplaceAll(pattern, "?", name)
+
+		info, err := m.fs.Stat(file)
+		if err == nil && !info.IsDir() {
+			return file, info, nil
+		}
+
+		checked = append(checked, file)
+	}
+
+	return "", nil, checked
+}
+
+// compile returns the compiled module, using the cached one while the file
+// is unchanged.
+func (m *Modules) compile(file string, info os.FileInfo) (*lua.FunctionProto, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if cached, ok := m.cache[file]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.proto, nil
+	}
+
+	source, err := afero.ReadFile(m.fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module %s: %w", file, err)
+	}
+
+	chunk, err := parse.Parse(bytes.NewReader(source), file)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := lua.Compile(chunk, file)
+	if err != nil {
+		return nil, err
+	}
+
+	m.cache[file] = compiledModule{modTime: info.ModTime(), size: info.Size(), proto: proto}
+
+	return proto, nil
+}
+
+// loader is the package.loaders entry that loads modules from the lua-path
+// directories.
+func (m *Modules) loader(luaState *lua.LState) int {
+	name := luaState.CheckString(1)
+
+	file, info, checked := m.find(name)
+	if file == "" {
+		luaState.Push(lua.LString("\n\tno file '" + strings.Join(checked, "'\n\tno file '") + "'"))
+
+		return luaReturnOne
+	}
+
+	proto, err := m.compile(file, info)
+	if err != nil {
+		luaState.RaiseError("error loading module '%s' from file '%s':\n\t%s", name, file, err.Error())
+
+		return 0
+	}
+
+	luaState.Push(luaState.NewFunctionFromProto(proto))
+
+	return luaReturnOne
+}
+
+// setModuleLoader replaces the gopher-lua loader that reads package.path with
+// the loader of the lua-path directories. Without configured modules the
+// default loader and package.path are kept.
+func setModuleLoader(luaState *lua.LState, modules *Modules) {
+	if modules == nil {
+		return
+	}
+
+	packageTable, ok := luaState.GetGlobal("package").(*lua.LTable)
+	if !ok {
+		return
+	}
+
+	loaders, ok := packageTable.RawGetString("loaders").(*lua.LTable)
+	if !ok {
+		return
+	}
+
+	loaders.RawSetInt(fileLoaderPosition, luaState.NewFunction(modules.loader))
+	packageTable.RawSetString("path", lua.LString(strings.Join(modules.patterns(), ";")))
+}
@@ -0,0 +1,132 @@
+package script_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func runModulesScript(t *testing.T, modules *script.Modules, source string) (string, error) {
+	t.Helper()
+
+	handler := script.NewHandler(
+		script.WithOutput(mocks.NoopOutput()),
+		script.WithScript(&config.Script{Script: source}),
+		script.WithRuntime(&script.Runtime{Modules: modules}),
+	)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+	recorder := httptest.NewRecorder()
+
+	err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+	return testutils.ReadBody(t, recorder), err
+}
+
+func TestModules(t *testing.T) {
+	t.Run("requires modules from lua-path directories", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/lua/greeting.lua":         `return { hello = function(name) return "Hello, " .. name end }`,
+			"/lua/utils/strings.lua":    `return { upper = string.upper }`,
+			"/shared/validate/init.lua": `return { ok = function() return "ok" end }`,
+		})
+
+		body, err := runModulesScript(t, script.NewModules(fs, []string{"/lua", "/shared"}), `
+			local greeting = require("greeting")
+			local strings = require("utils.strings")
+			local validate = require("validate")
+			response:WriteString(strings.upper(greeting.hello("John")) .. " " .. validate.ok())
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO, JOHN ok", body)
+	})
+
+	t.Run("searches directories in order", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/mapping/config.lua": `return "mapping"`,
+			"/global/config.lua":  `return "global"`,
+		})
+
+		body, err := runModulesScript(t, script.NewModules(fs, []string{"/mapping", "/global"}), `
+			response:WriteString(require("config"))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "mapping", body)
+	})
+
+	t.Run("modules can require other modules", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/lua/a.lua": `return require("b") .. "a"`,
+			"/lua/b.lua": `return "b"`,
+		})
+
+		body, err := runModulesScript(t, script.NewModules(fs, []string{"/lua"}), `
+			response:WriteString(require("a"))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ba", body)
+	})
+
+	t.Run("reloads changed modules", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/lua/version.lua": `return "v1"`,
+		})
+		modules := script.NewModules(fs, []string{"/lua"})
+		source := `response:WriteString(require("version"))`
+
+		body, err := runModulesScript(t, modules, source)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", body)
+
+		require.NoError(t, afero.WriteFile(fs, "/lua/version.lua", []byte(`return "version 2"`), 0o644))
+
+		body, err = runModulesScript(t, modules, source)
+		require.NoError(t, err)
+		assert.Equal(t, "version 2", body)
+	})
+
+	t.Run("missing module lists checked files", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{})
+
+		_, err := runModulesScript(t, script.NewModules(fs, []string{"/lua"}), `require("missing")`)
+
+		require.ErrorContains(t, err, "module missing not found")
+		require.ErrorContains(t, err, "no file '/lua/missing.lua'")
+		require.ErrorContains(t, err, "no file '/lua/missing/init.lua'")
+	})
+
+	t.Run("syntax error in module", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{
+			"/lua/broken.lua": `return {`,
+		})
+
+		_, err := runModulesScript(t, script.NewModules(fs, []string{"/lua"}), `require("broken")`)
+
+		require.ErrorContains(t, err, "error loading module 'broken' from file '/lua/broken.lua'")
+	})
+
+	t.Run("keeps the default loader without lua-path", func(t *testing.T) {
+		t.Setenv(lua.LuaPath, "")
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("greeting.lua", []byte(`return { hello = "Hello" }`), 0o600))
+
+		body, err := runModulesScript(t, nil, `response:WriteString(require("greeting").hello .. " " .. package.path)`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Hello "+lua.LuaPathDefault, body)
+	})
+}
@@ -3,20 +3,40 @@ package script
 import (
 	lua "github.com/yuin/gopher-lua"
 	luajson "layeh.com/gopher-json"
-)
 
-func newLuaState() *lua.LState {
-	luaState := lua.NewState()
-	loadStandardLibraries(luaState)
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+)
 
-	return luaState
+// Runtime holds the services shared by the scripts of a mapping. The http
+// module is available only when an HTTP client is set, the store module only
+// when a store is set and modules of the lua-path only when Modules is set.
+type Runtime struct {
+	HTTPClient contracts.HTTPClient
+	Store      *Store
+	Modules    *Modules
 }
 
-func loadStandardLibraries(luaState *lua.LState) {
-	luaState.SetGlobal("_G", luaState.Get(lua.GlobalsIndex))
-	luaState.PreloadModule("math", lua.OpenMath)
-	luaState.PreloadModule("string", lua.OpenString)
-	luaState.PreloadModule("table", lua.OpenTable)
-	luaState.PreloadModule("os", lua.OpenOs)
+// newLuaState creates the state for one script run with the libraries and
+// limits allowed by the sandbox.
+func newLuaState(runtime *Runtime, sandbox *config.ScriptSandbox) *lua.LState {
+	luaState := lua.NewState(luaOptions(sandbox))
+	openLibraries(luaState, sandbox)
 	luajson.Preload(luaState)
+
+	if runtime == nil {
+		return luaState
+	}
+
+	setModuleLoader(luaState, runtime.Modules)
+
+	if runtime.HTTPClient != nil {
+		preloadHTTPModule(luaState, runtime.HTTPClient)
+	}
+
+	if runtime.Store != nil {
+		preloadStoreModule(luaState, runtime.Store)
+	}
+
+	return luaState
 }
@@ -0,0 +1,140 @@
+package script
+
+import (
+	"encoding/json"
+
+	lua "github.com/yuin/gopher-lua"
+	luajson "layeh.com/gopher-json"
+)
+
+// Argument positions of the store module functions.
+const (
+	storeArgKey    = 1
+	storeArgPrefix = 1
+	storeArgValue  = 2
+	storeArgDelta  = 2
+)
+
+// storeModule is the Lua "store" module. It gives the scripts of a mapping
+// access to their shared Store.
+type storeModule struct {
+	store *Store
+}
+
+func preloadStoreModule(luaState *lua.LState, store *Store) {
+	module := &storeModule{store: store}
+	luaState.PreloadModule("store", module.loader)
+}
+
+func (m *storeModule) loader(luaState *lua.LState) int {
+	luaState.Push(luaState.SetFuncs(luaState.NewTable(), map[string]lua.LGFunction{
+		"get":       m.get,
+		"set":       m.set,
+		"delete":    m.delete,
+		"increment": m.increment,
+		"keys":      m.keys,
+		"list":      m.list,
+	}))
+
+	return luaReturnOne
+}
+
+// get implements store.get(key). It returns nil for missing keys.
+func (m *storeModule) get(luaState *lua.LState) int {
+	value, ok := m.store.Get(luaState.CheckString(storeArgKey))
+	if !ok {
+		luaState.Push(lua.LNil)
+
+		return luaReturnOne
+	}
+
+	luaState.Push(decodeStoreValue(luaState, value))
+
+	return luaReturnOne
+}
+
+// set implements store.set(key, value). Setting nil deletes the key.
+func (m *storeModule) set(luaState *lua.LState) int {
+	key := luaState.CheckString(storeArgKey)
+	value := luaState.Get(storeArgValue)
+
+	if value == lua.LNil {
+		raiseStoreError(luaState, m.store.Delete(key))
+
+		return 0
+	}
+
+	encoded, err := luajson.Encode(value)
+	if err != nil {
+		luaState.RaiseError("store: %s", err.Error())
+
+		return 0
+	}
+
+	raiseStoreError(luaState, m.store.Set(key, encoded))
+
+	return 0
+}
+
+// delete implements store.delete(key).
+func (m *storeModule) delete(luaState *lua.LState) int {
+	raiseStoreError(luaState, m.store.Delete(luaState.CheckString(storeArgKey)))
+
+	return 0
+}
+
+// increment implements store.increment(key, [delta]) and returns the new value.
+func (m *storeModule) increment(luaState *lua.LState) int {
+	key := luaState.CheckString(storeArgKey)
+	delta := luaState.OptNumber(storeArgDelta, 1)
+
+	value, err := m.store.Increment(key, float64(delta))
+	raiseStoreError(luaState, err)
+
+	luaState.Push(lua.LNumber(value))
+
+	return luaReturnOne
+}
+
+// keys implements store.keys([prefix]) and returns the sorted keys.
+func (m *storeModule) keys(luaState *lua.LState) int {
+	result := luaState.NewTable()
+
+	for _, key := range m.store.Keys(luaState.OptString(storeArgPrefix, "")) {
+		result.Append(lua.LString(key))
+	}
+
+	luaState.Push(result)
+
+	return luaReturnOne
+}
+
+// list implements store.list([prefix]) and returns the values in key order.
+func (m *storeModule) list(luaState *lua.LState) int {
+	result := luaState.NewTable()
+
+	for _, value := range m.store.List(luaState.OptString(storeArgPrefix, "")) {
+		result.Append(decodeStoreValue(luaState, value))
+	}
+
+	luaState.Push(result)
+
+	return luaReturnOne
+}
+
+func decodeStoreValue(luaState *lua.LState, value json.RawMessage) lua.LValue {
+	decoded, err := luajson.Decode(luaState, value)
+	if err != nil {
+		luaState.RaiseError("store: %s", err.Error())
+
+		return lua.LNil
+	}
+
+	return decoded
+}
+
+func raiseStoreError(luaState *lua.LState, err error) {
+	if err != nil {
+		luaState.RaiseError("store: %s", err.Error())
+	}
+}
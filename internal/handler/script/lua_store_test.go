@@ -0,0 +1,177 @@
+package script_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runStoreScript(t *testing.T, store *script.Store, source string) (string, error) {
+	t.Helper()
+
+	handler := script.NewHandler(
+		script.WithOutput(mocks.NoopOutput()),
+		script.WithScript(&config.Script{Script: source}),
+		script.WithRuntime(&script.Runtime{Store: store}),
+	)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+	recorder := httptest.NewRecorder()
+
+	err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+	return testutils.ReadBody(t, recorder), err
+}
+
+func TestStoreModule(t *testing.T) {
+	t.Run("keeps values between script runs", func(t *testing.T) {
+		store := script.NewStore()
+
+		_, err := runStoreScript(t, store, `
+			local store = require("store")
+			store.set("todo:1", { title = "Buy milk", done = false })
+			store.set("todo:2", { title = "Walk dog", done = true })
+		`)
+		require.NoError(t, err)
+
+		body, err := runStoreScript(t, store, `
+			local store = require("store")
+			local todo = store.get("todo:1")
+			response:WriteString(todo.title .. ":" .. tostring(todo.done) .. ":" .. tostring(store.get("missing")))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Buy milk:false:nil", body)
+	})
+
+	t.Run("list and keys", func(t *testing.T) {
+		store := script.NewStore()
+		require.NoError(t, store.Set("todo:2", json.RawMessage(`"second"`)))
+		require.NoError(t, store.Set("todo:1", json.RawMessage(`"first"`)))
+		require.NoError(t, store.Set("user", json.RawMessage(`"John"`)))
+
+		body, err := runStoreScript(t, store, `
+			local store = require("store")
+			local values = store.list("todo:")
+			local keys = store.keys()
+			response:WriteString(table.concat(values, ",") .. "|" .. table.concat(keys, ","))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "first,second|todo:1,todo:2,user", body)
+	})
+
+	t.Run("increment", func(t *testing.T) {
+		store := script.NewStore()
+
+		body, err := runStoreScript(t, store, `
+			local store = require("store")
+			store.increment("visits")
+			response:WriteString(tostring(store.increment("visits", 10)))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "11", body)
+	})
+
+	t.Run("set nil and delete remove values", func(t *testing.T) {
+		store := script.NewStore()
+		require.NoError(t, store.Set("a", json.RawMessage(`1`)))
+		require.NoError(t, store.Set("b", json.RawMessage(`2`)))
+
+		_, err := runStoreScript(t, store, `
+			local store = require("store")
+			store.set("a", nil)
+			store.delete("b")
+		`)
+
+		require.NoError(t, err)
+		assert.Empty(t, store.Keys(""))
+	})
+
+	t.Run("stored tables are copies", func(t *testing.T) {
+		store := script.NewStore()
+
+		body, err := runStoreScript(t, store, `
+			local store = require("store")
+			local user = { name = "John" }
+			store.set("user", user)
+			user.name = "Jane"
+			response:WriteString(store.get("user").name)
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "John", body)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			source   string
+			expected string
+		}{
+			{
+				name:     "increment of not a number",
+				source:   `local store = require("store"); store.set("name", "John"); store.increment("name")`,
+				expected: script.ErrStoreNotNumber.Error(),
+			},
+			{
+				name:     "value that can not be encoded",
+				source:   `require("store").set("fn", function() end)`,
+				expected: "store:",
+			},
+		}
+
+		for _, testCase := range tests {
+			t.Run(testCase.name, func(t *testing.T) {
+				_, err := runStoreScript(t, script.NewStore(), testCase.source)
+
+				require.ErrorContains(t, err, testCase.expected)
+			})
+		}
+	})
+
+	t.Run("is not available without a store", func(t *testing.T) {
+		_, err := runStoreScript(t, nil, `require("store")`)
+
+		require.ErrorContains(t, err, "module store not found")
+	})
+}
+
+func TestMiddleware_StoreModule(t *testing.T) {
+	store := script.NewStore()
+
+	middleware := script.NewMiddleware(
+		script.WithMiddlewareOutput(mocks.NoopOutput()),
+		script.WithMiddlewareScript(&config.ScriptMiddleware{Script: `
+			local store = require("store")
+
+			function on_response(response)
+				response.json.visits = store.increment("visits")
+			end
+		`}),
+		script.WithMiddlewareRuntime(&script.Runtime{Store: store}),
+	)
+
+	for range 2 {
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		_, err := serveMiddleware(t, middleware, request, writeJSON(`{}`))
+		require.NoError(t, err)
+	}
+
+	recorder, err := serveMiddleware(t, middleware,
+		httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil), writeJSON(`{}`))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"visits":3}`, testutils.ReadBody(t, recorder))
+}
@@ -0,0 +1,168 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-http-utils/headers"
+	"github.com/spf13/afero"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/evg4b/uncors/internal/infra"
+)
+
+const (
+	onRequestHook  = "on_request"
+	onResponseHook = "on_response"
+)
+
+// Middleware runs a Lua script around the next handler. The on_request hook
+// can change the request before it is proxied, the on_response hook can patch
+// the response before it is sent to the client.
+type Middleware struct {
+	script  *config.ScriptMiddleware
+	output  contracts.Output
+	fs      afero.Fs
+	runtime *Runtime
+}
+
+func NewMiddleware(options ...MiddlewareOption) *Middleware {
+	middleware := helpers.ApplyOptions(&Middleware{}, options)
+
+	helpers.AssertIsDefined(middleware.script, "Script middleware: script is not configured")
+	helpers.AssertIsDefined(middleware.output, "Script middleware: output is not configured")
+
+	return middleware
+}
+
+func (m *Middleware) ServeHTTP(
+	writer contracts.ResponseWriter,
+	request *contracts.Request,
+	next contracts.Next,
+) error {
+	luaState := newLuaState(m.runtime, &m.script.Sandbox)
+	defer luaState.Close()
+
+	// The script and every hook have their own time and instruction limits,
+	// so the time spent waiting for the next handler is not counted.
+	err := sandboxed(request.Context(), luaState, &m.script.Sandbox, func() error {
+		return runScript(luaState, m.fs, m.script.Script, m.script.File)
+	})
+	if err != nil {
+		return m.fail(err)
+	}
+
+	onRequest := hook(luaState, onRequestHook)
+	onResponse := hook(luaState, onResponseHook)
+
+	if onRequest == nil && onResponse == nil {
+		return next(writer, request)
+	}
+
+	// Changes of the script must not leak into the request seen by the
+	// middlewares that wrap this one, e.g. the HAR collector.
+	request = request.Clone(request.Context())
+
+	body, err := readRequestBody(request)
+	if err != nil {
+		return m.fail(err)
+	}
+
+	reqTable := createRequestTable(luaState, request)
+	setRequestTarget(reqTable, request)
+
+	if onRequest != nil {
+		err = sandboxed(request.Context(), luaState, &m.script.Sandbox, func() error {
+			return callHook(luaState, onRequest, reqTable)
+		})
+		if err != nil {
+			return m.fail(fmt.Errorf("%s: %w", onRequestHook, err))
+		}
+
+		request = applyRequestTable(reqTable, request, body)
+	} else {
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if onResponse == nil {
+		return next(writer, request)
+	}
+
+	// Without Accept-Encoding the HTTP client negotiates gzip itself and
+	// decodes the body, so the script always sees a plain body.
+	request.Header.Del(headers.AcceptEncoding)
+
+	buffer := infra.NewBufferedWriter()
+
+	err = next(buffer, request)
+	if err != nil {
+		return err
+	}
+
+	respTable, jsonBody := createHookResponseTable(luaState, buffer)
+
+	err = sandboxed(request.Context(), luaState, &m.script.Sandbox, func() error {
+		return callHook(luaState, onResponse, respTable, reqTable)
+	})
+	if err != nil {
+		return m.fail(fmt.Errorf("%s: %w", onResponseHook, err))
+	}
+
+	err = applyResponseTable(respTable, buffer, jsonBody)
+	if err != nil {
+		return m.fail(fmt.Errorf("%s: %w", onResponseHook, err))
+	}
+
+	return buffer.Replay(writer)
+}
+
+func (m *Middleware) fail(err error) error {
+	m.output.Errorf("Script middleware error: %v", err)
+
+	return fmt.Errorf("script error: %w", err)
+}
+
+func readRequestBody(request *contracts.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+type MiddlewareOption = func(*Middleware)
+
+func WithMiddlewareScript(script *config.ScriptMiddleware) MiddlewareOption {
+	return func(m *Middleware) {
+		m.script = script
+	}
+}
+
+func WithMiddlewareOutput(output contracts.Output) MiddlewareOption {
+	return func(m *Middleware) {
+		m.output = output
+	}
+}
+
+func WithMiddlewareFileSystem(fs afero.Fs) MiddlewareOption {
+	return func(m *Middleware) {
+		m.fs = fs
+	}
+}
+
+func WithMiddlewareRuntime(runtime *Runtime) MiddlewareOption {
+	return func(m *Middleware) {
+		m.runtime = runtime
+	}
+}
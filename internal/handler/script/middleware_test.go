@@ -0,0 +1,312 @@
+package script_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/rewrite"
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScriptMiddleware(t *testing.T, source string) *script.Middleware {
+	t.Helper()
+
+	return script.NewMiddleware(
+		script.WithMiddlewareOutput(mocks.NoopOutput()),
+		script.WithMiddlewareScript(&config.ScriptMiddleware{Script: source}),
+		script.WithMiddlewareFileSystem(testutils.FsFromMap(t, map[string]string{})),
+	)
+}
+
+func serveMiddleware(
+	t *testing.T,
+	middleware *script.Middleware,
+	request *http.Request,
+	next contracts.Next,
+) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	err := middleware.ServeHTTP(server.NewResponseRecorder(recorder), request, next)
+
+	return recorder, err
+}
+
+func writeJSON(body string) contracts.Next {
+	return func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+		writer.Header().Set(headers.ContentType, applicationJSON)
+		writer.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(writer, body)
+
+		return err
+	}
+}
+
+func TestMiddleware_OnRequest(t *testing.T) {
+	t.Run("changes headers, query, path and body", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_request(request)
+				request.headers["X-Feature"] = "enabled"
+				request.headers["X-Remove"] = nil
+				request.query_params.debug = "true"
+				request.path = "/v2" .. request.path
+				request.body = string.upper(request.body)
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodPost,
+			"http://localhost/api/items?page=1", strings.NewReader("payload"))
+		request.Header.Set("X-Remove", "value")
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, req *contracts.Request) error {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, "enabled", req.Header.Get("X-Feature"))
+			assert.Empty(t, req.Header.Get("X-Remove"))
+			assert.Equal(t, "debug=true&page=1", req.URL.RawQuery)
+			assert.Equal(t, "/v2/api/items", req.URL.Path)
+			assert.Equal(t, "PAYLOAD", string(body))
+			assert.Equal(t, int64(len("PAYLOAD")), req.ContentLength)
+
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("raw query wins over query params", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_request(request)
+				request.query_params.ignored = "1"
+				request.query = "raw=1"
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/?a=1", nil)
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, req *contracts.Request) error {
+			assert.Equal(t, "raw=1", req.URL.RawQuery)
+
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("keeps untouched request as is", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_request(request) end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodPost,
+			"http://localhost/path?b=2&a=1", strings.NewReader("body"))
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, req *contracts.Request) error {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, "b=2&a=1", req.URL.RawQuery)
+			assert.Equal(t, "body", string(body))
+
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("changes target host", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_request(request)
+				request.target = "staging.example.com:8443"
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, req *contracts.Request) error {
+			host, err := rewrite.GetRewriteHost(req)
+			require.NoError(t, err)
+			assert.Equal(t, "staging.example.com:8443", host)
+
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("does not change the request of outer middlewares", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_request(request)
+				request.headers["X-Feature"] = "enabled"
+				request.path = "/changed"
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/original", nil)
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, _ *contracts.Request) error {
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, request.Header.Get("X-Feature"))
+		assert.Equal(t, "/original", request.URL.Path)
+	})
+}
+
+func TestMiddleware_OnResponse(t *testing.T) {
+	t.Run("patches status, headers and JSON body", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_response(response, request)
+				response.status = 201
+				response.headers["X-Path"] = request.path
+				response.json.flags.new_ui = true
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/config", nil)
+
+		recorder, err := serveMiddleware(t, middleware, request, writeJSON(`{"flags":{"new_ui":false}}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "/config", recorder.Header().Get("X-Path"))
+		assert.JSONEq(t, `{"flags":{"new_ui":true}}`, testutils.ReadBody(t, recorder))
+		assert.Equal(t, "25", recorder.Header().Get(headers.ContentLength))
+	})
+
+	t.Run("keeps untouched JSON body byte for byte", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_response(response) end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+		body := "{ \"b\": 1,\n  \"a\": [1, 2] }"
+
+		recorder, err := serveMiddleware(t, middleware, request, writeJSON(body))
+
+		require.NoError(t, err)
+		assert.Equal(t, body, testutils.ReadBody(t, recorder))
+	})
+
+	t.Run("replaces body", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_response(response)
+				response.body = "replaced"
+				response.headers["Content-Type"] = "text/plain"
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		recorder, err := serveMiddleware(t, middleware, request, writeJSON(`{"a":1}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, "replaced", testutils.ReadBody(t, recorder))
+		assert.Equal(t, "text/plain", recorder.Header().Get(headers.ContentType))
+	})
+
+	t.Run("shares state with on_request", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			local started
+			function on_request(request)
+				started = request.method
+			end
+			function on_response(response)
+				response.headers["X-Method"] = started
+			end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodDelete, "http://localhost/", nil)
+
+		recorder, err := serveMiddleware(t, middleware, request, writeJSON(`{}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, recorder.Header().Get("X-Method"))
+	})
+
+	t.Run("removes Accept-Encoding", func(t *testing.T) {
+		middleware := newScriptMiddleware(t, `
+			function on_response(response) end
+		`)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+		request.Header.Set(headers.AcceptEncoding, "br, gzip")
+
+		_, err := serveMiddleware(t, middleware, request, func(_ contracts.ResponseWriter, req *contracts.Request) error {
+			assert.Empty(t, req.Header.Get(headers.AcceptEncoding))
+
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestMiddleware_Passthrough(t *testing.T) {
+	middleware := newScriptMiddleware(t, `local unused = 1`)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+	request.Header.Set(headers.AcceptEncoding, "gzip")
+
+	next := func(writer contracts.ResponseWriter, req *contracts.Request) error {
+		assert.Same(t, request, req)
+		writer.WriteHeader(http.StatusAccepted)
+
+		return nil
+	}
+
+	recorder, err := serveMiddleware(t, middleware, request, next)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "syntax error", source: `function on_request(`},
+		{name: "on_request error", source: `function on_request(request) error("boom") end`},
+		{name: "on_response error", source: `function on_response(response) error("boom") end`},
+		{name: "invalid JSON value", source: `function on_response(response) response.json = function() end end`},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			middleware := newScriptMiddleware(t, testCase.source)
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+			recorder, err := serveMiddleware(t, middleware, request, writeJSON(`{}`))
+
+			require.ErrorContains(t, err, "script error")
+			assert.Empty(t, testutils.ReadBody(t, recorder))
+		})
+	}
+
+	t.Run("missing script file", func(t *testing.T) {
+		middleware := script.NewMiddleware(
+			script.WithMiddlewareOutput(mocks.NoopOutput()),
+			script.WithMiddlewareScript(&config.ScriptMiddleware{File: "/missing.lua"}),
+			script.WithMiddlewareFileSystem(testutils.FsFromMap(t, map[string]string{})),
+		)
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		_, err := serveMiddleware(t, middleware, request, writeJSON(`{}`))
+
+		require.ErrorIs(t, err, script.ErrScriptFileNotFound)
+	})
+}
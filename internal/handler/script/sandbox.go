@@ -0,0 +1,172 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/evg4b/uncors/internal/config"
+)
+
+// closedChannel is returned by instructionLimit once the limit is reached.
+var closedChannel = func() chan struct{} {
+	channel := make(chan struct{})
+	close(channel)
+
+	return channel
+}()
+
+// luaOptions returns the options of a Lua state with the call stack and value
+// stack limits of the sandbox. gopher-lua keeps the values of the stack in its
+// registry, so MaxStackSize limits the registry; memory used by tables and
+// strings is not limited. The standard libraries are opened by openLibraries.
+func luaOptions(sandbox *config.ScriptSandbox) lua.Options {
+	options := lua.Options{
+		SkipOpenLibs:  true,
+		CallStackSize: lua.CallStackSize,
+		RegistrySize:  lua.RegistrySize,
+	}
+
+	if sandbox.MaxCallDepth > 0 {
+		options.CallStackSize = sandbox.MaxCallDepth
+	}
+
+	if sandbox.MaxStackSize > 0 {
+		options.RegistrySize = min(sandbox.MaxStackSize, lua.RegistrySize)
+		options.RegistryMaxSize = sandbox.MaxStackSize
+	}
+
+	return options
+}
+
+type luaLibrary struct {
+	name string
+	open lua.LGFunction
+}
+
+// unsafeLibraries are opened only when they are listed in allow-libs.
+var unsafeLibraries = []luaLibrary{
+	{lua.DebugLibName, lua.OpenDebug},
+	{lua.IoLibName, lua.OpenIo},
+}
+
+// openLibraries opens the libraries scripts can use. The io and debug
+// libraries are opened only when they are listed in allow-libs. The dofile
+// and loadfile functions, which read files outside of the configured file
+// system, are never available. The os library only has the functions listed
+// in allow-os in addition to the safe ones.
+func openLibraries(luaState *lua.LState, sandbox *config.ScriptSandbox) {
+	libraries := []luaLibrary{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+		{lua.OsLibName, lua.OpenOs},
+	}
+
+	for _, library := range unsafeLibraries {
+		if slices.Contains(sandbox.AllowLibs, library.name) {
+			libraries = append(libraries, library)
+		}
+	}
+
+	for _, library := range libraries {
+		luaState.Push(luaState.NewFunction(library.open))
+		luaState.Push(lua.LString(library.name))
+		luaState.Call(1, 0)
+	}
+
+	luaState.SetGlobal("dofile", lua.LNil)
+	luaState.SetGlobal("loadfile", lua.LNil)
+
+	if osTable, ok := luaState.GetGlobal(lua.OsLibName).(*lua.LTable); ok {
+		for _, name := range config.UnsafeOSFunctions {
+			if !slices.Contains(sandbox.AllowOS, name) {
+				osTable.RawSetString(name, lua.LNil)
+			}
+		}
+	}
+}
+
+// instructionLimit is the context of a Lua state with a limited number of
+// instructions. gopher-lua checks Done before every instruction it executes,
+// so the number of calls is the number of executed instructions.
+type instructionLimit struct {
+	context.Context //nolint:containedctx // wraps the context checked by the Lua VM
+
+	remaining atomic.Int64
+}
+
+func withInstructionLimit(ctx context.Context, limit int) context.Context {
+	if limit <= 0 {
+		return ctx
+	}
+
+	limited := &instructionLimit{Context: ctx}
+	limited.remaining.Store(int64(limit))
+
+	return limited
+}
+
+func (l *instructionLimit) Done() <-chan struct{} {
+	if l.remaining.Add(-1) < 0 {
+		return closedChannel
+	}
+
+	return l.Context.Done()
+}
+
+func (l *instructionLimit) Err() error {
+	if l.remaining.Load() < 0 {
+		return ErrScriptInstructionLimit
+	}
+
+	return l.Context.Err()
+}
+
+// sandboxed runs a chunk or a hook of the script within its timeout and
+// instruction limit and explains the errors caused by them.
+func sandboxed(ctx context.Context, luaState *lua.LState, sandbox *config.ScriptSandbox, run func() error) error {
+	timeout := sandbox.ExecutionTimeout()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	limited := withInstructionLimit(ctx, sandbox.MaxInstructions)
+
+	luaState.SetContext(limited)
+	defer luaState.RemoveContext()
+
+	err := run()
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(limited.Err(), ErrScriptInstructionLimit):
+		return fmt.Errorf("%w of %d instructions", ErrScriptInstructionLimit, sandbox.MaxInstructions)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w after %s", ErrScriptTimeout, timeout)
+	default:
+		return err
+	}
+}
+
+// executionContext returns the context of the running chunk or hook without
+// the instruction limit, so code outside of the Lua VM does not use it up.
+func executionContext(luaState *lua.LState) context.Context {
+	switch ctx := luaState.Context().(type) {
+	case nil:
+		return context.Background()
+	case *instructionLimit:
+		return ctx.Context
+	default:
+		return ctx
+	}
+}
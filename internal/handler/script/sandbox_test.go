@@ -0,0 +1,210 @@
+package script_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/config"
+	"github.com/evg4b/uncors/internal/contracts"
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/mocks"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSandboxedScript(t *testing.T, sandbox config.ScriptSandbox, source string) (string, error) {
+	t.Helper()
+
+	handler := script.NewHandler(
+		script.WithOutput(mocks.NoopOutput()),
+		script.WithScript(&config.Script{Script: source, Sandbox: sandbox}),
+		script.WithFileSystem(testutils.FsFromMap(t, map[string]string{})),
+	)
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+	recorder := httptest.NewRecorder()
+
+	err := handler.ServeHTTP(server.NewResponseRecorder(recorder), request)
+
+	return testutils.ReadBody(t, recorder), err
+}
+
+func TestSandbox_Limits(t *testing.T) {
+	t.Run("stops script after timeout", func(t *testing.T) {
+		_, err := runSandboxedScript(t, config.ScriptSandbox{Timeout: 50 * time.Millisecond}, `
+			while true do end
+		`)
+
+		require.ErrorIs(t, err, script.ErrScriptTimeout)
+		require.ErrorContains(t, err, "script timed out after 50ms")
+	})
+
+	t.Run("stops script after max instructions", func(t *testing.T) {
+		_, err := runSandboxedScript(t, config.ScriptSandbox{MaxInstructions: 1000}, `
+			while true do end
+		`)
+
+		require.ErrorIs(t, err, script.ErrScriptInstructionLimit)
+		require.ErrorContains(t, err, "script exceeded the limit of 1000 instructions")
+	})
+
+	t.Run("runs script within max instructions", func(t *testing.T) {
+		body, err := runSandboxedScript(t, config.ScriptSandbox{MaxInstructions: 1000}, `
+			local sum = 0
+			for i = 1, 10 do sum = sum + i end
+			response:WriteString(tostring(sum))
+		`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "55", body)
+	})
+
+	t.Run("limits call depth", func(t *testing.T) {
+		source := `
+			local function depth(n) if n == 0 then return 0 end return 1 + depth(n - 1) end
+			response:WriteString(tostring(depth(50)))
+		`
+
+		body, err := runSandboxedScript(t, config.ScriptSandbox{}, source)
+		require.NoError(t, err)
+		assert.Equal(t, "50", body)
+
+		_, err = runSandboxedScript(t, config.ScriptSandbox{MaxCallDepth: 20}, source)
+		require.ErrorContains(t, err, "stack overflow")
+	})
+
+	t.Run("limits stack size", func(t *testing.T) {
+		source := `
+			local values = {}
+			for i = 1, 2000 do values[i] = i end
+			response:WriteString(tostring(select("#", unpack(values))))
+		`
+
+		body, err := runSandboxedScript(t, config.ScriptSandbox{}, source)
+		require.NoError(t, err)
+		assert.Equal(t, "2000", body)
+
+		_, err = runSandboxedScript(t, config.ScriptSandbox{MaxStackSize: 1000}, source)
+		require.ErrorContains(t, err, "registry overflow")
+	})
+}
+
+func TestSandbox_Libraries(t *testing.T) {
+	tests := []struct {
+		name     string
+		sandbox  config.ScriptSandbox
+		source   string
+		expected string
+	}{
+		{
+			name:     "io and debug libraries are not available",
+			source:   `response:WriteString(type(io) .. " " .. type(debug))`,
+			expected: "nil nil",
+		},
+		{
+			name:     "allow-libs enables io and debug libraries",
+			sandbox:  config.ScriptSandbox{AllowLibs: []string{"io", "debug"}},
+			source:   `response:WriteString(type(io.open) .. " " .. type(debug.traceback))`,
+			expected: "function function",
+		},
+		{
+			name:     "allow-libs enables only listed libraries",
+			sandbox:  config.ScriptSandbox{AllowLibs: []string{"debug"}},
+			source:   `response:WriteString(type(io) .. " " .. type(require("debug").traceback))`,
+			expected: "nil function",
+		},
+		{
+			name:     "files cannot be loaded outside of require",
+			source:   `response:WriteString(type(dofile) .. " " .. type(loadfile))`,
+			expected: "nil nil",
+		},
+		{
+			name:     "safe os functions are available",
+			source:   `response:WriteString(type(os.time) .. " " .. type(os.date) .. " " .. type(os.getenv))`,
+			expected: "function function function",
+		},
+		{
+			name: "unsafe os functions are removed by default",
+			source: `response:WriteString(
+				type(os.execute) .. " " .. type(os.exit) .. " " .. type(os.remove) .. " " .. type(os.rename)
+			)`,
+			expected: "nil nil nil nil",
+		},
+		{
+			name:     "allow-os enables unsafe os functions",
+			sandbox:  config.ScriptSandbox{AllowOS: []string{"execute", "remove"}},
+			source:   `response:WriteString(type(os.execute) .. " " .. type(os.remove) .. " " .. type(os.exit))`,
+			expected: "function function nil",
+		},
+		{
+			name: "standard libraries can be required",
+			source: `response:WriteString(
+				tostring(require("math") == math) .. " " .. type(require("os").execute)
+			)`,
+			expected: "true nil",
+		},
+		{
+			name:     "json module is available",
+			source:   `response:WriteString(require("json").encode({ ok = true }))`,
+			expected: `{"ok":true}`,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			body, err := runSandboxedScript(t, testCase.sandbox, testCase.source)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, body)
+		})
+	}
+}
+
+func TestSandbox_Middleware(t *testing.T) {
+	sandbox := config.ScriptSandbox{Timeout: 50 * time.Millisecond}
+
+	t.Run("does not count the time of the next handler", func(t *testing.T) {
+		middleware := script.NewMiddleware(
+			script.WithMiddlewareOutput(mocks.NoopOutput()),
+			script.WithMiddlewareScript(&config.ScriptMiddleware{
+				Script:  `function on_response(response) response.status = 201 end`,
+				Sandbox: sandbox,
+			}),
+		)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		recorder, err := serveMiddleware(t, middleware, request,
+			func(writer contracts.ResponseWriter, _ *contracts.Request) error {
+				time.Sleep(100 * time.Millisecond)
+				writer.WriteHeader(http.StatusOK)
+
+				return nil
+			},
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("stops hook after timeout", func(t *testing.T) {
+		middleware := script.NewMiddleware(
+			script.WithMiddlewareOutput(mocks.NoopOutput()),
+			script.WithMiddlewareScript(&config.ScriptMiddleware{
+				Script:  `function on_request(request) while true do end end`,
+				Sandbox: sandbox,
+			}),
+		)
+
+		request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/", nil)
+
+		_, err := serveMiddleware(t, middleware, request, writeJSON(`{}`))
+
+		require.ErrorIs(t, err, script.ErrScriptTimeout)
+		require.ErrorContains(t, err, "on_request")
+	})
+}
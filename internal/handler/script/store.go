@@ -0,0 +1,265 @@
+package script
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	storeDirMode   = 0o755
+	storeFileMode  = 0o644
+	storeTmpSuffix = ".tmp"
+)
+
+// Store is the key-value store shared by the scripts of a mapping. Values are
+// kept JSON encoded, so scripts never share Lua tables between states. When a
+// file is configured, the store is loaded from it and saved after every change.
+type Store struct {
+	mutex  sync.RWMutex
+	values map[string]json.RawMessage
+	fs     afero.Fs
+	file   string
+}
+
+func NewStore() *Store {
+	return &Store{values: map[string]json.RawMessage{}}
+}
+
+// LoadStore creates a store persisted to the file. A missing file means an
+// empty store.
+func LoadStore(fs afero.Fs, file string) (*Store, error) {
+	store := &Store{values: map[string]json.RawMessage{}, fs: fs, file: file}
+
+	data, err := afero.ReadFile(fs, file)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script store %s: %w", file, err)
+	}
+
+	err = json.Unmarshal(data, &store.values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script store %s: %w", file, err)
+	}
+
+	return store, nil
+}
+
+// Get returns the encoded value of the key.
+func (s *Store) Get(key string) (json.RawMessage, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	value, ok := s.values[key]
+
+	return value, ok
+}
+
+func (s *Store) Set(key string, value json.RawMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = slices.Clone(value)
+
+	return s.save()
+}
+
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.values[key]; !ok {
+		return nil
+	}
+
+	delete(s.values, key)
+
+	return s.save()
+}
+
+// Increment adds delta to the number stored under the key and returns the
+// result. A missing key counts as zero.
+func (s *Store) Increment(key string, delta float64) (float64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var current float64
+
+	if value, ok := s.values[key]; ok {
+		err := json.Unmarshal(value, &current)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrStoreNotNumber, key)
+		}
+	}
+
+	current += delta
+
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return 0, err
+	}
+
+	s.values[key] = encoded
+
+	return current, s.save()
+}
+
+// Keys returns the sorted keys that start with the prefix.
+func (s *Store) Keys(prefix string) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.keys(prefix)
+}
+
+// List returns the values of the keys that start with the prefix in key order.
+func (s *Store) List(prefix string) []json.RawMessage {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := s.keys(prefix)
+	values := make([]json.RawMessage, 0, len(keys))
+
+	for _, key := range keys {
+		values = append(values, s.values[key])
+	}
+
+	return values
+}
+
+// Clear removes all values, including the persisted ones.
+func (s *Store) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clear(s.values)
+
+	return s.save()
+}
+
+func (s *Store) keys(prefix string) []string {
+	keys := make([]string, 0, len(s.values))
+
+	for key := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func (s *Store) save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = s.fs.MkdirAll(filepath.Dir(s.file), storeDirMode)
+	if err != nil {
+		return fmt.Errorf("failed to save script store %s: %w", s.file, err)
+	}
+
+	err = s.writeFile(data)
+	if err != nil {
+		return fmt.Errorf("failed to save script store %s: %w", s.file, err)
+	}
+
+	return nil
+}
+
+// writeFile replaces the file with a fully written temporary one, so a crash
+// while saving does not leave a truncated store behind.
+func (s *Store) writeFile(data []byte) error {
+	tmp := s.file + storeTmpSuffix
+
+	err := afero.WriteFile(s.fs, tmp, data, storeFileMode)
+	if err != nil {
+		return err
+	}
+
+	err = s.fs.Rename(tmp, s.file)
+	if err != nil {
+		_ = s.fs.Remove(tmp)
+
+		return err
+	}
+
+	return nil
+}
+
+// Stores keeps the store of every mapping for the lifetime of the running
+// configuration, so the data survives until the configuration is reloaded.
+type Stores struct {
+	mutex  sync.Mutex
+	fs     afero.Fs
+	stores map[string]*Store
+}
+
+func NewStores(fs afero.Fs) *Stores {
+	return &Stores{fs: fs, stores: map[string]*Store{}}
+}
+
+// For returns the store of the mapping, creating it on the first call.
+func (s *Stores) For(mapping, file string) (*Store, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if store, ok := s.stores[mapping]; ok && store.file == file {
+		return store, nil
+	}
+
+	store := NewStore()
+
+	if file != "" {
+		var err error
+
+		store, err = LoadStore(s.fs, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.stores[mapping] = store
+
+	return store, nil
+}
+
+// Reset forgets all stores. In-memory data is lost, persisted stores are
+// loaded from their files again.
+func (s *Stores) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clear(s.stores)
+}
+
+// Clear removes the data of all stores, including the persisted ones.
+func (s *Stores) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	errs := make([]error, 0, len(s.stores))
+
+	for _, store := range s.stores {
+		errs = append(errs, store.Clear())
+	}
+
+	return errors.Join(errs...)
+}
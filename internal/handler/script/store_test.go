@@ -0,0 +1,183 @@
+package script_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/handler/script"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeFile = "/data/store.json"
+
+func TestStore(t *testing.T) {
+	t.Run("set, get and delete", func(t *testing.T) {
+		store := script.NewStore()
+
+		require.NoError(t, store.Set("user", json.RawMessage(`{"name":"John"}`)))
+
+		value, ok := store.Get("user")
+		require.True(t, ok)
+		assert.JSONEq(t, `{"name":"John"}`, string(value))
+
+		require.NoError(t, store.Delete("user"))
+
+		_, ok = store.Get("user")
+		assert.False(t, ok)
+	})
+
+	t.Run("increment", func(t *testing.T) {
+		store := script.NewStore()
+
+		value, err := store.Increment("counter", 1)
+		require.NoError(t, err)
+		assert.InDelta(t, 1, value, 0)
+
+		value, err = store.Increment("counter", 2.5)
+		require.NoError(t, err)
+		assert.InDelta(t, 3.5, value, 0)
+	})
+
+	t.Run("increment of not a number", func(t *testing.T) {
+		store := script.NewStore()
+		require.NoError(t, store.Set("name", json.RawMessage(`"John"`)))
+
+		_, err := store.Increment("name", 1)
+
+		require.ErrorIs(t, err, script.ErrStoreNotNumber)
+	})
+
+	t.Run("keys and list by prefix in key order", func(t *testing.T) {
+		store := script.NewStore()
+		require.NoError(t, store.Set("todo:2", json.RawMessage(`"second"`)))
+		require.NoError(t, store.Set("todo:1", json.RawMessage(`"first"`)))
+		require.NoError(t, store.Set("user", json.RawMessage(`"John"`)))
+
+		assert.Equal(t, []string{"todo:1", "todo:2"}, store.Keys("todo:"))
+		assert.Equal(t, []json.RawMessage{json.RawMessage(`"first"`), json.RawMessage(`"second"`)}, store.List("todo:"))
+		assert.Len(t, store.Keys(""), 3)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		store := script.NewStore()
+		require.NoError(t, store.Set("user", json.RawMessage(`"John"`)))
+
+		require.NoError(t, store.Clear())
+
+		assert.Empty(t, store.Keys(""))
+	})
+}
+
+func TestLoadStore(t *testing.T) {
+	t.Run("persists every change", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		store, err := script.LoadStore(fs, storeFile)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Set("user", json.RawMessage(`"John"`)))
+		_, err = store.Increment("counter", 1)
+		require.NoError(t, err)
+
+		data, err := afero.ReadFile(fs, storeFile)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"counter":1,"user":"John"}`, string(data))
+
+		exists, err := afero.Exists(fs, storeFile+".tmp")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		require.NoError(t, store.Clear())
+
+		data, err = afero.ReadFile(fs, storeFile)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(data))
+	})
+
+	t.Run("loads existing file", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{storeFile: `{"user":"John"}`})
+
+		store, err := script.LoadStore(fs, storeFile)
+		require.NoError(t, err)
+
+		value, ok := store.Get("user")
+		require.True(t, ok)
+		assert.JSONEq(t, `"John"`, string(value))
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		fs := testutils.FsFromMap(t, map[string]string{storeFile: `[`})
+
+		_, err := script.LoadStore(fs, storeFile)
+
+		require.ErrorContains(t, err, "failed to parse script store /data/store.json")
+	})
+}
+
+func TestStores(t *testing.T) {
+	t.Run("returns the same store for a mapping", func(t *testing.T) {
+		stores := script.NewStores(afero.NewMemMapFs())
+
+		first, err := stores.For("http://localhost:3000", "")
+		require.NoError(t, err)
+
+		second, err := stores.For("http://localhost:3000", "")
+		require.NoError(t, err)
+
+		other, err := stores.For("http://localhost:4000", "")
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.NotSame(t, first, other)
+	})
+
+	t.Run("reset drops in-memory data and reloads files", func(t *testing.T) {
+		stores := script.NewStores(afero.NewMemMapFs())
+
+		memory, err := stores.For("memory", "")
+		require.NoError(t, err)
+		require.NoError(t, memory.Set("user", json.RawMessage(`"John"`)))
+
+		persisted, err := stores.For("persisted", storeFile)
+		require.NoError(t, err)
+		require.NoError(t, persisted.Set("user", json.RawMessage(`"John"`)))
+
+		stores.Reset()
+
+		memory, err = stores.For("memory", "")
+		require.NoError(t, err)
+		assert.Empty(t, memory.Keys(""))
+
+		persisted, err = stores.For("persisted", storeFile)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user"}, persisted.Keys(""))
+	})
+
+	t.Run("clear removes data of all stores", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		stores := script.NewStores(fs)
+
+		persisted, err := stores.For("persisted", storeFile)
+		require.NoError(t, err)
+		require.NoError(t, persisted.Set("user", json.RawMessage(`"John"`)))
+
+		require.NoError(t, stores.Clear())
+
+		assert.Empty(t, persisted.Keys(""))
+
+		data, err := afero.ReadFile(fs, storeFile)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(data))
+	})
+
+	t.Run("returns load errors", func(t *testing.T) {
+		stores := script.NewStores(testutils.FsFromMap(t, map[string]string{storeFile: `[`}))
+
+		_, err := stores.For("persisted", storeFile)
+
+		require.Error(t, err)
+	})
+}
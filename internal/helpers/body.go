@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// MaxMatchedBodySize is the largest request body read to match a request by
+// its body.
+const MaxMatchedBodySize = 1 << 20
+
+// PeekBody reads the request body and puts it back so handlers can read it
+// again. Bodies larger than MaxMatchedBodySize are not buffered, PeekBody
+// reports false for them and keeps them readable from the start.
+func PeekBody(request *http.Request) ([]byte, bool, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, true, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, MaxMatchedBodySize+1))
+	request.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(body), request.Body),
+		Closer: request.Body,
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(body) > MaxMatchedBodySize {
+		return nil, false, nil
+	}
+
+	return body, true, nil
+}
+
+// readCloser puts the read part of a body in front of its rest.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
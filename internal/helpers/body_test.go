@@ -0,0 +1,61 @@
+package helpers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeekBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "returns empty body",
+			body:     "",
+			expected: "",
+			ok:       true,
+		},
+		{
+			name:     "returns body up to the limit",
+			body:     strings.Repeat("x", helpers.MaxMatchedBodySize),
+			expected: strings.Repeat("x", helpers.MaxMatchedBodySize),
+			ok:       true,
+		},
+		{
+			name:     "skips body above the limit",
+			body:     strings.Repeat("x", helpers.MaxMatchedBodySize+1),
+			expected: "",
+			ok:       false,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var body io.Reader = http.NoBody
+			if testCase.body != "" {
+				body = strings.NewReader(testCase.body)
+			}
+
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", body)
+
+			peeked, ok, err := helpers.PeekBody(request)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.expected, string(peeked))
+
+			rest, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.body, string(rest))
+		})
+	}
+}
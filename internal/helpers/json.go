@@ -0,0 +1,32 @@
+package helpers
+
+import "strconv"
+
+// LookupJSONField follows a path of object keys and array indexes through a
+// decoded JSON document. It reports false when a segment is missing.
+func LookupJSONField(document any, path []string) (any, bool) {
+	current := document
+
+	for _, key := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+
+			current = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
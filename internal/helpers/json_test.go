@@ -0,0 +1,44 @@
+package helpers_test
+
+import (
+	"testing"
+
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupJSONField(t *testing.T) {
+	document := map[string]any{
+		"user": map[string]any{"name": "John"},
+		"devices": []any{
+			map[string]any{"type": "ios"},
+		},
+		"empty": nil,
+	}
+
+	tests := []struct {
+		name     string
+		path     []string
+		expected any
+		found    bool
+	}{
+		{name: "object field", path: []string{"user", "name"}, expected: "John", found: true},
+		{name: "array index", path: []string{"devices", "0", "type"}, expected: "ios", found: true},
+		{name: "null field", path: []string{"empty"}, expected: nil, found: true},
+		{name: "empty path", path: []string{}, expected: document, found: true},
+		{name: "missing field", path: []string{"user", "email"}},
+		{name: "index out of range", path: []string{"devices", "1"}},
+		{name: "negative index", path: []string{"devices", "-1"}},
+		{name: "not an index", path: []string{"devices", "first"}},
+		{name: "field of a scalar", path: []string{"user", "name", "first"}},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, found := helpers.LookupJSONField(document, testCase.path)
+
+			assert.Equal(t, testCase.found, found)
+			assert.Equal(t, testCase.expected, value)
+		})
+	}
+}
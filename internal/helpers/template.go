@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const randomStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var ErrNegativeLength = errors.New("length must not be negative")
+
+var (
+	fakeFirstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+		"William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+		"Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor",
+	}
+	fakeWords = []string{
+		"alpha", "bravo", "cloud", "delta", "echo", "forest", "galaxy", "harbor",
+		"island", "jungle", "kernel", "lemon", "meadow", "nebula", "orbit", "pixel",
+	}
+	fakeDomains = []string{"example.com", "example.org", "example.net"}
+)
+
+// TemplateFuncs returns helpers available in response templates. The now
+// function is the time source for time related helpers.
+func TemplateFuncs(now func() time.Time) template.FuncMap {
+	return template.FuncMap{
+		"uuid":          templateUUID,
+		"randomInt":     templateRandomInt,
+		"randomFloat":   templateRandomFloat,
+		"randomString":  templateRandomString,
+		"randomItem":    templateRandomItem,
+		"now":           now,
+		"timestamp":     func() int64 { return now().Unix() },
+		"timestampMs":   func() int64 { return now().UnixMilli() },
+		"fakeFirstName": func() string { return pick(fakeFirstNames) },
+		"fakeLastName":  func() string { return pick(fakeLastNames) },
+		"fakeName":      templateFakeName,
+		"fakeEmail":     templateFakeEmail,
+		"fakeWord":      func() string { return pick(fakeWords) },
+		"toJSON":        templateToJSON,
+		"default":       templateDefault,
+	}
+}
+
+// ParseTemplate parses text as a response template with all helpers available.
+func ParseTemplate(name, text string, now func() time.Time) (*template.Template, error) {
+	return template.New(name).
+		Option("missingkey=zero").
+		Funcs(TemplateFuncs(now)).
+		Parse(text)
+}
+
+func templateUUID() string {
+	var uuid [16]byte
+
+	_, _ = rand.Read(uuid[:])
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+func templateRandomInt(minValue, maxValue int) int {
+	if maxValue <= minValue {
+		return minValue
+	}
+
+	return minValue + mrand.IntN(maxValue-minValue+1)
+}
+
+func templateRandomFloat(minValue, maxValue float64) float64 {
+	if maxValue <= minValue {
+		return minValue
+	}
+
+	return minValue + mrand.Float64()*(maxValue-minValue)
+}
+
+func templateRandomString(length int) (string, error) {
+	if length < 0 {
+		return "", fmt.Errorf("%w: %d", ErrNegativeLength, length)
+	}
+
+	var builder strings.Builder
+
+	builder.Grow(length)
+
+	for range length {
+		builder.WriteByte(randomStringAlphabet[mrand.IntN(len(randomStringAlphabet))])
+	}
+
+	return builder.String(), nil
+}
+
+func templateRandomItem(items ...any) any {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return items[mrand.IntN(len(items))]
+}
+
+func templateFakeName() string {
+	return pick(fakeFirstNames) + " " + pick(fakeLastNames)
+}
+
+func templateFakeEmail() string {
+	return strings.ToLower(pick(fakeFirstNames)+"."+pick(fakeLastNames)) + "@" + pick(fakeDomains)
+}
+
+func templateToJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// templateDefault returns value unless it is empty, in which case fallback
+// is used. It is meant to be piped: {{ .Query.Get "page" | default "1" }}.
+func templateDefault(fallback, value any) any {
+	if value == nil {
+		return fallback
+	}
+
+	if text, ok := value.(string); ok && text == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func pick(items []string) string {
+	return items[mrand.IntN(len(items))]
+}
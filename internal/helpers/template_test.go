@@ -0,0 +1,103 @@
+package helpers_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evg4b/uncors/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate(t *testing.T) {
+	now := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	render := func(t *testing.T, text string, data any) string {
+		t.Helper()
+
+		tmpl, err := helpers.ParseTemplate("test", text, func() time.Time { return now })
+		require.NoError(t, err)
+
+		var builder strings.Builder
+		require.NoError(t, tmpl.Execute(&builder, data))
+
+		return builder.String()
+	}
+
+	t.Run("uuid", func(t *testing.T) {
+		first := render(t, "{{ uuid }}", nil)
+		second := render(t, "{{ uuid }}", nil)
+
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, first)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("randomInt stays in range", func(t *testing.T) {
+		for range 100 {
+			value, err := strconv.Atoi(render(t, "{{ randomInt 5 7 }}", nil))
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, value, 5)
+			assert.LessOrEqual(t, value, 7)
+		}
+	})
+
+	t.Run("randomFloat stays in range", func(t *testing.T) {
+		value, err := strconv.ParseFloat(render(t, "{{ randomFloat 1.5 2.5 }}", nil), 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, value, 1.5)
+		assert.Less(t, value, 2.5)
+	})
+
+	t.Run("randomString has requested length", func(t *testing.T) {
+		assert.Regexp(t, `^[a-zA-Z0-9]{12}$`, render(t, "{{ randomString 12 }}", nil))
+	})
+
+	t.Run("randomString rejects negative length", func(t *testing.T) {
+		tmpl, err := helpers.ParseTemplate("test", "{{ randomString -1 }}", time.Now)
+		require.NoError(t, err)
+
+		err = tmpl.Execute(&strings.Builder{}, nil)
+
+		require.ErrorIs(t, err, helpers.ErrNegativeLength)
+	})
+
+	t.Run("randomItem picks one of arguments", func(t *testing.T) {
+		assert.Contains(t, []string{"a", "b", "c"}, render(t, `{{ randomItem "a" "b" "c" }}`, nil))
+	})
+
+	t.Run("time helpers", func(t *testing.T) {
+		assert.Equal(t, "2026-01-02T03:04:05Z", render(t, `{{ now.Format "2006-01-02T15:04:05Z07:00" }}`, nil))
+		assert.Equal(t, "1767323045", render(t, "{{ timestamp }}", nil))
+		assert.Equal(t, "1767323045000", render(t, "{{ timestampMs }}", nil))
+	})
+
+	t.Run("fake data", func(t *testing.T) {
+		assert.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, render(t, "{{ fakeName }}", nil))
+		assert.Regexp(t, `^[a-z]+\.[a-z]+@example\.(com|org|net)$`, render(t, "{{ fakeEmail }}", nil))
+		assert.Regexp(t, `^[A-Z][a-z]+$`, render(t, "{{ fakeFirstName }}", nil))
+		assert.Regexp(t, `^[A-Z][a-z]+$`, render(t, "{{ fakeLastName }}", nil))
+		assert.Regexp(t, `^[a-z]+$`, render(t, "{{ fakeWord }}", nil))
+	})
+
+	t.Run("toJSON", func(t *testing.T) {
+		data := map[string]any{"items": []any{1, "two"}}
+
+		assert.Equal(t, `{"items":[1,"two"]}`, render(t, "{{ toJSON . }}", data))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		data := map[string]any{"empty": "", "value": "set"}
+
+		assert.Equal(t, "fallback", render(t, `{{ .empty | default "fallback" }}`, data))
+		assert.Equal(t, "fallback", render(t, `{{ .missing | default "fallback" }}`, data))
+		assert.Equal(t, "set", render(t, `{{ .value | default "fallback" }}`, data))
+	})
+
+	t.Run("rejects unknown functions", func(t *testing.T) {
+		_, err := helpers.ParseTemplate("test", "{{ unknown }}", time.Now)
+
+		require.ErrorContains(t, err, `function "unknown" not defined`)
+	})
+}
@@ -0,0 +1,101 @@
+package infra
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-http-utils/headers"
+
+	"github.com/evg4b/uncors/internal/contracts"
+)
+
+// BufferedWriter collects a response in memory instead of sending it to the
+// client, so it can be inspected or changed before it is replayed.
+type BufferedWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	startedAt  time.Time
+}
+
+func NewBufferedWriter() *BufferedWriter {
+	return &BufferedWriter{
+		header:    http.Header{},
+		startedAt: time.Now(),
+	}
+}
+
+func (w *BufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *BufferedWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *BufferedWriter) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	return w.body.Write(data)
+}
+
+func (w *BufferedWriter) Flush() {}
+
+func (w *BufferedWriter) StatusCode() int {
+	return w.statusCode
+}
+
+func (w *BufferedWriter) EnableBodyCapture() {}
+
+func (w *BufferedWriter) Captured() contracts.ResponseCapture {
+	statusCode := w.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	return contracts.ResponseCapture{
+		StatusCode: statusCode,
+		Header:     w.header,
+		Body:       w.body.Bytes(),
+		Duration:   time.Since(w.startedAt),
+	}
+}
+
+// SetStatusCode replaces the status code of the buffered response.
+func (w *BufferedWriter) SetStatusCode(statusCode int) {
+	w.statusCode = statusCode
+}
+
+// SetHeader replaces the headers of the buffered response.
+func (w *BufferedWriter) SetHeader(header http.Header) {
+	w.header = header
+}
+
+// SetBody replaces the body of the buffered response and its Content-Length.
+func (w *BufferedWriter) SetBody(body []byte) {
+	w.body.Reset()
+	w.body.Write(body)
+	w.header.Set(headers.ContentLength, strconv.Itoa(len(body)))
+}
+
+// Replay sends the buffered response to the client.
+func (w *BufferedWriter) Replay(writer contracts.ResponseWriter) error {
+	capture := w.Captured()
+	header := writer.Header()
+
+	for name, values := range capture.Header {
+		header[name] = values
+	}
+
+	writer.WriteHeader(capture.StatusCode)
+
+	_, err := writer.Write(capture.Body)
+
+	return err
+}
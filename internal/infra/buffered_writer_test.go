@@ -0,0 +1,81 @@
+package infra_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evg4b/uncors/internal/infra"
+	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/testing/testutils"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	t.Run("captures the response", func(t *testing.T) {
+		writer := infra.NewBufferedWriter()
+		writer.Header().Set(headers.ContentType, "text/plain")
+		writer.WriteHeader(http.StatusCreated)
+		writer.WriteHeader(http.StatusTeapot)
+
+		_, err := writer.Write([]byte("created"))
+		require.NoError(t, err)
+
+		capture := writer.Captured()
+
+		assert.Equal(t, http.StatusCreated, writer.StatusCode())
+		assert.Equal(t, http.StatusCreated, capture.StatusCode)
+		assert.Equal(t, "text/plain", capture.Header.Get(headers.ContentType))
+		assert.Equal(t, "created", string(capture.Body))
+	})
+
+	t.Run("defaults to status ok", func(t *testing.T) {
+		writer := infra.NewBufferedWriter()
+
+		assert.Equal(t, 0, writer.StatusCode())
+		assert.Equal(t, http.StatusOK, writer.Captured().StatusCode)
+
+		_, err := writer.Write([]byte("ok"))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, writer.StatusCode())
+	})
+
+	t.Run("replaces the response", func(t *testing.T) {
+		writer := infra.NewBufferedWriter()
+		writer.WriteHeader(http.StatusOK)
+
+		_, err := writer.Write([]byte("original"))
+		require.NoError(t, err)
+
+		writer.SetStatusCode(http.StatusAccepted)
+		writer.SetHeader(http.Header{"X-Custom": {"value"}})
+		writer.SetBody([]byte("changed"))
+
+		capture := writer.Captured()
+
+		assert.Equal(t, http.StatusAccepted, capture.StatusCode)
+		assert.Equal(t, "value", capture.Header.Get("X-Custom"))
+		assert.Equal(t, "7", capture.Header.Get(headers.ContentLength))
+		assert.Equal(t, "changed", string(capture.Body))
+	})
+
+	t.Run("replays the response", func(t *testing.T) {
+		writer := infra.NewBufferedWriter()
+		writer.Header().Set("X-Custom", "value")
+		writer.WriteHeader(http.StatusNotFound)
+
+		_, err := writer.Write([]byte("missing"))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+
+		require.NoError(t, writer.Replay(server.NewResponseRecorder(recorder)))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, "value", recorder.Header().Get("X-Custom"))
+		assert.Equal(t, "missing", testutils.ReadBody(t, recorder))
+	})
+}
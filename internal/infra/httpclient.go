@@ -12,6 +12,9 @@ const defaultTimeout = 5 * time.Minute
 func MakeHTTPClient(proxy string) *http.Client {
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
+		// Only the wait for response headers is limited: a whole-request
+		// timeout would cut long-lived streams such as Server-Sent Events.
+		ResponseHeaderTimeout: defaultTimeout,
 	}
 
 	if proxy != "" {
@@ -28,6 +31,5 @@ func MakeHTTPClient(proxy string) *http.Client {
 			return http.ErrUseLastResponse
 		},
 		Transport: transport,
-		Timeout:   defaultTimeout,
 	}
 }
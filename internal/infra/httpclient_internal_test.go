@@ -14,7 +14,8 @@ func TestMakeHTTPClient(t *testing.T) {
 
 		assert.NotNil(t, client)
 		assert.NotNil(t, client.Transport)
-		assert.Equal(t, defaultTimeout, client.Timeout)
+		assert.Zero(t, client.Timeout)
+		assert.Equal(t, defaultTimeout, client.Transport.(*http.Transport).ResponseHeaderTimeout)
 	})
 
 	t.Run("check redirect should return error", func(t *testing.T) {
@@ -38,7 +39,8 @@ func TestMakeHTTPClient(t *testing.T) {
 
 		assert.NotNil(t, client)
 		assert.NotNil(t, client.Transport)
-		assert.Equal(t, defaultTimeout, client.Timeout)
+		assert.Zero(t, client.Timeout)
+		assert.Equal(t, defaultTimeout, client.Transport.(*http.Transport).ResponseHeaderTimeout)
 	})
 
 	t.Run("return error where url is incorrect", func(t *testing.T) {
@@ -15,6 +15,9 @@ var (
 	// ErrNoCertificateForHost is returned when no certificate is available for the requested host.
 	ErrNoCertificateForHost = errors.New("no certificate available for host")
 
+	// ErrHijackNotSupported is returned when the underlying writer cannot hand over its connection.
+	ErrHijackNotSupported = errors.New("response writer does not support hijacking")
+
 	// ErrCACertExpired is returned when the CA certificate has already expired.
 	ErrCACertExpired = errors.New("CA certificate has expired! Please regenerate it with: uncors generate-certs --force")
 
@@ -1,19 +1,25 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/evg4b/uncors/internal/contracts"
 )
 
+// MaxCaptureSize caps the captured body so endless streams (SSE, long-poll)
+// can not grow memory forever. Bytes past the limit still reach the client.
+const MaxCaptureSize = 10 * 1024 * 1024 // 10 MB
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 
 	statusCode int
-	buf        *bytes.Buffer
+	buf        *captureBuffer
 	output     io.Writer
 	startedAt  time.Time
 }
@@ -37,6 +43,32 @@ func (r *ResponseRecorder) Write(b []byte) (int, error) {
 	return r.output.Write(b)
 }
 
+// Flush sends any buffered data to the client so streamed responses are
+// delivered as they arrive.
+func (r *ResponseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets protocol upgrades (e.g. WebSocket) take over the client
+// connection. Once hijacked the recorder reports 101 Switching Protocols.
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackNotSupported
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.statusCode = http.StatusSwitchingProtocols
+
+	return conn, buf, nil
+}
+
 func (r *ResponseRecorder) StatusCode() int {
 	return r.statusCode
 }
@@ -46,24 +78,51 @@ func (r *ResponseRecorder) EnableBodyCapture() {
 		return
 	}
 
-	r.buf = &bytes.Buffer{}
+	r.buf = &captureBuffer{limit: MaxCaptureSize}
 	r.output = io.MultiWriter(r.buf, r.ResponseWriter)
 }
 
 func (r *ResponseRecorder) Captured() contracts.ResponseCapture {
-	var body []byte
+	var (
+		body      []byte
+		truncated bool
+	)
+
 	if r.buf != nil {
 		body = r.buf.Bytes()
+		truncated = r.buf.truncated
 	}
 
 	return contracts.ResponseCapture{
 		StatusCode: normaliseStatusCode(r.statusCode),
 		Header:     r.Header(),
 		Body:       body,
+		Truncated:  truncated,
 		Duration:   time.Since(r.startedAt),
 	}
 }
 
+// captureBuffer keeps at most limit bytes and silently discards the rest,
+// remembering that the captured body is incomplete.
+type captureBuffer struct {
+	bytes.Buffer
+
+	limit     int
+	truncated bool
+}
+
+func (c *captureBuffer) Write(b []byte) (int, error) {
+	remaining := c.limit - c.Len()
+	if len(b) > remaining {
+		c.truncated = true
+		c.Buffer.Write(b[:max(remaining, 0)])
+
+		return len(b), nil
+	}
+
+	return c.Buffer.Write(b)
+}
+
 func normaliseStatusCode(code int) int {
 	if code == 0 {
 		return http.StatusOK
@@ -1,6 +1,9 @@
 package server_test
 
 import (
+	"bufio"
+	"bytes"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -100,6 +103,86 @@ func TestResponseRecorder_Captured(t *testing.T) {
 	})
 }
 
+func TestResponseRecorder_CaptureLimit(t *testing.T) {
+	t.Run("is not truncated under the limit", func(t *testing.T) {
+		rec := server.NewResponseRecorder(httptest.NewRecorder())
+		rec.EnableBodyCapture()
+		_, err := rec.Write([]byte("small"))
+		require.NoError(t, err)
+
+		assert.False(t, rec.Captured().Truncated)
+	})
+
+	t.Run("caps captured body and keeps writing through", func(t *testing.T) {
+		underlying := httptest.NewRecorder()
+		rec := server.NewResponseRecorder(underlying)
+		rec.EnableBodyCapture()
+
+		chunk := bytes.Repeat([]byte("a"), server.MaxCaptureSize-1)
+		_, err := rec.Write(chunk)
+		require.NoError(t, err)
+
+		n, err := rec.Write([]byte("bcd"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		captured := rec.Captured()
+		assert.True(t, captured.Truncated)
+		assert.Len(t, captured.Body, server.MaxCaptureSize)
+		assert.Equal(t, byte('b'), captured.Body[len(captured.Body)-1])
+		assert.Equal(t, server.MaxCaptureSize+2, underlying.Body.Len())
+	})
+}
+
+func TestResponseRecorder_Flush(t *testing.T) {
+	t.Run("flushes underlying writer", func(t *testing.T) {
+		underlying := httptest.NewRecorder()
+		rec := server.NewResponseRecorder(underlying)
+
+		rec.Flush()
+
+		assert.True(t, underlying.Flushed)
+	})
+
+	t.Run("does nothing when underlying writer can not be flushed", func(t *testing.T) {
+		rec := server.NewResponseRecorder(struct{ http.ResponseWriter }{httptest.NewRecorder()})
+
+		assert.NotPanics(t, rec.Flush)
+	})
+}
+
+func TestResponseRecorder_Hijack(t *testing.T) {
+	t.Run("returns error when underlying writer can not be hijacked", func(t *testing.T) {
+		rec := server.NewResponseRecorder(httptest.NewRecorder())
+
+		_, _, err := rec.Hijack()
+
+		require.ErrorIs(t, err, server.ErrHijackNotSupported)
+		assert.Equal(t, 0, rec.StatusCode())
+	})
+
+	t.Run("reports switching protocols after hijack", func(t *testing.T) {
+		rec := server.NewResponseRecorder(&hijackableWriter{ResponseRecorder: httptest.NewRecorder()})
+
+		conn, _, err := rec.Hijack()
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		assert.Equal(t, http.StatusSwitchingProtocols, rec.StatusCode())
+	})
+}
+
+type hijackableWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w *hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	client, _ := net.Pipe()
+
+	return client, bufio.NewReadWriter(bufio.NewReader(client), bufio.NewWriter(client)), nil
+}
+
 func TestResponseRecorder_ImplementsInterfaces(t *testing.T) {
 	rec := server.NewResponseRecorder(httptest.NewRecorder())
 
@@ -110,4 +193,12 @@ func TestResponseRecorder_ImplementsInterfaces(t *testing.T) {
 	t.Run("implements BodyCapturer", func(_ *testing.T) {
 		var _ contracts.BodyCapturer = rec
 	})
+
+	t.Run("implements Hijacker", func(_ *testing.T) {
+		var _ http.Hijacker = rec
+	})
+
+	t.Run("implements Flusher", func(_ *testing.T) {
+		var _ http.Flusher = rec
+	})
 }
@@ -4,6 +4,14 @@ const DisclaimerMessage = `DON'T USE IT FOR PRODUCTION!
 This is a reverse proxy for use in testing or debugging web applications locally.
 It hasn't been reviewed for security issues.`
 
+const ExposedListenerMessage = `SERVER IS REACHABLE FROM THE NETWORK!
+Listening on %s.
+Anyone who can reach this machine can use the proxy and your mocked or cached data.`
+
+const OfflineModeMessage = `OFFLINE MODE IS ENABLED!
+Cacheable requests are served only from the cache, including expired entries.
+Requests without a cached response get 504 Gateway Timeout.`
+
 const NewVersionIsAvailable = `NEW VERSION IS AVAILABLE!
 %s is not the latest version, you should upgrade to %s.
 See more information at https://github.com/evg4b/uncors/releases
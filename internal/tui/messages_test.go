@@ -19,3 +19,14 @@ func TestNewVersionIsAvailable(t *testing.T) {
 	assert.Contains(t, tui.NewVersionIsAvailable, "NEW VERSION IS AVAILABLE")
 	assert.Contains(t, tui.NewVersionIsAvailable, "%s")
 }
+
+func TestExposedListenerMessage(t *testing.T) {
+	assert.NotEmpty(t, tui.ExposedListenerMessage)
+	assert.Contains(t, tui.ExposedListenerMessage, "REACHABLE FROM THE NETWORK")
+	assert.Contains(t, tui.ExposedListenerMessage, "%s")
+}
+
+func TestOfflineModeMessage(t *testing.T) {
+	assert.NotEmpty(t, tui.OfflineModeMessage)
+	assert.Contains(t, tui.OfflineModeMessage, "OFFLINE MODE")
+}
@@ -17,6 +17,7 @@ type outputType int8
 
 const (
 	defaultOutput outputType = iota
+	debugOutput
 	infoOutput
 	warnOutput
 	errorOutput
@@ -25,6 +26,7 @@ const (
 var boxLength = 8
 
 var levelStyles = map[outputType]lipgloss.Style{
+	debugOutput:   styles.DebugBlockStyle.Width(boxLength).Bold(true),
 	infoOutput:    styles.InfoBlockStyle.Width(boxLength).Bold(true),
 	warnOutput:    styles.WarningBlockStyle.Width(boxLength).Bold(true),
 	errorOutput:   styles.ErrorBlockStyle.Width(boxLength).Bold(true),
@@ -32,6 +34,7 @@ var levelStyles = map[outputType]lipgloss.Style{
 }
 
 var messageMap = map[outputType]string{
+	debugOutput: DebugLabel,
 	infoOutput:  InfoLabel,
 	warnOutput:  WarningLabel,
 	errorOutput: ErrorLabel,
@@ -72,6 +75,14 @@ func (output *CliOutput) Write(p []byte) (int, error) {
 	return output.output.Write(p)
 }
 
+func (output *CliOutput) Debug(msg any) {
+	output.print(fmt.Sprint(msg), debugOutput)
+}
+
+func (output *CliOutput) Debugf(msg string, args ...any) {
+	output.print(fmt.Sprintf(msg, args...), debugOutput)
+}
+
 func (output *CliOutput) Info(msg any) {
 	output.print(fmt.Sprint(msg), infoOutput)
 }
@@ -35,6 +35,20 @@ func TestCliOutput_Write(t *testing.T) {
 	assert.Equal(t, "direct write", buf.String())
 }
 
+func TestCliOutput_Debug(t *testing.T) {
+	t.Run("Debug", testutils.WithTrueColor(func(t *testing.T) {
+		var buf strings.Builder
+		tui.NewCliOutput(&buf).Debug("test debug")
+		testutils.MatchSnapshot(t, buf.String())
+	}))
+
+	t.Run("Debugf", testutils.WithTrueColor(func(t *testing.T) {
+		var buf strings.Builder
+		tui.NewCliOutput(&buf).Debugf("formatted %s %d", "debug", 42)
+		testutils.MatchSnapshot(t, buf.String())
+	}))
+}
+
 func TestCliOutput_Info(t *testing.T) {
 	t.Run("Info", testutils.WithTrueColor(func(t *testing.T) {
 		var buf strings.Builder
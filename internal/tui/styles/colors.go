@@ -30,6 +30,7 @@ var (
 	cacheColor   = lightDark(lipgloss.Color("#CCC906"), lipgloss.Color("#FEFC7F"))
 	rewriteColor = lightDark(lipgloss.Color("#FF7F00"), lipgloss.Color("#FF7F00"))
 	optionsColor = lightDark(lipgloss.Color("#005BA5"), lipgloss.Color("#0072CE"))
+	wsColor      = lightDark(lipgloss.Color("#008080"), lipgloss.Color("#2EC4B6"))
 
 	// Http status colors.
 
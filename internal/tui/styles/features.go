@@ -7,4 +7,5 @@ var (
 	CacheStyle   = blockStyle.Background(cacheColor)
 	RewriteStyle = blockStyle.Background(rewriteColor)
 	OptionsStyle = blockStyle.Background(optionsColor)
+	WSStyle      = blockStyle.Background(wsColor)
 )
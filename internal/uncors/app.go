@@ -3,8 +3,8 @@ package uncors
 import (
 	"context"
 	"errors"
-	"net"
-	"strconv"
+	"fmt"
+	"strings"
 
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/di"
@@ -15,8 +15,6 @@ import (
 	"github.com/spf13/afero"
 )
 
-const baseAddress = "127.0.0.1"
-
 type Uncors struct {
 	fs afero.Fs
 
@@ -47,17 +45,25 @@ func (app *Uncors) Start(ctx context.Context, uncorsConfig *config.UncorsConfig)
 		return err
 	}
 
+	app.warnExposedTargets(uncorsConfig)
+	app.applyOfflineMode(uncorsConfig)
+
 	return app.server.Start(ctx, targets)
 }
 
 func (app *Uncors) Restart(ctx context.Context, uncorsConfig *config.UncorsConfig) error {
 	app.output.Info("Restarting server....")
+	app.container.Scenarios().Reset()
+	app.container.ScriptStores().Reset()
 
 	targets, err := app.mappingsToTarget(uncorsConfig)
 	if err != nil {
 		return err
 	}
 
+	app.warnExposedTargets(uncorsConfig)
+	app.applyOfflineMode(uncorsConfig)
+
 	err = app.server.Restart(ctx, targets)
 	if err != nil {
 		return err
@@ -84,12 +90,18 @@ func (app *Uncors) Shutdown(ctx context.Context) error {
 }
 
 func (app *Uncors) mappingsToTarget(uncorsConfig *config.UncorsConfig) ([]server.Target, error) {
-	groupedMappings := uncorsConfig.Mappings.GroupByPort()
+	groupedMappings := uncorsConfig.Mappings.GroupByPort(uncorsConfig.Listen)
 	targets := make([]server.Target, 0, len(groupedMappings))
 	errs := make([]error, 0, len(groupedMappings))
 
 	for _, group := range groupedMappings {
-		muxRouter, err := app.container.Router(group.Mappings, &uncorsConfig.CacheConfig, uncorsConfig.Proxy)
+		muxRouter, err := app.container.Router(
+			group.Mappings,
+			&uncorsConfig.CacheConfig,
+			uncorsConfig.Proxy,
+			uncorsConfig.LuaPath,
+			uncorsConfig.Debug,
+		)
 		if err != nil {
 			errs = append(errs, err)
 
@@ -97,7 +109,7 @@ func (app *Uncors) mappingsToTarget(uncorsConfig *config.UncorsConfig) ([]server
 		}
 
 		targets = append(targets, server.Target{
-			Address:   net.JoinHostPort(baseAddress, strconv.Itoa(group.Port)),
+			Address:   group.Address(uncorsConfig.Listen),
 			Handler:   muxRouter,
 			EnableTLS: group.Scheme == "https",
 		})
@@ -105,3 +117,30 @@ func (app *Uncors) mappingsToTarget(uncorsConfig *config.UncorsConfig) ([]server
 
 	return targets, errors.Join(errs...)
 }
+
+// warnExposedTargets prints a warning when any listener accepts connections
+// from outside the local machine.
+func (app *Uncors) warnExposedTargets(uncorsConfig *config.UncorsConfig) {
+	var exposed []string
+
+	for _, group := range uncorsConfig.Mappings.GroupByPort(uncorsConfig.Listen) {
+		if !config.IsLoopbackListen(group.EffectiveListen(uncorsConfig.Listen)) {
+			exposed = append(exposed, group.Address(uncorsConfig.Listen))
+		}
+	}
+
+	if len(exposed) > 0 {
+		app.output.WarnBox(fmt.Sprintf(tui.ExposedListenerMessage, strings.Join(exposed, ", ")))
+		app.output.Print("")
+	}
+}
+
+// applyOfflineMode switches offline mode to the configured state.
+func (app *Uncors) applyOfflineMode(uncorsConfig *config.UncorsConfig) {
+	app.container.OfflineMode().Set(uncorsConfig.Offline)
+
+	if uncorsConfig.Offline {
+		app.output.WarnBox(tui.OfflineModeMessage)
+		app.output.Print("")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -161,6 +162,137 @@ func TestUncorsStart(t *testing.T) {
 	assert.Equal(t, "OK", string(body))
 }
 
+func TestUncorsListen(t *testing.T) {
+	targetServer := testutils.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "OK")
+	}))
+	defer targetServer.Close()
+
+	tests := []struct {
+		name          string
+		listen        string
+		mappingListen string
+		dialHost      string
+		exposed       bool
+	}{
+		{name: "IPv6 loopback", listen: "::1", dialHost: "[::1]"},
+		{name: "mapping override", listen: "0.0.0.0", mappingListen: "127.0.0.1", dialHost: "127.0.0.1"},
+		{name: "all interfaces", listen: config.AllInterfaces, dialHost: "127.0.0.1", exposed: true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stdout strings.Builder
+
+			container := di.NewContainer(di.WithVersion(version), di.WithStdout(&stdout))
+			defer testutils.Close(t, container)
+
+			app := uncors.CreateUncors(container)
+			port := testutils.GetFreePort(t)
+
+			err := app.Start(t.Context(), &config.UncorsConfig{
+				Listen: testCase.listen,
+				Mappings: []config.Mapping{
+					{
+						From:   hosts.Localhost.HTTPPort(port),
+						To:     hosts.Parse(targetServer.URL),
+						Listen: testCase.mappingListen,
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			defer app.Close()
+
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodGet,
+				fmt.Sprintf("http://%s:%d/", testCase.dialHost, port),
+				nil,
+			)
+			require.NoError(t, err)
+
+			req.Host = hosts.Localhost.HTTPPort(port).HostPort()
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			if testCase.exposed {
+				assert.Contains(t, stdout.String(), "SERVER IS REACHABLE FROM THE NETWORK")
+			} else {
+				assert.NotContains(t, stdout.String(), "SERVER IS REACHABLE FROM THE NETWORK")
+			}
+		})
+	}
+}
+
+func TestUncorsOffline(t *testing.T) {
+	calls := 0
+	targetServer := testutils.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+
+		fmt.Fprint(w, "OK")
+	}))
+	defer targetServer.Close()
+
+	var stdout strings.Builder
+
+	container := di.NewContainer(di.WithVersion(version), di.WithStdout(&stdout))
+	defer testutils.Close(t, container)
+
+	app := uncors.CreateUncors(container)
+	port := testutils.GetFreePort(t)
+
+	uncorsConfig := &config.UncorsConfig{
+		Offline: true,
+		Mappings: []config.Mapping{
+			{
+				From:  hosts.Localhost.HTTPPort(port),
+				To:    hosts.Parse(targetServer.URL),
+				Cache: config.CacheRules{{Path: "/**"}},
+			},
+		},
+		CacheConfig: config.CacheConfig{
+			ExpirationTime: time.Minute,
+			MaxSize:        1024 * 1024,
+			Methods:        []string{http.MethodGet},
+		},
+	}
+
+	err := app.Start(t.Context(), uncorsConfig)
+	require.NoError(t, err)
+
+	defer app.Close()
+
+	get := func() int {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, hosts.Localhost.HTTPPort(port).String(), nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	assert.Contains(t, stdout.String(), "OFFLINE MODE IS ENABLED")
+	assert.True(t, container.OfflineMode().Enabled())
+	assert.Equal(t, http.StatusGatewayTimeout, get())
+	assert.Equal(t, 0, calls)
+
+	uncorsConfig.Offline = false
+	err = app.Restart(t.Context(), uncorsConfig)
+	require.NoError(t, err)
+
+	assert.False(t, container.OfflineMode().Enabled())
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, 1, calls)
+}
+
 func TestUncorsRestart(t *testing.T) {
 	container := di.NewContainer(di.WithVersion(version))
 	defer testutils.Close(t, container)
@@ -225,6 +357,36 @@ func TestUncorsRestart(t *testing.T) {
 	assert.Equal(t, "Server 2", string(body2))
 }
 
+func TestUncorsRestartResetsScenarios(t *testing.T) {
+	container := di.NewContainer(di.WithVersion(version))
+	defer testutils.Close(t, container)
+
+	app := uncors.CreateUncors(container)
+
+	targetServer := testutils.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer targetServer.Close()
+
+	uncorsConfig := &config.UncorsConfig{
+		Mappings: []config.Mapping{
+			{From: hosts.Loopback.HTTPPort(testutils.GetFreePort(t)), To: hosts.Parse(targetServer.URL)},
+		},
+	}
+
+	err := app.Start(context.Background(), uncorsConfig)
+	require.NoError(t, err)
+
+	defer app.Close()
+
+	container.Scenarios().Set("order", "shipped")
+
+	err = app.Restart(context.Background(), uncorsConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, config.DefaultScenarioState, container.Scenarios().State("order"))
+}
+
 func TestUncorsClose(t *testing.T) {
 	container := di.NewContainer(di.WithVersion(version))
 	defer testutils.Close(t, container)
@@ -512,7 +674,7 @@ func TestUncorsWithComplexConfiguration(t *testing.T) {
 						},
 					},
 				},
-				Cache: config.CacheGlobs{"/cache/*"},
+				Cache: config.CacheRules{{Path: "/cache/*"}},
 			},
 		},
 		CacheConfig: config.CacheConfig{
@@ -353,8 +353,8 @@ func TestHandlerWithCache(t *testing.T) {
 			{
 				From: hosts.Loopback.HTTPPort(port),
 				To:   hosts.Parse(targetServer.URL),
-				Cache: config.CacheGlobs{
-					"/cached/*",
+				Cache: config.CacheRules{
+					{Path: "/cached/*"},
 				},
 			},
 		},
@@ -2,6 +2,7 @@ package uncorsapp
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -12,8 +13,11 @@ import (
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/di"
+	"github.com/evg4b/uncors/internal/handler/cache"
+	"github.com/evg4b/uncors/internal/handler/har"
 	"github.com/evg4b/uncors/internal/helpers"
 	"github.com/evg4b/uncors/internal/server"
+	"github.com/evg4b/uncors/internal/tui/styles"
 	"github.com/evg4b/uncors/internal/uncors"
 )
 
@@ -25,12 +29,18 @@ const (
 	bytesPerMegabyte  = 1024 * 1024
 )
 
+var warningIndicatorStyle = lipgloss.NewStyle().
+	Foreground(styles.WarningColor).
+	Bold(true)
+
 type UncorsApp struct {
 	keys keyMap
 
 	app       *uncors.Uncors
 	output    *tuiOutput
 	tracker   server.IRequestTracker
+	offline   *cache.OfflineMode
+	harStats  *har.Stats
 	container *di.Container
 
 	outputCh   chan string
@@ -91,6 +101,8 @@ func NewUncorsApp(
 		app:           uncors.CreateUncors(container),
 		output:        output,
 		tracker:       container.RequestTracker(),
+		offline:       container.OfflineMode(),
+		harStats:      container.HARStats(),
 		container:     container,
 		outputCh:      outputCh,
 		appContext:    func() context.Context { return appCtx },
@@ -199,6 +211,14 @@ func (m *UncorsApp) View() tea.View {
 	helpStr := m.helpWidget.View().Content
 	memStr := m.memWidget.View().Content
 
+	if dropped := m.harStats.Dropped(); dropped > 0 {
+		memStr = warningIndicatorStyle.Render(fmt.Sprintf("[ HAR DROPPED: %d ]", dropped)) + " " + memStr
+	}
+
+	if m.offline.Enabled() {
+		memStr = warningIndicatorStyle.Render("[ OFFLINE ]") + " " + memStr
+	}
+
 	gap := m.termWidth - lipgloss.Width(helpStr) - lipgloss.Width(memStr)
 	if gap > 0 {
 		viewBuilder.WriteString(helpStr + strings.Repeat(" ", gap) + memStr)
@@ -225,6 +245,18 @@ func (m *UncorsApp) handleKeyPress(msg tea.KeyPressMsg) tea.Cmd {
 		return m.restartCmd()
 	}
 
+	if key.Matches(msg, m.keys.Offline) {
+		m.toggleOffline()
+
+		return nil
+	}
+
+	if key.Matches(msg, m.keys.ClearStore) {
+		m.clearScriptStores()
+
+		return nil
+	}
+
 	if key.Matches(msg, m.keys.Quit) {
 		return m.shutdownCmd()
 	}
@@ -232,6 +264,27 @@ func (m *UncorsApp) handleKeyPress(msg tea.KeyPressMsg) tea.Cmd {
 	return nil
 }
 
+func (m *UncorsApp) toggleOffline() {
+	if m.offline.Toggle() {
+		m.output.Warn("Offline mode enabled: serving cacheable requests only from the cache")
+	} else {
+		m.output.Info("Offline mode disabled")
+	}
+}
+
+// clearScriptStores removes the data of all script stores, including the
+// persisted ones.
+func (m *UncorsApp) clearScriptStores() {
+	err := m.container.ScriptStores().Clear()
+	if err != nil {
+		m.output.Errorf("Failed to clear script store: %v", err)
+
+		return
+	}
+
+	m.output.Info("Script store cleared")
+}
+
 func (m *UncorsApp) updateHistoryHeight() {
 	footerHeight := m.footerHeight()
 	viewportHeight := max(m.termHeight-footerHeight, 1)
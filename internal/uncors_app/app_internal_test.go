@@ -1,9 +1,11 @@
 package uncorsapp
 
 import (
+	"encoding/json"
 	"errors"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/evg4b/uncors/internal/config"
 	"github.com/evg4b/uncors/internal/contracts"
 	"github.com/evg4b/uncors/internal/di"
+	"github.com/evg4b/uncors/internal/handler/har"
 	"github.com/evg4b/uncors/internal/server"
 	"github.com/evg4b/uncors/testing/testutils"
 	"github.com/stretchr/testify/assert"
@@ -76,12 +79,12 @@ func TestNewUncorsAppAndKeyMap(t *testing.T) {
 	require.NotNil(t, app.Init())
 
 	keys := newKeyMap()
-	assert.Len(t, keys.ShortHelp(), 3)
+	assert.Len(t, keys.ShortHelp(), 4)
 	fullHelp := keys.FullHelp()
 	require.Len(t, fullHelp, 3)
 	assert.Len(t, fullHelp[0], 4)
-	assert.Len(t, fullHelp[1], 2)
-	assert.Len(t, fullHelp[2], 3)
+	assert.Len(t, fullHelp[1], 3)
+	assert.Len(t, fullHelp[2], 4)
 }
 
 func TestUncorsAppUpdateViewAndLayout(t *testing.T) {
@@ -253,6 +256,24 @@ func TestUncorsAppKeyHandlingAndMessages(t *testing.T) {
 	assert.Nil(t, cmd)
 	assert.True(t, app.historyWidget.autoScroll)
 
+	_, cmd = app.Update(tea.KeyPressMsg(tea.Key{Text: "o", Code: 'o'}))
+	assert.Nil(t, cmd)
+	assert.True(t, app.offline.Enabled())
+	assert.Contains(t, app.View().Content, "OFFLINE")
+
+	_, cmd = app.Update(tea.KeyPressMsg(tea.Key{Text: "o", Code: 'o'}))
+	assert.Nil(t, cmd)
+	assert.False(t, app.offline.Enabled())
+	assert.NotContains(t, app.View().Content, "OFFLINE")
+
+	store, err := app.container.ScriptStores().For("localhost", "")
+	require.NoError(t, err)
+	require.NoError(t, store.Set("todos", json.RawMessage(`[1]`)))
+
+	_, cmd = app.Update(tea.KeyPressMsg(tea.Key{Text: "s", Code: 's'}))
+	assert.Nil(t, cmd)
+	assert.Empty(t, store.Keys(""))
+
 	_, cmd = app.Update(tea.KeyPressMsg(tea.Key{Text: "r", Code: 'r'}))
 	require.NotNil(t, cmd)
 	assert.Equal(t, restartMsg{}, cmd())
@@ -262,6 +283,26 @@ func TestUncorsAppKeyHandlingAndMessages(t *testing.T) {
 	assert.Equal(t, shutdownMsg{}, cmd())
 }
 
+func TestUncorsAppShowsDroppedHAREntries(t *testing.T) {
+	app, _ := newTestApp(t)
+	defer cleanupTestApp(t, app)
+
+	app.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	assert.NotContains(t, app.View().Content, "HAR DROPPED")
+
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	require.NoError(t, os.WriteFile(blocker, []byte("x"), 0o600))
+
+	writer := har.NewWriter(filepath.Join(blocker, "out.har"), har.WithStats(app.harStats))
+	for app.harStats.Dropped() == 0 {
+		writer.AddEntry(har.Entry{})
+	}
+
+	require.NoError(t, writer.Close())
+
+	assert.Contains(t, app.View().Content, "HAR DROPPED")
+}
+
 func TestUncorsAppServerErrorRestartShutdownAndFormatting(t *testing.T) {
 	t.Run("server error and restart messages update state", func(t *testing.T) {
 		app, _ := newTestApp(t)
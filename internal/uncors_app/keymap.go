@@ -5,6 +5,8 @@ import "charm.land/bubbles/v2/key"
 type keyMap struct {
 	Help       key.Binding
 	Restart    key.Binding
+	Offline    key.Binding
+	ClearStore key.Binding
 	Quit       key.Binding
 	ScrollUp   key.Binding
 	ScrollDown key.Binding
@@ -28,6 +30,14 @@ func newKeyMap() keyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "reload"),
 		),
+		Offline: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "offline mode"),
+		),
+		ClearStore: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "clear store"),
+		),
 		ScrollUp: key.NewBinding(
 			key.WithKeys("up", "k"),
 			key.WithHelp("↑/k", "scroll up"),
@@ -56,13 +66,13 @@ func newKeyMap() keyMap {
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Restart, k.Quit}
+	return []key.Binding{k.Help, k.Restart, k.Offline, k.Quit}
 }
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.ScrollUp, k.ScrollDown, k.PageUp, k.PageDown},
-		{k.GotoTop, k.GotoBottom},
-		{k.Help, k.Restart, k.Quit},
+		{k.GotoTop, k.GotoBottom, k.Quit},
+		{k.Help, k.Restart, k.Offline, k.ClearStore},
 	}
 }
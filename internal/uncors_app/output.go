@@ -23,6 +23,14 @@ func (o *tuiOutput) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
+func (o *tuiOutput) Debug(msg any) {
+	o.capture(func(out *tui.CliOutput) { out.Debug(msg) })
+}
+
+func (o *tuiOutput) Debugf(msg string, args ...any) {
+	o.capture(func(out *tui.CliOutput) { out.Debugf(msg, args...) })
+}
+
 func (o *tuiOutput) Info(msg any) {
 	o.capture(func(out *tui.CliOutput) { out.Info(msg) })
 }
@@ -29,6 +29,20 @@ func newTestOutput() (*tuiOutput, <-chan string) {
 	return newTuiOutput(outputCh), outputCh
 }
 
+func TestTuiOutput_Debug(t *testing.T) {
+	t.Run("Debug sends message to channel", func(t *testing.T) {
+		out, ch := newTestOutput()
+		out.Debug("hello debug")
+		assert.Contains(t, recv(t, ch), "hello debug")
+	})
+
+	t.Run("Debugf formats and sends message", func(t *testing.T) {
+		out, ch := newTestOutput()
+		out.Debugf("value is %d", 42)
+		assert.Contains(t, recv(t, ch), "42")
+	})
+}
+
 func TestTuiOutput_Info(t *testing.T) {
 	t.Run("Info sends message to channel", func(t *testing.T) {
 		out, ch := newTestOutput()
@@ -10,8 +10,10 @@ import (
 )
 
 var (
-	placeholderRegexp     = regexp.MustCompile(`\{([a-zA-Z][a-zA-Z0-9_]*)\}`)
-	schemeRegexp          = regexp.MustCompile(`^(https?):`)
+	placeholderRegexp = regexp.MustCompile(`\{([a-zA-Z][a-zA-Z0-9_]*)\}`)
+	schemeRegexp      = regexp.MustCompile(`^(https?):`)
+	// urlAuthorityRegexp finds the scheme, host and port of URLs in text.
+	urlAuthorityRegexp    = regexp.MustCompile(`(?:https?:)?(?://|\\/\\/)[A-Za-z0-9.\-]+(?::\d+)?`)
 	errEmptyPort          = errors.New("empty port")
 	errEmptyURL           = errors.New("url is empty")
 	errWildcardNotAllowed = errors.New("use {key} placeholders instead of * wildcard")
@@ -111,6 +111,33 @@ func (r *Replacer) ReplaceSoft(source string) string {
 	return source
 }
 
+// ReplaceAllIn replaces every URL matched by the replacer inside text, such
+// as an HTML or JSON body. Only the scheme, host and port of each URL are
+// replaced; paths and the rest of the text are kept. URLs with JSON-escaped
+// slashes (https:\/\/host) are supported.
+func (r *Replacer) ReplaceAllIn(text []byte) []byte {
+	return urlAuthorityRegexp.ReplaceAllFunc(text, func(match []byte) []byte {
+		authority := strings.TrimRight(string(match), ".")
+		suffix := string(match[len(authority):])
+
+		escaped := strings.Contains(authority, `\/`)
+		if escaped {
+			authority = strings.ReplaceAll(authority, `\/`, "/")
+		}
+
+		replaced, err := r.Replace(authority)
+		if err != nil {
+			return match
+		}
+
+		if escaped {
+			replaced = strings.ReplaceAll(replaced, "/", `\/`)
+		}
+
+		return []byte(replaced + suffix)
+	})
+}
+
 func (r *Replacer) IsTargetSecure() bool {
 	if len(r.scheme) > 0 {
 		return isSecure(r.scheme)
@@ -334,3 +334,63 @@ func TestReplacerIsMatched(t *testing.T) {
 		})
 	}
 }
+
+func TestReplacerReplaceAllIn(t *testing.T) {
+	replacer, err := urlreplacer.NewReplacer("https://api.example.com", "http://localhost:3000")
+	require.NoError(t, err)
+
+	wildcard, err := urlreplacer.NewReplacer("https://{tenant}.api.com", "http://{tenant}.local.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		replacer *urlreplacer.Replacer
+		text     string
+		expected string
+	}{
+		{
+			name:     "html links",
+			replacer: replacer,
+			text:     `<a href="https://api.example.com/docs?page=1">docs</a><img src="https://api.example.com:443/logo.png">`,
+			expected: `<a href="http://localhost:3000/docs?page=1">docs</a><img src="http://localhost:3000/logo.png">`,
+		},
+		{
+			name:     "json values",
+			replacer: replacer,
+			text:     `{"next":"https://api.example.com/users?page=2","self":"https://api.example.com"}`,
+			expected: `{"next":"http://localhost:3000/users?page=2","self":"http://localhost:3000"}`,
+		},
+		{
+			name:     "json escaped slashes",
+			replacer: replacer,
+			text:     `{"next":"https:\/\/api.example.com\/users"}`,
+			expected: `{"next":"http:\/\/localhost:3000\/users"}`,
+		},
+		{
+			name:     "trailing dot",
+			replacer: replacer,
+			text:     `See https://api.example.com.`,
+			expected: `See http://localhost:3000.`,
+		},
+		{
+			name:     "other hosts are kept",
+			replacer: replacer,
+			text:     `https://api.example.com.evil.com https://cdn.example.com/app.js // comment`,
+			expected: `https://api.example.com.evil.com https://cdn.example.com/app.js // comment`,
+		},
+		{
+			name:     "placeholders",
+			replacer: wildcard,
+			text:     `fetch("https://premium.api.com/v1/users")`,
+			expected: `fetch("http://premium.local.com/v1/users")`,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := testCase.replacer.ReplaceAllIn([]byte(testCase.text))
+
+			assert.Equal(t, testCase.expected, string(actual))
+		})
+	}
+}
@@ -22,7 +22,11 @@ import (
 
 var Version = "v0.7.0"
 
-const generateCertsCmd = "generate-certs"
+const (
+	generateCertsCmd = "generate-certs"
+	harCmd           = "har"
+	harImportCmd     = "import"
+)
 
 func main() {
 	exitCode := run()
@@ -50,6 +54,10 @@ func run() int {
 		return runGenerateCerts(container)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == harCmd {
+		return runHAR(container)
+	}
+
 	pflag.Usage = func() {
 		tui.PrintLogo(output, Version)
 		fmt.Fprintf(output, "Usage of %s:\n", os.Args[0])
@@ -92,6 +100,41 @@ func runGenerateCerts(container *di.Container) int {
 	return 0
 }
 
+// runHAR executes the har sub-commands and returns an exit code.
+func runHAR(container *di.Container) int {
+	output := container.CliOutput()
+
+	if len(os.Args) < 3 || os.Args[2] != harImportCmd {
+		output.Errorf("Unknown har command, expected: %s %s <file.har>", harCmd, harImportCmd)
+		log.Printf("Error: unknown har command: %v", os.Args[2:])
+
+		return 1
+	}
+
+	cmd := container.HARImportCommand()
+
+	flags := pflag.NewFlagSet(harCmd+" "+harImportCmd, pflag.ContinueOnError)
+	cmd.DefineFlags(flags)
+
+	err := flags.Parse(os.Args[3:])
+	if err != nil {
+		output.Error(err)
+		log.Printf("Error: %v", err)
+
+		return 1
+	}
+
+	err = cmd.Execute(flags.Args())
+	if err != nil {
+		output.Error(err)
+		log.Printf("Error: %v", err)
+
+		return 1
+	}
+
+	return 0
+}
+
 // runNonInteractive starts the proxy in non-interactive (headless) mode and
 // blocks until the server shuts down. The config file is watched for changes
 // when configPath is non-empty.
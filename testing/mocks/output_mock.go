@@ -18,6 +18,20 @@ type OutputMock struct {
 	t          minimock.Tester
 	finishOnce sync.Once
 
+	funcDebug          func(msg any)
+	funcDebugOrigin    string
+	inspectFuncDebug   func(msg any)
+	afterDebugCounter  uint64
+	beforeDebugCounter uint64
+	DebugMock          mOutputMockDebug
+
+	funcDebugf          func(msg string, args ...any)
+	funcDebugfOrigin    string
+	inspectFuncDebugf   func(msg string, args ...any)
+	afterDebugfCounter  uint64
+	beforeDebugfCounter uint64
+	DebugfMock          mOutputMockDebugf
+
 	funcError          func(msg any)
 	funcErrorOrigin    string
 	inspectFuncError   func(msg any)
@@ -125,6 +139,12 @@ func NewOutputMock(t minimock.Tester) *OutputMock {
 		controller.RegisterMocker(m)
 	}
 
+	m.DebugMock = mOutputMockDebug{mock: m}
+	m.DebugMock.callArgs = []*OutputMockDebugParams{}
+
+	m.DebugfMock = mOutputMockDebugf{mock: m}
+	m.DebugfMock.callArgs = []*OutputMockDebugfParams{}
+
 	m.ErrorMock = mOutputMockError{mock: m}
 	m.ErrorMock.callArgs = []*OutputMockErrorParams{}
 
@@ -172,6 +192,645 @@ func NewOutputMock(t minimock.Tester) *OutputMock {
 	return m
 }
 
+type mOutputMockDebug struct {
+	optional           bool
+	mock               *OutputMock
+	defaultExpectation *OutputMockDebugExpectation
+	expectations       []*OutputMockDebugExpectation
+
+	callArgs []*OutputMockDebugParams
+	mutex    sync.RWMutex
+
+	expectedInvocations       uint64
+	expectedInvocationsOrigin string
+}
+
+// OutputMockDebugExpectation specifies expectation struct of the Output.Debug
+type OutputMockDebugExpectation struct {
+	mock               *OutputMock
+	params             *OutputMockDebugParams
+	paramPtrs          *OutputMockDebugParamPtrs
+	expectationOrigins OutputMockDebugExpectationOrigins
+
+	returnOrigin string
+	Counter      uint64
+}
+
+// OutputMockDebugParams contains parameters of the Output.Debug
+type OutputMockDebugParams struct {
+	msg any
+}
+
+// OutputMockDebugParamPtrs contains pointers to parameters of the Output.Debug
+type OutputMockDebugParamPtrs struct {
+	msg *any
+}
+
+// OutputMockDebugOrigins contains origins of expectations of the Output.Debug
+type OutputMockDebugExpectationOrigins struct {
+	origin    string
+	originMsg string
+}
+
+// Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
+// the test will fail minimock's automatic final call check if the mocked method was not called at least once.
+// Optional() makes method check to work in '0 or more' mode.
+// It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
+// catch the problems when the expected method call is totally skipped during test run.
+func (mmDebug *mOutputMockDebug) Optional() *mOutputMockDebug {
+	mmDebug.optional = true
+	return mmDebug
+}
+
+// Expect sets up expected params for Output.Debug
+func (mmDebug *mOutputMockDebug) Expect(msg any) *mOutputMockDebug {
+	if mmDebug.mock.funcDebug != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by Set")
+	}
+
+	if mmDebug.defaultExpectation == nil {
+		mmDebug.defaultExpectation = &OutputMockDebugExpectation{}
+	}
+
+	if mmDebug.defaultExpectation.paramPtrs != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by ExpectParams functions")
+	}
+
+	mmDebug.defaultExpectation.params = &OutputMockDebugParams{msg}
+	mmDebug.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmDebug.expectations {
+		if minimock.Equal(e.params, mmDebug.defaultExpectation.params) {
+			mmDebug.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmDebug.defaultExpectation.params)
+		}
+	}
+
+	return mmDebug
+}
+
+// ExpectMsgParam1 sets up expected param msg for Output.Debug
+func (mmDebug *mOutputMockDebug) ExpectMsgParam1(msg any) *mOutputMockDebug {
+	if mmDebug.mock.funcDebug != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by Set")
+	}
+
+	if mmDebug.defaultExpectation == nil {
+		mmDebug.defaultExpectation = &OutputMockDebugExpectation{}
+	}
+
+	if mmDebug.defaultExpectation.params != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by Expect")
+	}
+
+	if mmDebug.defaultExpectation.paramPtrs == nil {
+		mmDebug.defaultExpectation.paramPtrs = &OutputMockDebugParamPtrs{}
+	}
+	mmDebug.defaultExpectation.paramPtrs.msg = &msg
+	mmDebug.defaultExpectation.expectationOrigins.originMsg = minimock.CallerInfo(1)
+
+	return mmDebug
+}
+
+// Inspect accepts an inspector function that has same arguments as the Output.Debug
+func (mmDebug *mOutputMockDebug) Inspect(f func(msg any)) *mOutputMockDebug {
+	if mmDebug.mock.inspectFuncDebug != nil {
+		mmDebug.mock.t.Fatalf("Inspect function is already set for OutputMock.Debug")
+	}
+
+	mmDebug.mock.inspectFuncDebug = f
+
+	return mmDebug
+}
+
+// Return sets up results that will be returned by Output.Debug
+func (mmDebug *mOutputMockDebug) Return() *OutputMock {
+	if mmDebug.mock.funcDebug != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by Set")
+	}
+
+	if mmDebug.defaultExpectation == nil {
+		mmDebug.defaultExpectation = &OutputMockDebugExpectation{mock: mmDebug.mock}
+	}
+
+	mmDebug.defaultExpectation.returnOrigin = minimock.CallerInfo(1)
+	return mmDebug.mock
+}
+
+// Set uses given function f to mock the Output.Debug method
+func (mmDebug *mOutputMockDebug) Set(f func(msg any)) *OutputMock {
+	if mmDebug.defaultExpectation != nil {
+		mmDebug.mock.t.Fatalf("Default expectation is already set for the Output.Debug method")
+	}
+
+	if len(mmDebug.expectations) > 0 {
+		mmDebug.mock.t.Fatalf("Some expectations are already set for the Output.Debug method")
+	}
+
+	mmDebug.mock.funcDebug = f
+	mmDebug.mock.funcDebugOrigin = minimock.CallerInfo(1)
+	return mmDebug.mock
+}
+
+// When sets expectation for the Output.Debug which will trigger the result defined by the following
+// Then helper
+func (mmDebug *mOutputMockDebug) When(msg any) *OutputMockDebugExpectation {
+	if mmDebug.mock.funcDebug != nil {
+		mmDebug.mock.t.Fatalf("OutputMock.Debug mock is already set by Set")
+	}
+
+	expectation := &OutputMockDebugExpectation{
+		mock:               mmDebug.mock,
+		params:             &OutputMockDebugParams{msg},
+		expectationOrigins: OutputMockDebugExpectationOrigins{origin: minimock.CallerInfo(1)},
+	}
+	mmDebug.expectations = append(mmDebug.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Output.Debug return parameters for the expectation previously defined by the When method
+
+func (e *OutputMockDebugExpectation) Then() *OutputMock {
+	return e.mock
+}
+
+// Times sets number of times Output.Debug should be invoked
+func (mmDebug *mOutputMockDebug) Times(n uint64) *mOutputMockDebug {
+	if n == 0 {
+		mmDebug.mock.t.Fatalf("Times of OutputMock.Debug mock can not be zero")
+	}
+	mm_atomic.StoreUint64(&mmDebug.expectedInvocations, n)
+	mmDebug.expectedInvocationsOrigin = minimock.CallerInfo(1)
+	return mmDebug
+}
+
+func (mmDebug *mOutputMockDebug) invocationsDone() bool {
+	if len(mmDebug.expectations) == 0 && mmDebug.defaultExpectation == nil && mmDebug.mock.funcDebug == nil {
+		return true
+	}
+
+	totalInvocations := mm_atomic.LoadUint64(&mmDebug.mock.afterDebugCounter)
+	expectedInvocations := mm_atomic.LoadUint64(&mmDebug.expectedInvocations)
+
+	return totalInvocations > 0 && (expectedInvocations == 0 || expectedInvocations == totalInvocations)
+}
+
+// Debug implements mm_contracts.Output
+func (mmDebug *OutputMock) Debug(msg any) {
+	mm_atomic.AddUint64(&mmDebug.beforeDebugCounter, 1)
+	defer mm_atomic.AddUint64(&mmDebug.afterDebugCounter, 1)
+
+	mmDebug.t.Helper()
+
+	if mmDebug.inspectFuncDebug != nil {
+		mmDebug.inspectFuncDebug(msg)
+	}
+
+	mm_params := OutputMockDebugParams{msg}
+
+	// Record call args
+	mmDebug.DebugMock.mutex.Lock()
+	mmDebug.DebugMock.callArgs = append(mmDebug.DebugMock.callArgs, &mm_params)
+	mmDebug.DebugMock.mutex.Unlock()
+
+	for _, e := range mmDebug.DebugMock.expectations {
+		if minimock.Equal(*e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return
+		}
+	}
+
+	if mmDebug.DebugMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmDebug.DebugMock.defaultExpectation.Counter, 1)
+		mm_want := mmDebug.DebugMock.defaultExpectation.params
+		mm_want_ptrs := mmDebug.DebugMock.defaultExpectation.paramPtrs
+
+		mm_got := OutputMockDebugParams{msg}
+
+		if mm_want_ptrs != nil {
+
+			if mm_want_ptrs.msg != nil && !minimock.Equal(*mm_want_ptrs.msg, mm_got.msg) {
+				mmDebug.t.Errorf("OutputMock.Debug got unexpected parameter msg, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmDebug.DebugMock.defaultExpectation.expectationOrigins.originMsg, *mm_want_ptrs.msg, mm_got.msg, minimock.Diff(*mm_want_ptrs.msg, mm_got.msg))
+			}
+
+		} else if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmDebug.t.Errorf("OutputMock.Debug got unexpected parameters, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+				mmDebug.DebugMock.defaultExpectation.expectationOrigins.origin, *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		return
+
+	}
+	if mmDebug.funcDebug != nil {
+		mmDebug.funcDebug(msg)
+		return
+	}
+	mmDebug.t.Fatalf("Unexpected call to OutputMock.Debug. %v", msg)
+
+}
+
+// DebugAfterCounter returns a count of finished OutputMock.Debug invocations
+func (mmDebug *OutputMock) DebugAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmDebug.afterDebugCounter)
+}
+
+// DebugBeforeCounter returns a count of OutputMock.Debug invocations
+func (mmDebug *OutputMock) DebugBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmDebug.beforeDebugCounter)
+}
+
+// Calls returns a list of arguments used in each call to OutputMock.Debug.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmDebug *mOutputMockDebug) Calls() []*OutputMockDebugParams {
+	mmDebug.mutex.RLock()
+
+	argCopy := make([]*OutputMockDebugParams, len(mmDebug.callArgs))
+	copy(argCopy, mmDebug.callArgs)
+
+	mmDebug.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockDebugDone returns true if the count of the Debug invocations corresponds
+// the number of defined expectations
+func (m *OutputMock) MinimockDebugDone() bool {
+	if m.DebugMock.optional {
+		// Optional methods provide '0 or more' call count restriction.
+		return true
+	}
+
+	for _, e := range m.DebugMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	return m.DebugMock.invocationsDone()
+}
+
+// MinimockDebugInspect logs each unmet expectation
+func (m *OutputMock) MinimockDebugInspect() {
+	for _, e := range m.DebugMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to OutputMock.Debug at\n%s with params: %#v", e.expectationOrigins.origin, *e.params)
+		}
+	}
+
+	afterDebugCounter := mm_atomic.LoadUint64(&m.afterDebugCounter)
+	// if default expectation was set then invocations count should be greater than zero
+	if m.DebugMock.defaultExpectation != nil && afterDebugCounter < 1 {
+		if m.DebugMock.defaultExpectation.params == nil {
+			m.t.Errorf("Expected call to OutputMock.Debug at\n%s", m.DebugMock.defaultExpectation.returnOrigin)
+		} else {
+			m.t.Errorf("Expected call to OutputMock.Debug at\n%s with params: %#v", m.DebugMock.defaultExpectation.expectationOrigins.origin, *m.DebugMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcDebug != nil && afterDebugCounter < 1 {
+		m.t.Errorf("Expected call to OutputMock.Debug at\n%s", m.funcDebugOrigin)
+	}
+
+	if !m.DebugMock.invocationsDone() && afterDebugCounter > 0 {
+		m.t.Errorf("Expected %d calls to OutputMock.Debug at\n%s but found %d calls",
+			mm_atomic.LoadUint64(&m.DebugMock.expectedInvocations), m.DebugMock.expectedInvocationsOrigin, afterDebugCounter)
+	}
+}
+
+type mOutputMockDebugf struct {
+	optional           bool
+	mock               *OutputMock
+	defaultExpectation *OutputMockDebugfExpectation
+	expectations       []*OutputMockDebugfExpectation
+
+	callArgs []*OutputMockDebugfParams
+	mutex    sync.RWMutex
+
+	expectedInvocations       uint64
+	expectedInvocationsOrigin string
+}
+
+// OutputMockDebugfExpectation specifies expectation struct of the Output.Debugf
+type OutputMockDebugfExpectation struct {
+	mock               *OutputMock
+	params             *OutputMockDebugfParams
+	paramPtrs          *OutputMockDebugfParamPtrs
+	expectationOrigins OutputMockDebugfExpectationOrigins
+
+	returnOrigin string
+	Counter      uint64
+}
+
+// OutputMockDebugfParams contains parameters of the Output.Debugf
+type OutputMockDebugfParams struct {
+	msg  string
+	args []any
+}
+
+// OutputMockDebugfParamPtrs contains pointers to parameters of the Output.Debugf
+type OutputMockDebugfParamPtrs struct {
+	msg  *string
+	args *[]any
+}
+
+// OutputMockDebugfOrigins contains origins of expectations of the Output.Debugf
+type OutputMockDebugfExpectationOrigins struct {
+	origin     string
+	originMsg  string
+	originArgs string
+}
+
+// Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
+// the test will fail minimock's automatic final call check if the mocked method was not called at least once.
+// Optional() makes method check to work in '0 or more' mode.
+// It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
+// catch the problems when the expected method call is totally skipped during test run.
+func (mmDebugf *mOutputMockDebugf) Optional() *mOutputMockDebugf {
+	mmDebugf.optional = true
+	return mmDebugf
+}
+
+// Expect sets up expected params for Output.Debugf
+func (mmDebugf *mOutputMockDebugf) Expect(msg string, args ...any) *mOutputMockDebugf {
+	if mmDebugf.mock.funcDebugf != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Set")
+	}
+
+	if mmDebugf.defaultExpectation == nil {
+		mmDebugf.defaultExpectation = &OutputMockDebugfExpectation{}
+	}
+
+	if mmDebugf.defaultExpectation.paramPtrs != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by ExpectParams functions")
+	}
+
+	mmDebugf.defaultExpectation.params = &OutputMockDebugfParams{msg, args}
+	mmDebugf.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmDebugf.expectations {
+		if minimock.Equal(e.params, mmDebugf.defaultExpectation.params) {
+			mmDebugf.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmDebugf.defaultExpectation.params)
+		}
+	}
+
+	return mmDebugf
+}
+
+// ExpectMsgParam1 sets up expected param msg for Output.Debugf
+func (mmDebugf *mOutputMockDebugf) ExpectMsgParam1(msg string) *mOutputMockDebugf {
+	if mmDebugf.mock.funcDebugf != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Set")
+	}
+
+	if mmDebugf.defaultExpectation == nil {
+		mmDebugf.defaultExpectation = &OutputMockDebugfExpectation{}
+	}
+
+	if mmDebugf.defaultExpectation.params != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Expect")
+	}
+
+	if mmDebugf.defaultExpectation.paramPtrs == nil {
+		mmDebugf.defaultExpectation.paramPtrs = &OutputMockDebugfParamPtrs{}
+	}
+	mmDebugf.defaultExpectation.paramPtrs.msg = &msg
+	mmDebugf.defaultExpectation.expectationOrigins.originMsg = minimock.CallerInfo(1)
+
+	return mmDebugf
+}
+
+// ExpectArgsParam2 sets up expected param args for Output.Debugf
+func (mmDebugf *mOutputMockDebugf) ExpectArgsParam2(args ...any) *mOutputMockDebugf {
+	if mmDebugf.mock.funcDebugf != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Set")
+	}
+
+	if mmDebugf.defaultExpectation == nil {
+		mmDebugf.defaultExpectation = &OutputMockDebugfExpectation{}
+	}
+
+	if mmDebugf.defaultExpectation.params != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Expect")
+	}
+
+	if mmDebugf.defaultExpectation.paramPtrs == nil {
+		mmDebugf.defaultExpectation.paramPtrs = &OutputMockDebugfParamPtrs{}
+	}
+	mmDebugf.defaultExpectation.paramPtrs.args = &args
+	mmDebugf.defaultExpectation.expectationOrigins.originArgs = minimock.CallerInfo(1)
+
+	return mmDebugf
+}
+
+// Inspect accepts an inspector function that has same arguments as the Output.Debugf
+func (mmDebugf *mOutputMockDebugf) Inspect(f func(msg string, args ...any)) *mOutputMockDebugf {
+	if mmDebugf.mock.inspectFuncDebugf != nil {
+		mmDebugf.mock.t.Fatalf("Inspect function is already set for OutputMock.Debugf")
+	}
+
+	mmDebugf.mock.inspectFuncDebugf = f
+
+	return mmDebugf
+}
+
+// Return sets up results that will be returned by Output.Debugf
+func (mmDebugf *mOutputMockDebugf) Return() *OutputMock {
+	if mmDebugf.mock.funcDebugf != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Set")
+	}
+
+	if mmDebugf.defaultExpectation == nil {
+		mmDebugf.defaultExpectation = &OutputMockDebugfExpectation{mock: mmDebugf.mock}
+	}
+
+	mmDebugf.defaultExpectation.returnOrigin = minimock.CallerInfo(1)
+	return mmDebugf.mock
+}
+
+// Set uses given function f to mock the Output.Debugf method
+func (mmDebugf *mOutputMockDebugf) Set(f func(msg string, args ...any)) *OutputMock {
+	if mmDebugf.defaultExpectation != nil {
+		mmDebugf.mock.t.Fatalf("Default expectation is already set for the Output.Debugf method")
+	}
+
+	if len(mmDebugf.expectations) > 0 {
+		mmDebugf.mock.t.Fatalf("Some expectations are already set for the Output.Debugf method")
+	}
+
+	mmDebugf.mock.funcDebugf = f
+	mmDebugf.mock.funcDebugfOrigin = minimock.CallerInfo(1)
+	return mmDebugf.mock
+}
+
+// When sets expectation for the Output.Debugf which will trigger the result defined by the following
+// Then helper
+func (mmDebugf *mOutputMockDebugf) When(msg string, args ...any) *OutputMockDebugfExpectation {
+	if mmDebugf.mock.funcDebugf != nil {
+		mmDebugf.mock.t.Fatalf("OutputMock.Debugf mock is already set by Set")
+	}
+
+	expectation := &OutputMockDebugfExpectation{
+		mock:               mmDebugf.mock,
+		params:             &OutputMockDebugfParams{msg, args},
+		expectationOrigins: OutputMockDebugfExpectationOrigins{origin: minimock.CallerInfo(1)},
+	}
+	mmDebugf.expectations = append(mmDebugf.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Output.Debugf return parameters for the expectation previously defined by the When method
+
+func (e *OutputMockDebugfExpectation) Then() *OutputMock {
+	return e.mock
+}
+
+// Times sets number of times Output.Debugf should be invoked
+func (mmDebugf *mOutputMockDebugf) Times(n uint64) *mOutputMockDebugf {
+	if n == 0 {
+		mmDebugf.mock.t.Fatalf("Times of OutputMock.Debugf mock can not be zero")
+	}
+	mm_atomic.StoreUint64(&mmDebugf.expectedInvocations, n)
+	mmDebugf.expectedInvocationsOrigin = minimock.CallerInfo(1)
+	return mmDebugf
+}
+
+func (mmDebugf *mOutputMockDebugf) invocationsDone() bool {
+	if len(mmDebugf.expectations) == 0 && mmDebugf.defaultExpectation == nil && mmDebugf.mock.funcDebugf == nil {
+		return true
+	}
+
+	totalInvocations := mm_atomic.LoadUint64(&mmDebugf.mock.afterDebugfCounter)
+	expectedInvocations := mm_atomic.LoadUint64(&mmDebugf.expectedInvocations)
+
+	return totalInvocations > 0 && (expectedInvocations == 0 || expectedInvocations == totalInvocations)
+}
+
+// Debugf implements mm_contracts.Output
+func (mmDebugf *OutputMock) Debugf(msg string, args ...any) {
+	mm_atomic.AddUint64(&mmDebugf.beforeDebugfCounter, 1)
+	defer mm_atomic.AddUint64(&mmDebugf.afterDebugfCounter, 1)
+
+	mmDebugf.t.Helper()
+
+	if mmDebugf.inspectFuncDebugf != nil {
+		mmDebugf.inspectFuncDebugf(msg, args...)
+	}
+
+	mm_params := OutputMockDebugfParams{msg, args}
+
+	// Record call args
+	mmDebugf.DebugfMock.mutex.Lock()
+	mmDebugf.DebugfMock.callArgs = append(mmDebugf.DebugfMock.callArgs, &mm_params)
+	mmDebugf.DebugfMock.mutex.Unlock()
+
+	for _, e := range mmDebugf.DebugfMock.expectations {
+		if minimock.Equal(*e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return
+		}
+	}
+
+	if mmDebugf.DebugfMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmDebugf.DebugfMock.defaultExpectation.Counter, 1)
+		mm_want := mmDebugf.DebugfMock.defaultExpectation.params
+		mm_want_ptrs := mmDebugf.DebugfMock.defaultExpectation.paramPtrs
+
+		mm_got := OutputMockDebugfParams{msg, args}
+
+		if mm_want_ptrs != nil {
+
+			if mm_want_ptrs.msg != nil && !minimock.Equal(*mm_want_ptrs.msg, mm_got.msg) {
+				mmDebugf.t.Errorf("OutputMock.Debugf got unexpected parameter msg, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmDebugf.DebugfMock.defaultExpectation.expectationOrigins.originMsg, *mm_want_ptrs.msg, mm_got.msg, minimock.Diff(*mm_want_ptrs.msg, mm_got.msg))
+			}
+
+			if mm_want_ptrs.args != nil && !minimock.Equal(*mm_want_ptrs.args, mm_got.args) {
+				mmDebugf.t.Errorf("OutputMock.Debugf got unexpected parameter args, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmDebugf.DebugfMock.defaultExpectation.expectationOrigins.originArgs, *mm_want_ptrs.args, mm_got.args, minimock.Diff(*mm_want_ptrs.args, mm_got.args))
+			}
+
+		} else if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmDebugf.t.Errorf("OutputMock.Debugf got unexpected parameters, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+				mmDebugf.DebugfMock.defaultExpectation.expectationOrigins.origin, *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		return
+
+	}
+	if mmDebugf.funcDebugf != nil {
+		mmDebugf.funcDebugf(msg, args...)
+		return
+	}
+	mmDebugf.t.Fatalf("Unexpected call to OutputMock.Debugf. %v %v", msg, args)
+
+}
+
+// DebugfAfterCounter returns a count of finished OutputMock.Debugf invocations
+func (mmDebugf *OutputMock) DebugfAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmDebugf.afterDebugfCounter)
+}
+
+// DebugfBeforeCounter returns a count of OutputMock.Debugf invocations
+func (mmDebugf *OutputMock) DebugfBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmDebugf.beforeDebugfCounter)
+}
+
+// Calls returns a list of arguments used in each call to OutputMock.Debugf.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmDebugf *mOutputMockDebugf) Calls() []*OutputMockDebugfParams {
+	mmDebugf.mutex.RLock()
+
+	argCopy := make([]*OutputMockDebugfParams, len(mmDebugf.callArgs))
+	copy(argCopy, mmDebugf.callArgs)
+
+	mmDebugf.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockDebugfDone returns true if the count of the Debugf invocations corresponds
+// the number of defined expectations
+func (m *OutputMock) MinimockDebugfDone() bool {
+	if m.DebugfMock.optional {
+		// Optional methods provide '0 or more' call count restriction.
+		return true
+	}
+
+	for _, e := range m.DebugfMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	return m.DebugfMock.invocationsDone()
+}
+
+// MinimockDebugfInspect logs each unmet expectation
+func (m *OutputMock) MinimockDebugfInspect() {
+	for _, e := range m.DebugfMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to OutputMock.Debugf at\n%s with params: %#v", e.expectationOrigins.origin, *e.params)
+		}
+	}
+
+	afterDebugfCounter := mm_atomic.LoadUint64(&m.afterDebugfCounter)
+	// if default expectation was set then invocations count should be greater than zero
+	if m.DebugfMock.defaultExpectation != nil && afterDebugfCounter < 1 {
+		if m.DebugfMock.defaultExpectation.params == nil {
+			m.t.Errorf("Expected call to OutputMock.Debugf at\n%s", m.DebugfMock.defaultExpectation.returnOrigin)
+		} else {
+			m.t.Errorf("Expected call to OutputMock.Debugf at\n%s with params: %#v", m.DebugfMock.defaultExpectation.expectationOrigins.origin, *m.DebugfMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcDebugf != nil && afterDebugfCounter < 1 {
+		m.t.Errorf("Expected call to OutputMock.Debugf at\n%s", m.funcDebugfOrigin)
+	}
+
+	if !m.DebugfMock.invocationsDone() && afterDebugfCounter > 0 {
+		m.t.Errorf("Expected %d calls to OutputMock.Debugf at\n%s but found %d calls",
+			mm_atomic.LoadUint64(&m.DebugfMock.expectedInvocations), m.DebugfMock.expectedInvocationsOrigin, afterDebugfCounter)
+	}
+}
+
 type mOutputMockError struct {
 	optional           bool
 	mock               *OutputMock
@@ -4571,6 +5230,10 @@ func (m *OutputMock) MinimockWriteInspect() {
 func (m *OutputMock) MinimockFinish() {
 	m.finishOnce.Do(func() {
 		if !m.minimockDone() {
+			m.MinimockDebugInspect()
+
+			m.MinimockDebugfInspect()
+
 			m.MinimockErrorInspect()
 
 			m.MinimockErrorBoxInspect()
@@ -4621,6 +5284,8 @@ func (m *OutputMock) MinimockWait(timeout mm_time.Duration) {
 func (m *OutputMock) minimockDone() bool {
 	done := true
 	return done &&
+		m.MinimockDebugDone() &&
+		m.MinimockDebugfDone() &&
 		m.MinimockErrorDone() &&
 		m.MinimockErrorBoxDone() &&
 		m.MinimockErrorfDone() &&
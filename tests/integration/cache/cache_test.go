@@ -26,7 +26,7 @@ func TestCacheMiddleware(t *testing.T) {
 		Mappings: config.Mappings{{
 			From:  hosts.Parse("https://cache.local"),
 			To:    backend.AsHost(),
-			Cache: config.CacheGlobs{"/cached/**"},
+			Cache: config.CacheRules{{Path: "/cached/**"}},
 		}},
 	})
 
@@ -28,6 +28,16 @@ func TestScriptHandler(t *testing.T) {
 					Script: `
 response:WriteHeader(202)
 response:WriteString("scripted")
+`,
+				},
+				{
+					Matcher: config.RequestMatcher{Path: "/aggregate"},
+					Script: `
+local http = require("http")
+local first = http.get("` + backend.URL() + `/first")
+local second = http.get("` + backend.URL() + `/second")
+response:WriteHeader(200)
+response:WriteString(first.body .. "+" .. second.body)
 `,
 				},
 				{
@@ -67,4 +77,18 @@ response:WriteString(request.path_params["id"])
 
 		snaps.MatchSnapshot(t, result.ResponseDump(t))
 	})
+
+	t.Run("script calls the backend through the http module", func(t *testing.T) {
+		backend.Reset()
+
+		result := env.Do(t, integration.NewRequest(t, http.MethodGet, env.URL("script.local", "/aggregate")))
+		defer testutils.Close(t, result.Response.Body)
+
+		body, err := io.ReadAll(result.Response.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+		assert.Equal(t, "ok+ok", string(body))
+		assert.Equal(t, 2, backend.Count())
+	})
 }